@@ -29,6 +29,8 @@ type PushMsgReq struct {
 	Keys                 []string        `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
 	ProtoOp              int32           `protobuf:"varint,3,opt,name=protoOp,proto3" json:"protoOp,omitempty"`
 	Proto                *protocol.Proto `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`
+	Priority             int32           `protobuf:"varint,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	MsgID                string          `protobuf:"bytes,5,opt,name=msgID,proto3" json:"msgID,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -80,6 +82,20 @@ func (m *PushMsgReq) GetProto() *protocol.Proto {
 	return nil
 }
 
+func (m *PushMsgReq) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *PushMsgReq) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
 type PushMsgReply struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -115,6 +131,8 @@ type BroadcastReq struct {
 	ProtoOp              int32           `protobuf:"varint,1,opt,name=protoOp,proto3" json:"protoOp,omitempty"`
 	Proto                *protocol.Proto `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`
 	Speed                int32           `protobuf:"varint,3,opt,name=speed,proto3" json:"speed,omitempty"`
+	Priority             int32           `protobuf:"varint,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	MsgID                string          `protobuf:"bytes,5,opt,name=msgID,proto3" json:"msgID,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -166,6 +184,20 @@ func (m *BroadcastReq) GetSpeed() int32 {
 	return 0
 }
 
+func (m *BroadcastReq) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *BroadcastReq) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
 type BroadcastReply struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -200,6 +232,8 @@ var xxx_messageInfo_BroadcastReply proto.InternalMessageInfo
 type BroadcastRoomReq struct {
 	RoomID               string          `protobuf:"bytes,1,opt,name=roomID,proto3" json:"roomID,omitempty"`
 	Proto                *protocol.Proto `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`
+	Priority             int32           `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	MsgID                string          `protobuf:"bytes,4,opt,name=msgID,proto3" json:"msgID,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -244,6 +278,20 @@ func (m *BroadcastRoomReq) GetProto() *protocol.Proto {
 	return nil
 }
 
+func (m *BroadcastRoomReq) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *BroadcastRoomReq) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
 type BroadcastRoomReply struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -345,6 +393,543 @@ func (m *RoomsReply) GetRooms() map[string]bool {
 	return nil
 }
 
+type RoomMember struct {
+	Mid                  int64    `protobuf:"varint,1,opt,name=mid,proto3" json:"mid,omitempty"`
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoomMember) Reset()         { *m = RoomMember{} }
+func (m *RoomMember) String() string { return proto.CompactTextString(m) }
+func (*RoomMember) ProtoMessage()    {}
+func (*RoomMember) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{8}
+}
+
+func (m *RoomMember) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoomMember.Unmarshal(m, b)
+}
+func (m *RoomMember) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoomMember.Marshal(b, m, deterministic)
+}
+func (m *RoomMember) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoomMember.Merge(m, src)
+}
+func (m *RoomMember) XXX_Size() int {
+	return xxx_messageInfo_RoomMember.Size(m)
+}
+func (m *RoomMember) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoomMember.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoomMember proto.InternalMessageInfo
+
+func (m *RoomMember) GetMid() int64 {
+	if m != nil {
+		return m.Mid
+	}
+	return 0
+}
+
+func (m *RoomMember) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+// RoomMembersReq pages the members of a room, cursor is the key of the last member paged, empty at first.
+type RoomMembersReq struct {
+	RoomID               string   `protobuf:"bytes,1,opt,name=roomID,proto3" json:"roomID,omitempty"`
+	Cursor               string   `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoomMembersReq) Reset()         { *m = RoomMembersReq{} }
+func (m *RoomMembersReq) String() string { return proto.CompactTextString(m) }
+func (*RoomMembersReq) ProtoMessage()    {}
+func (*RoomMembersReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{9}
+}
+
+func (m *RoomMembersReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoomMembersReq.Unmarshal(m, b)
+}
+func (m *RoomMembersReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoomMembersReq.Marshal(b, m, deterministic)
+}
+func (m *RoomMembersReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoomMembersReq.Merge(m, src)
+}
+func (m *RoomMembersReq) XXX_Size() int {
+	return xxx_messageInfo_RoomMembersReq.Size(m)
+}
+func (m *RoomMembersReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoomMembersReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoomMembersReq proto.InternalMessageInfo
+
+func (m *RoomMembersReq) GetRoomID() string {
+	if m != nil {
+		return m.RoomID
+	}
+	return ""
+}
+
+func (m *RoomMembersReq) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *RoomMembersReq) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// RoomMembersReply next is the cursor of the next page, empty if there is no more.
+type RoomMembersReply struct {
+	Members              []*RoomMember `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	Next                 string        `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *RoomMembersReply) Reset()         { *m = RoomMembersReply{} }
+func (m *RoomMembersReply) String() string { return proto.CompactTextString(m) }
+func (*RoomMembersReply) ProtoMessage()    {}
+func (*RoomMembersReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{10}
+}
+
+func (m *RoomMembersReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoomMembersReply.Unmarshal(m, b)
+}
+func (m *RoomMembersReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoomMembersReply.Marshal(b, m, deterministic)
+}
+func (m *RoomMembersReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoomMembersReply.Merge(m, src)
+}
+func (m *RoomMembersReply) XXX_Size() int {
+	return xxx_messageInfo_RoomMembersReply.Size(m)
+}
+func (m *RoomMembersReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoomMembersReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoomMembersReply proto.InternalMessageInfo
+
+func (m *RoomMembersReply) GetMembers() []*RoomMember {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+func (m *RoomMembersReply) GetNext() string {
+	if m != nil {
+		return m.Next
+	}
+	return ""
+}
+
+// PushStreamReq is a push on the stream, one of pushMsg, broadcast and broadcastRoom is set.
+type PushStreamReq struct {
+	Seq                  int64             `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	PushMsg              *PushMsgReq       `protobuf:"bytes,2,opt,name=pushMsg,proto3" json:"pushMsg,omitempty"`
+	Broadcast            *BroadcastReq     `protobuf:"bytes,3,opt,name=broadcast,proto3" json:"broadcast,omitempty"`
+	BroadcastRoom        *BroadcastRoomReq `protobuf:"bytes,4,opt,name=broadcastRoom,proto3" json:"broadcastRoom,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PushStreamReq) Reset()         { *m = PushStreamReq{} }
+func (m *PushStreamReq) String() string { return proto.CompactTextString(m) }
+func (*PushStreamReq) ProtoMessage()    {}
+func (*PushStreamReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{11}
+}
+
+func (m *PushStreamReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushStreamReq.Unmarshal(m, b)
+}
+func (m *PushStreamReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushStreamReq.Marshal(b, m, deterministic)
+}
+func (m *PushStreamReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushStreamReq.Merge(m, src)
+}
+func (m *PushStreamReq) XXX_Size() int {
+	return xxx_messageInfo_PushStreamReq.Size(m)
+}
+func (m *PushStreamReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushStreamReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushStreamReq proto.InternalMessageInfo
+
+func (m *PushStreamReq) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *PushStreamReq) GetPushMsg() *PushMsgReq {
+	if m != nil {
+		return m.PushMsg
+	}
+	return nil
+}
+
+func (m *PushStreamReq) GetBroadcast() *BroadcastReq {
+	if m != nil {
+		return m.Broadcast
+	}
+	return nil
+}
+
+func (m *PushStreamReq) GetBroadcastRoom() *BroadcastRoomReq {
+	if m != nil {
+		return m.BroadcastRoom
+	}
+	return nil
+}
+
+// PushStreamAck acks the push of the same seq, error is empty if it's ok.
+type PushStreamAck struct {
+	Seq                  int64    `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PushStreamAck) Reset()         { *m = PushStreamAck{} }
+func (m *PushStreamAck) String() string { return proto.CompactTextString(m) }
+func (*PushStreamAck) ProtoMessage()    {}
+func (*PushStreamAck) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{12}
+}
+
+func (m *PushStreamAck) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushStreamAck.Unmarshal(m, b)
+}
+func (m *PushStreamAck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushStreamAck.Marshal(b, m, deterministic)
+}
+func (m *PushStreamAck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushStreamAck.Merge(m, src)
+}
+func (m *PushStreamAck) XXX_Size() int {
+	return xxx_messageInfo_PushStreamAck.Size(m)
+}
+func (m *PushStreamAck) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushStreamAck.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushStreamAck proto.InternalMessageInfo
+
+func (m *PushStreamAck) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *PushStreamAck) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type Ban struct {
+	Ip  string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Mid int64  `protobuf:"varint,2,opt,name=mid,proto3" json:"mid,omitempty"`
+	// expire is the unix seconds when the ban ends, 0 is forever
+	Expire               int64    `protobuf:"varint,3,opt,name=expire,proto3" json:"expire,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Ban) Reset()         { *m = Ban{} }
+func (m *Ban) String() string { return proto.CompactTextString(m) }
+func (*Ban) ProtoMessage()    {}
+func (*Ban) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{13}
+}
+
+func (m *Ban) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Ban.Unmarshal(m, b)
+}
+func (m *Ban) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Ban.Marshal(b, m, deterministic)
+}
+func (m *Ban) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Ban.Merge(m, src)
+}
+func (m *Ban) XXX_Size() int {
+	return xxx_messageInfo_Ban.Size(m)
+}
+func (m *Ban) XXX_DiscardUnknown() {
+	xxx_messageInfo_Ban.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Ban proto.InternalMessageInfo
+
+func (m *Ban) GetIp() string {
+	if m != nil {
+		return m.Ip
+	}
+	return ""
+}
+
+func (m *Ban) GetMid() int64 {
+	if m != nil {
+		return m.Mid
+	}
+	return 0
+}
+
+func (m *Ban) GetExpire() int64 {
+	if m != nil {
+		return m.Expire
+	}
+	return 0
+}
+
+type BanReq struct {
+	Bans                 []*Ban   `protobuf:"bytes,1,rep,name=bans,proto3" json:"bans,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BanReq) Reset()         { *m = BanReq{} }
+func (m *BanReq) String() string { return proto.CompactTextString(m) }
+func (*BanReq) ProtoMessage()    {}
+func (*BanReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{14}
+}
+
+func (m *BanReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BanReq.Unmarshal(m, b)
+}
+func (m *BanReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BanReq.Marshal(b, m, deterministic)
+}
+func (m *BanReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BanReq.Merge(m, src)
+}
+func (m *BanReq) XXX_Size() int {
+	return xxx_messageInfo_BanReq.Size(m)
+}
+func (m *BanReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_BanReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BanReq proto.InternalMessageInfo
+
+func (m *BanReq) GetBans() []*Ban {
+	if m != nil {
+		return m.Bans
+	}
+	return nil
+}
+
+type BanReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BanReply) Reset()         { *m = BanReply{} }
+func (m *BanReply) String() string { return proto.CompactTextString(m) }
+func (*BanReply) ProtoMessage()    {}
+func (*BanReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{15}
+}
+
+func (m *BanReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BanReply.Unmarshal(m, b)
+}
+func (m *BanReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BanReply.Marshal(b, m, deterministic)
+}
+func (m *BanReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BanReply.Merge(m, src)
+}
+func (m *BanReply) XXX_Size() int {
+	return xxx_messageInfo_BanReply.Size(m)
+}
+func (m *BanReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_BanReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BanReply proto.InternalMessageInfo
+
+type UnbanReq struct {
+	Ips                  []string `protobuf:"bytes,1,rep,name=ips,proto3" json:"ips,omitempty"`
+	Mids                 []int64  `protobuf:"varint,2,rep,packed,name=mids,proto3" json:"mids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnbanReq) Reset()         { *m = UnbanReq{} }
+func (m *UnbanReq) String() string { return proto.CompactTextString(m) }
+func (*UnbanReq) ProtoMessage()    {}
+func (*UnbanReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{16}
+}
+
+func (m *UnbanReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnbanReq.Unmarshal(m, b)
+}
+func (m *UnbanReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnbanReq.Marshal(b, m, deterministic)
+}
+func (m *UnbanReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnbanReq.Merge(m, src)
+}
+func (m *UnbanReq) XXX_Size() int {
+	return xxx_messageInfo_UnbanReq.Size(m)
+}
+func (m *UnbanReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnbanReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnbanReq proto.InternalMessageInfo
+
+func (m *UnbanReq) GetIps() []string {
+	if m != nil {
+		return m.Ips
+	}
+	return nil
+}
+
+func (m *UnbanReq) GetMids() []int64 {
+	if m != nil {
+		return m.Mids
+	}
+	return nil
+}
+
+type UnbanReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnbanReply) Reset()         { *m = UnbanReply{} }
+func (m *UnbanReply) String() string { return proto.CompactTextString(m) }
+func (*UnbanReply) ProtoMessage()    {}
+func (*UnbanReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{17}
+}
+
+func (m *UnbanReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnbanReply.Unmarshal(m, b)
+}
+func (m *UnbanReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnbanReply.Marshal(b, m, deterministic)
+}
+func (m *UnbanReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnbanReply.Merge(m, src)
+}
+func (m *UnbanReply) XXX_Size() int {
+	return xxx_messageInfo_UnbanReply.Size(m)
+}
+func (m *UnbanReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnbanReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnbanReply proto.InternalMessageInfo
+
+type BansReq struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BansReq) Reset()         { *m = BansReq{} }
+func (m *BansReq) String() string { return proto.CompactTextString(m) }
+func (*BansReq) ProtoMessage()    {}
+func (*BansReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{18}
+}
+
+func (m *BansReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BansReq.Unmarshal(m, b)
+}
+func (m *BansReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BansReq.Marshal(b, m, deterministic)
+}
+func (m *BansReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BansReq.Merge(m, src)
+}
+func (m *BansReq) XXX_Size() int {
+	return xxx_messageInfo_BansReq.Size(m)
+}
+func (m *BansReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_BansReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BansReq proto.InternalMessageInfo
+
+type BansReply struct {
+	Bans                 []*Ban   `protobuf:"bytes,1,rep,name=bans,proto3" json:"bans,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BansReply) Reset()         { *m = BansReply{} }
+func (m *BansReply) String() string { return proto.CompactTextString(m) }
+func (*BansReply) ProtoMessage()    {}
+func (*BansReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_327b4a7d084564be, []int{19}
+}
+
+func (m *BansReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BansReply.Unmarshal(m, b)
+}
+func (m *BansReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BansReply.Marshal(b, m, deterministic)
+}
+func (m *BansReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BansReply.Merge(m, src)
+}
+func (m *BansReply) XXX_Size() int {
+	return xxx_messageInfo_BansReply.Size(m)
+}
+func (m *BansReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_BansReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BansReply proto.InternalMessageInfo
+
+func (m *BansReply) GetBans() []*Ban {
+	if m != nil {
+		return m.Bans
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*PushMsgReq)(nil), "goim.comet.PushMsgReq")
 	proto.RegisterType((*PushMsgReply)(nil), "goim.comet.PushMsgReply")
@@ -355,38 +940,75 @@ func init() {
 	proto.RegisterType((*RoomsReq)(nil), "goim.comet.RoomsReq")
 	proto.RegisterType((*RoomsReply)(nil), "goim.comet.RoomsReply")
 	proto.RegisterMapType((map[string]bool)(nil), "goim.comet.RoomsReply.RoomsEntry")
+	proto.RegisterType((*RoomMember)(nil), "goim.comet.RoomMember")
+	proto.RegisterType((*RoomMembersReq)(nil), "goim.comet.RoomMembersReq")
+	proto.RegisterType((*RoomMembersReply)(nil), "goim.comet.RoomMembersReply")
+	proto.RegisterType((*PushStreamReq)(nil), "goim.comet.PushStreamReq")
+	proto.RegisterType((*PushStreamAck)(nil), "goim.comet.PushStreamAck")
+	proto.RegisterType((*Ban)(nil), "goim.comet.Ban")
+	proto.RegisterType((*BanReq)(nil), "goim.comet.BanReq")
+	proto.RegisterType((*BanReply)(nil), "goim.comet.BanReply")
+	proto.RegisterType((*UnbanReq)(nil), "goim.comet.UnbanReq")
+	proto.RegisterType((*UnbanReply)(nil), "goim.comet.UnbanReply")
+	proto.RegisterType((*BansReq)(nil), "goim.comet.BansReq")
+	proto.RegisterType((*BansReply)(nil), "goim.comet.BansReply")
 }
 
 func init() { proto.RegisterFile("comet/comet.proto", fileDescriptor_327b4a7d084564be) }
 
 var fileDescriptor_327b4a7d084564be = []byte{
-	// 404 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x53, 0xcf, 0x4f, 0xe2, 0x40,
-	0x14, 0x4e, 0x61, 0xcb, 0x8f, 0x07, 0x4b, 0xd8, 0x49, 0x43, 0x9a, 0x66, 0xb3, 0x61, 0x7b, 0x62,
-	0xd7, 0xd8, 0x26, 0x18, 0x22, 0x91, 0x93, 0xa8, 0x07, 0x0f, 0x44, 0x32, 0x31, 0x1e, 0xbc, 0x15,
-	0x18, 0x01, 0x69, 0x9d, 0xd2, 0x16, 0x93, 0x9e, 0xfc, 0x0b, 0xfc, 0x9f, 0xcd, 0x9b, 0xa9, 0x6d,
-	0x31, 0x95, 0x44, 0x2f, 0xcd, 0xf7, 0xde, 0x7c, 0xf3, 0xbe, 0x6f, 0xbe, 0x07, 0xf0, 0x6b, 0xce,
-	0x3d, 0x16, 0xd9, 0xe2, 0x6b, 0xf9, 0x01, 0x8f, 0x38, 0x81, 0x25, 0x5f, 0x7b, 0x96, 0xe8, 0x18,
-	0x83, 0xe5, 0x3a, 0x5a, 0xed, 0x66, 0x58, 0xd9, 0xb7, 0x2c, 0x08, 0xe2, 0xe3, 0x89, 0xc3, 0x6d,
-	0x24, 0xd8, 0x8e, 0xbf, 0xb6, 0xc5, 0x85, 0x39, 0x77, 0x53, 0x20, 0x47, 0x98, 0x0f, 0x00, 0xd3,
-	0x5d, 0xb8, 0x9a, 0x84, 0x4b, 0xca, 0xb6, 0x84, 0xc0, 0x8f, 0x0d, 0x8b, 0x43, 0x5d, 0xe9, 0x96,
-	0x7b, 0x75, 0x2a, 0x30, 0xd1, 0xa1, 0x2a, 0xa8, 0x37, 0xbe, 0x5e, 0xee, 0x2a, 0x3d, 0x95, 0xbe,
-	0x97, 0xe4, 0x3f, 0xa8, 0x02, 0xea, 0xa5, 0xae, 0xd2, 0x6b, 0xf4, 0x35, 0x4b, 0xd8, 0x49, 0x05,
-	0xa6, 0x08, 0xa8, 0xa4, 0x98, 0x2d, 0x68, 0xa6, 0x3a, 0xbe, 0x1b, 0x9b, 0x8f, 0xd0, 0x1c, 0x07,
-	0xdc, 0x59, 0xcc, 0x9d, 0x30, 0x42, 0xe5, 0x9c, 0x8a, 0xf2, 0x6d, 0x15, 0xa2, 0x81, 0x1a, 0xfa,
-	0x8c, 0x2d, 0x12, 0xa7, 0xb2, 0x30, 0xdb, 0xd0, 0xca, 0x69, 0xa1, 0xfa, 0x1d, 0xb4, 0xb3, 0x0e,
-	0xe7, 0x1e, 0x3a, 0xe8, 0x40, 0x25, 0xe0, 0xdc, 0xbb, 0xbe, 0x14, 0x06, 0xea, 0x34, 0xa9, 0xbe,
-	0xf4, 0x4a, 0x0d, 0xc8, 0x87, 0xb9, 0xa8, 0x06, 0x50, 0xc3, 0x22, 0xa4, 0x6c, 0x6b, 0xbe, 0x00,
-	0x24, 0xd8, 0x77, 0x63, 0x72, 0x0a, 0x2a, 0xaa, 0xc8, 0xc0, 0x1b, 0xfd, 0xbf, 0x56, 0xb6, 0x50,
-	0x2b, 0xa3, 0x49, 0x78, 0xf5, 0x14, 0x05, 0x31, 0x95, 0x7c, 0x63, 0x98, 0x8c, 0x11, 0x4d, 0xd2,
-	0x86, 0xf2, 0x86, 0xc5, 0x89, 0x6f, 0x84, 0x18, 0xc4, 0xb3, 0xe3, 0xee, 0x98, 0x30, 0x5d, 0xa3,
-	0xb2, 0x38, 0x2b, 0x0d, 0x95, 0xfe, 0x6b, 0x09, 0xd4, 0x0b, 0x14, 0x20, 0x23, 0xa8, 0x26, 0x2b,
-	0x21, 0x9d, 0xbc, 0x70, 0xf6, 0x7b, 0x30, 0xf4, 0xc2, 0x3e, 0x3a, 0x3f, 0x87, 0x7a, 0xfa, 0x52,
-	0xb2, 0x47, 0xcb, 0xaf, 0xd5, 0x30, 0x3e, 0x39, 0xc1, 0x11, 0x13, 0xf8, 0xb9, 0x17, 0x16, 0xf9,
-	0x5d, 0x4c, 0x96, 0xfb, 0x31, 0xfe, 0x1c, 0x38, 0xc5, 0x71, 0x03, 0x50, 0x45, 0x24, 0x44, 0x2b,
-	0x48, 0x71, 0x6b, 0x74, 0x8a, 0xb3, 0x1d, 0x1f, 0xdd, 0xff, 0x3b, 0xfc, 0xcf, 0x11, 0x37, 0x46,
-	0xe2, 0x3b, 0xab, 0x88, 0x35, 0x9f, 0xbc, 0x05, 0x00, 0x00, 0xff, 0xff, 0xb3, 0xcb, 0xf3, 0x2a,
-	0x8c, 0x03, 0x00, 0x00,
+	// 805 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0x4d, 0x8f, 0x12, 0x4d,
+	0x10, 0xce, 0x30, 0x0c, 0x1f, 0xc5, 0x2e, 0x2f, 0x6f, 0x8b, 0x64, 0x9c, 0x6c, 0x0c, 0x8e, 0x17,
+	0x62, 0xb2, 0x40, 0x30, 0xba, 0xab, 0x7b, 0x30, 0xe0, 0xaa, 0xd9, 0xc3, 0xc6, 0x4d, 0x1b, 0x8d,
+	0xf1, 0x36, 0x40, 0x87, 0xed, 0xc0, 0x7c, 0x30, 0x33, 0x98, 0xc5, 0x8b, 0x57, 0x7f, 0x82, 0x07,
+	0xe3, 0xd9, 0x7f, 0xe3, 0x5f, 0x32, 0xd5, 0x3d, 0x5f, 0xb0, 0xc3, 0x6a, 0xf6, 0x42, 0xaa, 0xaa,
+	0xab, 0xaa, 0x9f, 0xaa, 0xe7, 0xe9, 0x01, 0xfe, 0x9f, 0xb8, 0x36, 0x0b, 0x7b, 0xe2, 0xb7, 0xeb,
+	0xf9, 0x6e, 0xe8, 0x12, 0x98, 0xb9, 0xdc, 0xee, 0x8a, 0x88, 0xf1, 0x6c, 0xc6, 0xc3, 0xcb, 0xd5,
+	0x18, 0xbd, 0x9e, 0xc3, 0x9d, 0xd9, 0xe4, 0x92, 0x39, 0xb3, 0x2f, 0xcc, 0x99, 0xf5, 0x30, 0xa9,
+	0x67, 0x79, 0xbc, 0x27, 0x8a, 0x26, 0xee, 0x22, 0x31, 0x64, 0x1b, 0xf3, 0xbb, 0x02, 0x70, 0xb1,
+	0x0a, 0x2e, 0xcf, 0x83, 0x19, 0x65, 0x4b, 0x42, 0xa0, 0x38, 0x67, 0xeb, 0x40, 0x57, 0xda, 0x6a,
+	0xa7, 0x4a, 0x85, 0x4d, 0x74, 0x28, 0x8b, 0xdc, 0xb7, 0x9e, 0xae, 0xb6, 0x95, 0x8e, 0x46, 0x63,
+	0x97, 0x3c, 0x02, 0x4d, 0x98, 0x7a, 0xa1, 0xad, 0x74, 0x6a, 0x83, 0x66, 0x57, 0x60, 0x4a, 0x6e,
+	0xb8, 0x40, 0x83, 0xca, 0x14, 0x62, 0x40, 0xc5, 0xf3, 0xb9, 0xeb, 0xf3, 0x70, 0xad, 0x17, 0x45,
+	0x9b, 0xc4, 0x27, 0x4d, 0xd0, 0xec, 0x60, 0x76, 0x76, 0xaa, 0x6b, 0x6d, 0xa5, 0x53, 0xa5, 0xd2,
+	0x31, 0xeb, 0xb0, 0x97, 0x20, 0xf3, 0x16, 0x6b, 0xf3, 0x87, 0x02, 0x7b, 0x23, 0xdf, 0xb5, 0xa6,
+	0x13, 0x2b, 0x08, 0x11, 0x6c, 0x06, 0x98, 0x72, 0x7b, 0x60, 0x4d, 0xd0, 0x02, 0x8f, 0xb1, 0x69,
+	0x34, 0x9c, 0x74, 0x6e, 0x01, 0xb7, 0x01, 0xf5, 0x0c, 0x3a, 0x04, 0xfc, 0x4d, 0x81, 0x46, 0x1a,
+	0x72, 0x5d, 0x1b, 0x41, 0xb7, 0xa0, 0xe4, 0xbb, 0xae, 0x7d, 0x76, 0x2a, 0x30, 0x57, 0x69, 0xe4,
+	0xdd, 0x7a, 0x97, 0xea, 0x2e, 0x70, 0xc5, 0x2c, 0xb8, 0x26, 0x90, 0x2d, 0x24, 0x08, 0x10, 0xa0,
+	0x82, 0x4e, 0x40, 0xd9, 0xd2, 0xfc, 0x0a, 0x10, 0xd9, 0xde, 0x62, 0x4d, 0x8e, 0x40, 0x43, 0x5c,
+	0x52, 0x08, 0xb5, 0xc1, 0x83, 0x6e, 0xaa, 0xb6, 0x6e, 0x9a, 0x26, 0xcd, 0x57, 0x4e, 0xe8, 0xaf,
+	0xa9, 0xcc, 0x37, 0x8e, 0x01, 0xd2, 0x20, 0x69, 0x80, 0x3a, 0x67, 0xeb, 0x68, 0x52, 0x34, 0x11,
+	0xde, 0x67, 0x6b, 0xb1, 0x62, 0x62, 0xcc, 0x0a, 0x95, 0xce, 0xf3, 0xc2, 0xb1, 0x62, 0xf6, 0x65,
+	0xe5, 0x39, 0xb3, 0xc7, 0xcc, 0xc7, 0x4a, 0x9b, 0x4f, 0x45, 0xa5, 0x4a, 0xd1, 0x8c, 0x7b, 0x15,
+	0x92, 0x5e, 0xe6, 0x07, 0xa8, 0xa7, 0x15, 0xc1, 0x4d, 0xcb, 0x6d, 0x41, 0x69, 0xb2, 0xf2, 0x03,
+	0xd7, 0x8f, 0xca, 0x23, 0x0f, 0xd1, 0x2c, 0xb8, 0xcd, 0xc3, 0x98, 0x7b, 0xe1, 0x98, 0x1f, 0xa1,
+	0xb1, 0xd1, 0x17, 0x17, 0xd2, 0x87, 0xb2, 0x2d, 0xfd, 0x68, 0x25, 0xad, 0xed, 0x95, 0xc8, 0x74,
+	0x1a, 0xa7, 0xe1, 0x53, 0x72, 0xd8, 0x55, 0x18, 0xdd, 0x28, 0x6c, 0xf3, 0xb7, 0x02, 0xfb, 0xa8,
+	0xe9, 0x77, 0xa1, 0xcf, 0x2c, 0x21, 0x87, 0x06, 0xa8, 0x01, 0x5b, 0xc6, 0x73, 0x06, 0x6c, 0x89,
+	0x37, 0x79, 0x52, 0xf6, 0x91, 0x14, 0x36, 0x6e, 0x4a, 0xdf, 0x2a, 0x8d, 0xd3, 0xc8, 0x53, 0xa8,
+	0x8e, 0x63, 0x72, 0xc5, 0x24, 0xb5, 0x81, 0x9e, 0xad, 0xc9, 0x3e, 0x1a, 0x9a, 0xa6, 0x92, 0x11,
+	0xec, 0x8f, 0xb3, 0xa2, 0x10, 0x92, 0xa9, 0x0d, 0x0e, 0xf2, 0x6b, 0xa5, 0x7e, 0xe9, 0x66, 0x89,
+	0x79, 0x94, 0x1d, 0x68, 0x38, 0x99, 0xe7, 0x0c, 0xd4, 0x04, 0x8d, 0xf9, 0x7e, 0xb2, 0x7b, 0xe9,
+	0x98, 0x2f, 0x40, 0x1d, 0x59, 0x0e, 0xa9, 0x43, 0x81, 0x7b, 0x11, 0x5b, 0x05, 0xee, 0xc5, 0xbc,
+	0x17, 0x52, 0xde, 0x5b, 0x50, 0x62, 0x57, 0x1e, 0xf7, 0x99, 0x18, 0x4d, 0xa5, 0x91, 0x67, 0x1e,
+	0x42, 0x69, 0x64, 0x39, 0xb8, 0xc3, 0x87, 0x50, 0x1c, 0x5b, 0x4e, 0x4c, 0xcc, 0x7f, 0x1b, 0xf0,
+	0x2d, 0x87, 0x8a, 0x43, 0xd4, 0xba, 0x48, 0x47, 0xdd, 0xf7, 0xa1, 0xf2, 0xde, 0x19, 0xcb, 0xe2,
+	0x06, 0xa8, 0xdc, 0x8b, 0x3f, 0x78, 0x68, 0x22, 0x71, 0x36, 0x9f, 0x06, 0x7a, 0xa1, 0xad, 0x76,
+	0x54, 0x2a, 0x6c, 0x73, 0x0f, 0x20, 0xaa, 0xc0, 0xfa, 0x2a, 0x94, 0x47, 0x96, 0x23, 0x9e, 0x4d,
+	0x1f, 0xaa, 0xd2, 0x44, 0x91, 0xfc, 0x0b, 0x90, 0xc1, 0x4f, 0x15, 0xb4, 0x97, 0x18, 0x23, 0x27,
+	0x50, 0x8e, 0xe8, 0x24, 0x3b, 0x38, 0x36, 0xf4, 0xdc, 0x38, 0xde, 0x35, 0x84, 0x6a, 0xc2, 0x0d,
+	0xd9, 0x49, 0xb7, 0x61, 0xec, 0x38, 0xc1, 0x16, 0xe7, 0xb0, 0xbf, 0x41, 0x2f, 0xb9, 0x91, 0x79,
+	0xe3, 0xfe, 0x0d, 0xa7, 0xd8, 0xee, 0x09, 0x68, 0xe8, 0x04, 0xa4, 0x99, 0xf3, 0xb5, 0x58, 0x1a,
+	0xad, 0xfc, 0x6f, 0x08, 0x79, 0x03, 0xb5, 0xcc, 0x6b, 0x23, 0x46, 0xfe, 0xbb, 0x12, 0x2d, 0x0e,
+	0x76, 0x9e, 0x61, 0xa3, 0xd7, 0x00, 0xa9, 0x14, 0xc9, 0xbd, 0xed, 0xcd, 0x25, 0x6f, 0xce, 0xd8,
+	0x71, 0x34, 0x9c, 0xcc, 0x3b, 0x4a, 0x5f, 0x19, 0xfc, 0x52, 0x00, 0x04, 0x41, 0xc3, 0xa9, 0xcd,
+	0x1d, 0xd2, 0x93, 0x42, 0x25, 0xdb, 0x6c, 0xb2, 0xa5, 0xd1, 0xbc, 0x16, 0x8b, 0xf6, 0x20, 0xb4,
+	0xb2, 0xb9, 0x87, 0x58, 0x70, 0x46, 0x2b, 0x27, 0x8a, 0x65, 0x03, 0x28, 0xa2, 0x92, 0xc8, 0x9d,
+	0xad, 0xa6, 0x62, 0xf2, 0xbb, 0xd7, 0x83, 0xde, 0x62, 0x3d, 0xea, 0x7d, 0x3a, 0xfc, 0xfb, 0x5f,
+	0xbf, 0x28, 0x3a, 0x11, 0xbf, 0xe3, 0x92, 0xf8, 0x03, 0x79, 0xfc, 0x67, 0x00, 0xfa, 0x05, 0xb3,
+	0xb6, 0x51, 0x08, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -409,6 +1031,10 @@ type CometClient interface {
 	BroadcastRoom(ctx context.Context, in *BroadcastRoomReq, opts ...grpc.CallOption) (*BroadcastRoomReply, error)
 	// Rooms get all rooms
 	Rooms(ctx context.Context, in *RoomsReq, opts ...grpc.CallOption) (*RoomsReply, error)
+	// RoomMembers page the mids and keys in a room
+	RoomMembers(ctx context.Context, in *RoomMembersReq, opts ...grpc.CallOption) (*RoomMembersReply, error)
+	// PushStream pipeline the pushes, every push is acked by its seq
+	PushStream(ctx context.Context, opts ...grpc.CallOption) (Comet_PushStreamClient, error)
 }
 
 type cometClient struct {
@@ -455,6 +1081,46 @@ func (c *cometClient) Rooms(ctx context.Context, in *RoomsReq, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *cometClient) RoomMembers(ctx context.Context, in *RoomMembersReq, opts ...grpc.CallOption) (*RoomMembersReply, error) {
+	out := new(RoomMembersReply)
+	err := c.cc.Invoke(ctx, "/goim.comet.Comet/RoomMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cometClient) PushStream(ctx context.Context, opts ...grpc.CallOption) (Comet_PushStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Comet_serviceDesc.Streams[0], "/goim.comet.Comet/PushStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cometPushStreamClient{stream}
+	return x, nil
+}
+
+type Comet_PushStreamClient interface {
+	Send(*PushStreamReq) error
+	Recv() (*PushStreamAck, error)
+	grpc.ClientStream
+}
+
+type cometPushStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *cometPushStreamClient) Send(m *PushStreamReq) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *cometPushStreamClient) Recv() (*PushStreamAck, error) {
+	m := new(PushStreamAck)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // CometServer is the server API for Comet service.
 type CometServer interface {
 	// PushMsg push by key or mid
@@ -465,6 +1131,10 @@ type CometServer interface {
 	BroadcastRoom(context.Context, *BroadcastRoomReq) (*BroadcastRoomReply, error)
 	// Rooms get all rooms
 	Rooms(context.Context, *RoomsReq) (*RoomsReply, error)
+	// RoomMembers page the mids and keys in a room
+	RoomMembers(context.Context, *RoomMembersReq) (*RoomMembersReply, error)
+	// PushStream pipeline the pushes, every push is acked by its seq
+	PushStream(Comet_PushStreamServer) error
 }
 
 // UnimplementedCometServer can be embedded to have forward compatible implementations.
@@ -483,6 +1153,12 @@ func (*UnimplementedCometServer) BroadcastRoom(ctx context.Context, req *Broadca
 func (*UnimplementedCometServer) Rooms(ctx context.Context, req *RoomsReq) (*RoomsReply, error) {
 	return nil, status.Error(codes.Unimplemented, "method Rooms not implemented")
 }
+func (*UnimplementedCometServer) RoomMembers(ctx context.Context, req *RoomMembersReq) (*RoomMembersReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method RoomMembers not implemented")
+}
+func (*UnimplementedCometServer) PushStream(srv Comet_PushStreamServer) error {
+	return status.Error(codes.Unimplemented, "method PushStream not implemented")
+}
 
 func RegisterCometServer(s *grpc.Server, srv CometServer) {
 	s.RegisterService(&_Comet_serviceDesc, srv)
@@ -560,6 +1236,50 @@ func _Comet_Rooms_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Comet_RoomMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoomMembersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CometServer).RoomMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.comet.Comet/RoomMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CometServer).RoomMembers(ctx, req.(*RoomMembersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Comet_PushStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CometServer).PushStream(&cometPushStreamServer{stream})
+}
+
+type Comet_PushStreamServer interface {
+	Send(*PushStreamAck) error
+	Recv() (*PushStreamReq, error)
+	grpc.ServerStream
+}
+
+type cometPushStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *cometPushStreamServer) Send(m *PushStreamAck) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *cometPushStreamServer) Recv() (*PushStreamReq, error) {
+	m := new(PushStreamReq)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _Comet_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "goim.comet.Comet",
 	HandlerType: (*CometServer)(nil),
@@ -580,6 +1300,167 @@ var _Comet_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Rooms",
 			Handler:    _Comet_Rooms_Handler,
 		},
+		{
+			MethodName: "RoomMembers",
+			Handler:    _Comet_RoomMembers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushStream",
+			Handler:       _Comet_PushStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "comet/comet.proto",
+}
+
+// CometAdminClient is the client API for CometAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CometAdminClient interface {
+	// Ban ban the ips and mids, their connections are closed
+	Ban(ctx context.Context, in *BanReq, opts ...grpc.CallOption) (*BanReply, error)
+	// Unban remove the bans of the ips and mids
+	Unban(ctx context.Context, in *UnbanReq, opts ...grpc.CallOption) (*UnbanReply, error)
+	// Bans get all the bans
+	Bans(ctx context.Context, in *BansReq, opts ...grpc.CallOption) (*BansReply, error)
+}
+
+type cometAdminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCometAdminClient(cc *grpc.ClientConn) CometAdminClient {
+	return &cometAdminClient{cc}
+}
+
+func (c *cometAdminClient) Ban(ctx context.Context, in *BanReq, opts ...grpc.CallOption) (*BanReply, error) {
+	out := new(BanReply)
+	err := c.cc.Invoke(ctx, "/goim.comet.CometAdmin/Ban", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cometAdminClient) Unban(ctx context.Context, in *UnbanReq, opts ...grpc.CallOption) (*UnbanReply, error) {
+	out := new(UnbanReply)
+	err := c.cc.Invoke(ctx, "/goim.comet.CometAdmin/Unban", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cometAdminClient) Bans(ctx context.Context, in *BansReq, opts ...grpc.CallOption) (*BansReply, error) {
+	out := new(BansReply)
+	err := c.cc.Invoke(ctx, "/goim.comet.CometAdmin/Bans", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CometAdminServer is the server API for CometAdmin service.
+type CometAdminServer interface {
+	// Ban ban the ips and mids, their connections are closed
+	Ban(context.Context, *BanReq) (*BanReply, error)
+	// Unban remove the bans of the ips and mids
+	Unban(context.Context, *UnbanReq) (*UnbanReply, error)
+	// Bans get all the bans
+	Bans(context.Context, *BansReq) (*BansReply, error)
+}
+
+// UnimplementedCometAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedCometAdminServer struct {
+}
+
+func (*UnimplementedCometAdminServer) Ban(ctx context.Context, req *BanReq) (*BanReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Ban not implemented")
+}
+func (*UnimplementedCometAdminServer) Unban(ctx context.Context, req *UnbanReq) (*UnbanReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Unban not implemented")
+}
+func (*UnimplementedCometAdminServer) Bans(ctx context.Context, req *BansReq) (*BansReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Bans not implemented")
+}
+
+func RegisterCometAdminServer(s *grpc.Server, srv CometAdminServer) {
+	s.RegisterService(&_CometAdmin_serviceDesc, srv)
+}
+
+func _CometAdmin_Ban_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CometAdminServer).Ban(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.comet.CometAdmin/Ban",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CometAdminServer).Ban(ctx, req.(*BanReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CometAdmin_Unban_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbanReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CometAdminServer).Unban(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.comet.CometAdmin/Unban",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CometAdminServer).Unban(ctx, req.(*UnbanReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CometAdmin_Bans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BansReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CometAdminServer).Bans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.comet.CometAdmin/Bans",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CometAdminServer).Bans(ctx, req.(*BansReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _CometAdmin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "goim.comet.CometAdmin",
+	HandlerType: (*CometAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ban",
+			Handler:    _CometAdmin_Ban_Handler,
+		},
+		{
+			MethodName: "Unban",
+			Handler:    _CometAdmin_Unban_Handler,
+		},
+		{
+			MethodName: "Bans",
+			Handler:    _CometAdmin_Bans_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "comet/comet.proto",
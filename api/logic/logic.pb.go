@@ -31,18 +31,21 @@ const (
 	PushMsg_PUSH      PushMsg_Type = 0
 	PushMsg_ROOM      PushMsg_Type = 1
 	PushMsg_BROADCAST PushMsg_Type = 2
+	PushMsg_BATCH     PushMsg_Type = 3
 )
 
 var PushMsg_Type_name = map[int32]string{
 	0: "PUSH",
 	1: "ROOM",
 	2: "BROADCAST",
+	3: "BATCH",
 }
 
 var PushMsg_Type_value = map[string]int32{
 	"PUSH":      0,
 	"ROOM":      1,
 	"BROADCAST": 2,
+	"BATCH":     3,
 }
 
 func (x PushMsg_Type) String() string {
@@ -53,17 +56,79 @@ func (PushMsg_Type) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_2dfb3aef05fe3328, []int{0, 0}
 }
 
+type PushMsg_Priority int32
+
+const (
+	PushMsg_NORMAL PushMsg_Priority = 0
+	PushMsg_HIGH   PushMsg_Priority = 1
+)
+
+var PushMsg_Priority_name = map[int32]string{
+	0: "NORMAL",
+	1: "HIGH",
+}
+
+var PushMsg_Priority_value = map[string]int32{
+	"NORMAL": 0,
+	"HIGH":   1,
+}
+
+func (x PushMsg_Priority) String() string {
+	return proto.EnumName(PushMsg_Priority_name, int32(x))
+}
+
+func (PushMsg_Priority) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{0, 1}
+}
+
+type ConnEvent_Type int32
+
+const (
+	ConnEvent_CONNECT    ConnEvent_Type = 0
+	ConnEvent_DISCONNECT ConnEvent_Type = 1
+	ConnEvent_HEARTBEAT  ConnEvent_Type = 2
+	ConnEvent_RECEIVE    ConnEvent_Type = 3
+	ConnEvent_ONLINE     ConnEvent_Type = 4
+)
+
+var ConnEvent_Type_name = map[int32]string{
+	0: "CONNECT",
+	1: "DISCONNECT",
+	2: "HEARTBEAT",
+	3: "RECEIVE",
+	4: "ONLINE",
+}
+
+var ConnEvent_Type_value = map[string]int32{
+	"CONNECT":    0,
+	"DISCONNECT": 1,
+	"HEARTBEAT":  2,
+	"RECEIVE":    3,
+	"ONLINE":     4,
+}
+
+func (x ConnEvent_Type) String() string {
+	return proto.EnumName(ConnEvent_Type_name, int32(x))
+}
+
+func (ConnEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{14, 0}
+}
+
 type PushMsg struct {
-	Type                 PushMsg_Type `protobuf:"varint,1,opt,name=type,proto3,enum=goim.logic.PushMsg_Type" json:"type,omitempty"`
-	Operation            int32        `protobuf:"varint,2,opt,name=operation,proto3" json:"operation,omitempty"`
-	Speed                int32        `protobuf:"varint,3,opt,name=speed,proto3" json:"speed,omitempty"`
-	Server               string       `protobuf:"bytes,4,opt,name=server,proto3" json:"server,omitempty"`
-	Room                 string       `protobuf:"bytes,5,opt,name=room,proto3" json:"room,omitempty"`
-	Keys                 []string     `protobuf:"bytes,6,rep,name=keys,proto3" json:"keys,omitempty"`
-	Msg                  []byte       `protobuf:"bytes,7,opt,name=msg,proto3" json:"msg,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	Type                 PushMsg_Type     `protobuf:"varint,1,opt,name=type,proto3,enum=goim.logic.PushMsg_Type" json:"type,omitempty"`
+	Operation            int32            `protobuf:"varint,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	Speed                int32            `protobuf:"varint,3,opt,name=speed,proto3" json:"speed,omitempty"`
+	Server               string           `protobuf:"bytes,4,opt,name=server,proto3" json:"server,omitempty"`
+	Room                 string           `protobuf:"bytes,5,opt,name=room,proto3" json:"room,omitempty"`
+	Keys                 []string         `protobuf:"bytes,6,rep,name=keys,proto3" json:"keys,omitempty"`
+	Msg                  []byte           `protobuf:"bytes,7,opt,name=msg,proto3" json:"msg,omitempty"`
+	Priority             PushMsg_Priority `protobuf:"varint,8,opt,name=priority,proto3,enum=goim.logic.PushMsg_Priority" json:"priority,omitempty"`
+	MsgID                string           `protobuf:"bytes,9,opt,name=msgID,proto3" json:"msgID,omitempty"`
+	Items                []*PushItem      `protobuf:"bytes,10,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *PushMsg) Reset()         { *m = PushMsg{} }
@@ -140,6 +205,177 @@ func (m *PushMsg) GetMsg() []byte {
 	return nil
 }
 
+func (m *PushMsg) GetPriority() PushMsg_Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return PushMsg_NORMAL
+}
+
+func (m *PushMsg) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
+func (m *PushMsg) GetItems() []*PushItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+// PushItem is one message of a batch push, mids are resolved to keys by logic.
+type PushItem struct {
+	Operation            int32    `protobuf:"varint,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Keys                 []string `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty"`
+	Mids                 []int64  `protobuf:"varint,3,rep,packed,name=mids,proto3" json:"mids,omitempty"`
+	Msg                  []byte   `protobuf:"bytes,4,opt,name=msg,proto3" json:"msg,omitempty"`
+	MsgID                string   `protobuf:"bytes,5,opt,name=msgID,proto3" json:"msgID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PushItem) Reset()         { *m = PushItem{} }
+func (m *PushItem) String() string { return proto.CompactTextString(m) }
+func (*PushItem) ProtoMessage()    {}
+func (*PushItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{1}
+}
+
+func (m *PushItem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushItem.Unmarshal(m, b)
+}
+func (m *PushItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushItem.Marshal(b, m, deterministic)
+}
+func (m *PushItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushItem.Merge(m, src)
+}
+func (m *PushItem) XXX_Size() int {
+	return xxx_messageInfo_PushItem.Size(m)
+}
+func (m *PushItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushItem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushItem proto.InternalMessageInfo
+
+func (m *PushItem) GetOperation() int32 {
+	if m != nil {
+		return m.Operation
+	}
+	return 0
+}
+
+func (m *PushItem) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *PushItem) GetMids() []int64 {
+	if m != nil {
+		return m.Mids
+	}
+	return nil
+}
+
+func (m *PushItem) GetMsg() []byte {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (m *PushItem) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
+type PushBatchReq struct {
+	Items                []*PushItem      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Priority             PushMsg_Priority `protobuf:"varint,2,opt,name=priority,proto3,enum=goim.logic.PushMsg_Priority" json:"priority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PushBatchReq) Reset()         { *m = PushBatchReq{} }
+func (m *PushBatchReq) String() string { return proto.CompactTextString(m) }
+func (*PushBatchReq) ProtoMessage()    {}
+func (*PushBatchReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{2}
+}
+
+func (m *PushBatchReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushBatchReq.Unmarshal(m, b)
+}
+func (m *PushBatchReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushBatchReq.Marshal(b, m, deterministic)
+}
+func (m *PushBatchReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushBatchReq.Merge(m, src)
+}
+func (m *PushBatchReq) XXX_Size() int {
+	return xxx_messageInfo_PushBatchReq.Size(m)
+}
+func (m *PushBatchReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushBatchReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushBatchReq proto.InternalMessageInfo
+
+func (m *PushBatchReq) GetItems() []*PushItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+func (m *PushBatchReq) GetPriority() PushMsg_Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return PushMsg_NORMAL
+}
+
+type PushBatchReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PushBatchReply) Reset()         { *m = PushBatchReply{} }
+func (m *PushBatchReply) String() string { return proto.CompactTextString(m) }
+func (*PushBatchReply) ProtoMessage()    {}
+func (*PushBatchReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{3}
+}
+
+func (m *PushBatchReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushBatchReply.Unmarshal(m, b)
+}
+func (m *PushBatchReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushBatchReply.Marshal(b, m, deterministic)
+}
+func (m *PushBatchReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushBatchReply.Merge(m, src)
+}
+func (m *PushBatchReply) XXX_Size() int {
+	return xxx_messageInfo_PushBatchReply.Size(m)
+}
+func (m *PushBatchReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushBatchReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushBatchReply proto.InternalMessageInfo
+
 type ConnectReq struct {
 	Server               string   `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
 	Cookie               string   `protobuf:"bytes,2,opt,name=cookie,proto3" json:"cookie,omitempty"`
@@ -153,7 +389,7 @@ func (m *ConnectReq) Reset()         { *m = ConnectReq{} }
 func (m *ConnectReq) String() string { return proto.CompactTextString(m) }
 func (*ConnectReq) ProtoMessage()    {}
 func (*ConnectReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{1}
+	return fileDescriptor_2dfb3aef05fe3328, []int{4}
 }
 
 func (m *ConnectReq) XXX_Unmarshal(b []byte) error {
@@ -210,7 +446,7 @@ func (m *ConnectReply) Reset()         { *m = ConnectReply{} }
 func (m *ConnectReply) String() string { return proto.CompactTextString(m) }
 func (*ConnectReply) ProtoMessage()    {}
 func (*ConnectReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{2}
+	return fileDescriptor_2dfb3aef05fe3328, []int{5}
 }
 
 func (m *ConnectReply) XXX_Unmarshal(b []byte) error {
@@ -279,7 +515,7 @@ func (m *DisconnectReq) Reset()         { *m = DisconnectReq{} }
 func (m *DisconnectReq) String() string { return proto.CompactTextString(m) }
 func (*DisconnectReq) ProtoMessage()    {}
 func (*DisconnectReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{3}
+	return fileDescriptor_2dfb3aef05fe3328, []int{6}
 }
 
 func (m *DisconnectReq) XXX_Unmarshal(b []byte) error {
@@ -332,7 +568,7 @@ func (m *DisconnectReply) Reset()         { *m = DisconnectReply{} }
 func (m *DisconnectReply) String() string { return proto.CompactTextString(m) }
 func (*DisconnectReply) ProtoMessage()    {}
 func (*DisconnectReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{4}
+	return fileDescriptor_2dfb3aef05fe3328, []int{7}
 }
 
 func (m *DisconnectReply) XXX_Unmarshal(b []byte) error {
@@ -373,7 +609,7 @@ func (m *HeartbeatReq) Reset()         { *m = HeartbeatReq{} }
 func (m *HeartbeatReq) String() string { return proto.CompactTextString(m) }
 func (*HeartbeatReq) ProtoMessage()    {}
 func (*HeartbeatReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{5}
+	return fileDescriptor_2dfb3aef05fe3328, []int{8}
 }
 
 func (m *HeartbeatReq) XXX_Unmarshal(b []byte) error {
@@ -425,7 +661,7 @@ func (m *HeartbeatReply) Reset()         { *m = HeartbeatReply{} }
 func (m *HeartbeatReply) String() string { return proto.CompactTextString(m) }
 func (*HeartbeatReply) ProtoMessage()    {}
 func (*HeartbeatReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{6}
+	return fileDescriptor_2dfb3aef05fe3328, []int{9}
 }
 
 func (m *HeartbeatReply) XXX_Unmarshal(b []byte) error {
@@ -446,9 +682,59 @@ func (m *HeartbeatReply) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_HeartbeatReply proto.InternalMessageInfo
 
+type HeartbeatsReq struct {
+	Server               string          `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	Heartbeats           []*HeartbeatReq `protobuf:"bytes,2,rep,name=heartbeats,proto3" json:"heartbeats,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *HeartbeatsReq) Reset()         { *m = HeartbeatsReq{} }
+func (m *HeartbeatsReq) String() string { return proto.CompactTextString(m) }
+func (*HeartbeatsReq) ProtoMessage()    {}
+func (*HeartbeatsReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{10}
+}
+
+func (m *HeartbeatsReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeartbeatsReq.Unmarshal(m, b)
+}
+func (m *HeartbeatsReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeartbeatsReq.Marshal(b, m, deterministic)
+}
+func (m *HeartbeatsReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeartbeatsReq.Merge(m, src)
+}
+func (m *HeartbeatsReq) XXX_Size() int {
+	return xxx_messageInfo_HeartbeatsReq.Size(m)
+}
+func (m *HeartbeatsReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeartbeatsReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeartbeatsReq proto.InternalMessageInfo
+
+func (m *HeartbeatsReq) GetServer() string {
+	if m != nil {
+		return m.Server
+	}
+	return ""
+}
+
+func (m *HeartbeatsReq) GetHeartbeats() []*HeartbeatReq {
+	if m != nil {
+		return m.Heartbeats
+	}
+	return nil
+}
+
+// OnlineReq is the room counts of a server, delta is true if roomCount only has the rooms
+// changed since the last one, a zero count means the room is gone.
 type OnlineReq struct {
 	Server               string           `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
 	RoomCount            map[string]int32 `protobuf:"bytes,2,rep,name=roomCount,proto3" json:"roomCount,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Delta                bool             `protobuf:"varint,3,opt,name=delta,proto3" json:"delta,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
 	XXX_unrecognized     []byte           `json:"-"`
 	XXX_sizecache        int32            `json:"-"`
@@ -458,7 +744,7 @@ func (m *OnlineReq) Reset()         { *m = OnlineReq{} }
 func (m *OnlineReq) String() string { return proto.CompactTextString(m) }
 func (*OnlineReq) ProtoMessage()    {}
 func (*OnlineReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{7}
+	return fileDescriptor_2dfb3aef05fe3328, []int{11}
 }
 
 func (m *OnlineReq) XXX_Unmarshal(b []byte) error {
@@ -493,6 +779,14 @@ func (m *OnlineReq) GetRoomCount() map[string]int32 {
 	return nil
 }
 
+func (m *OnlineReq) GetDelta() bool {
+	if m != nil {
+		return m.Delta
+	}
+	return false
+}
+
+// OnlineReply is the room counts of all the servers loaded by the logic.
 type OnlineReply struct {
 	AllRoomCount         map[string]int32 `protobuf:"bytes,1,rep,name=allRoomCount,proto3" json:"allRoomCount,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
@@ -504,7 +798,7 @@ func (m *OnlineReply) Reset()         { *m = OnlineReply{} }
 func (m *OnlineReply) String() string { return proto.CompactTextString(m) }
 func (*OnlineReply) ProtoMessage()    {}
 func (*OnlineReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{8}
+	return fileDescriptor_2dfb3aef05fe3328, []int{12}
 }
 
 func (m *OnlineReply) XXX_Unmarshal(b []byte) error {
@@ -532,637 +826,2645 @@ func (m *OnlineReply) GetAllRoomCount() map[string]int32 {
 	return nil
 }
 
-type ReceiveReq struct {
-	Mid                  int64           `protobuf:"varint,1,opt,name=mid,proto3" json:"mid,omitempty"`
-	Proto                *protocol.Proto `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+// OnlineDelta is the room counts changed since the last one, a zero count means the room is gone.
+// full is true if the room counts are complete, like the first one on a stream.
+type OnlineDelta struct {
+	Server    string           `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	RoomCount map[string]int32 `protobuf:"bytes,2,rep,name=roomCount,proto3" json:"roomCount,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Full      bool             `protobuf:"varint,3,opt,name=full,proto3" json:"full,omitempty"`
+	// ack is the seq of the last online reply applied by the comet, the next reply is the changes since it
+	Ack                  int64    `protobuf:"varint,4,opt,name=ack,proto3" json:"ack,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ReceiveReq) Reset()         { *m = ReceiveReq{} }
-func (m *ReceiveReq) String() string { return proto.CompactTextString(m) }
-func (*ReceiveReq) ProtoMessage()    {}
-func (*ReceiveReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{9}
+func (m *OnlineDelta) Reset()         { *m = OnlineDelta{} }
+func (m *OnlineDelta) String() string { return proto.CompactTextString(m) }
+func (*OnlineDelta) ProtoMessage()    {}
+func (*OnlineDelta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{13}
 }
 
-func (m *ReceiveReq) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ReceiveReq.Unmarshal(m, b)
+func (m *OnlineDelta) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnlineDelta.Unmarshal(m, b)
 }
-func (m *ReceiveReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ReceiveReq.Marshal(b, m, deterministic)
+func (m *OnlineDelta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnlineDelta.Marshal(b, m, deterministic)
 }
-func (m *ReceiveReq) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ReceiveReq.Merge(m, src)
+func (m *OnlineDelta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnlineDelta.Merge(m, src)
 }
-func (m *ReceiveReq) XXX_Size() int {
-	return xxx_messageInfo_ReceiveReq.Size(m)
+func (m *OnlineDelta) XXX_Size() int {
+	return xxx_messageInfo_OnlineDelta.Size(m)
 }
-func (m *ReceiveReq) XXX_DiscardUnknown() {
-	xxx_messageInfo_ReceiveReq.DiscardUnknown(m)
+func (m *OnlineDelta) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnlineDelta.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ReceiveReq proto.InternalMessageInfo
+var xxx_messageInfo_OnlineDelta proto.InternalMessageInfo
 
-func (m *ReceiveReq) GetMid() int64 {
+func (m *OnlineDelta) GetServer() string {
 	if m != nil {
-		return m.Mid
+		return m.Server
 	}
-	return 0
+	return ""
 }
 
-func (m *ReceiveReq) GetProto() *protocol.Proto {
+func (m *OnlineDelta) GetRoomCount() map[string]int32 {
 	if m != nil {
-		return m.Proto
+		return m.RoomCount
 	}
 	return nil
 }
 
-type ReceiveReply struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *OnlineDelta) GetFull() bool {
+	if m != nil {
+		return m.Full
+	}
+	return false
 }
 
-func (m *ReceiveReply) Reset()         { *m = ReceiveReply{} }
-func (m *ReceiveReply) String() string { return proto.CompactTextString(m) }
-func (*ReceiveReply) ProtoMessage()    {}
-func (*ReceiveReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{10}
+func (m *OnlineDelta) GetAck() int64 {
+	if m != nil {
+		return m.Ack
+	}
+	return 0
 }
 
-func (m *ReceiveReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ReceiveReply.Unmarshal(m, b)
+// ConnEvent is a connection event of a comet multiplexed on the stream,
+// the reply has the same seq, a zero seq has no reply.
+type ConnEvent struct {
+	Type       ConnEvent_Type `protobuf:"varint,1,opt,name=type,proto3,enum=goim.logic.ConnEvent_Type" json:"type,omitempty"`
+	Seq        int64          `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Connect    *ConnectReq    `protobuf:"bytes,3,opt,name=connect,proto3" json:"connect,omitempty"`
+	Disconnect *DisconnectReq `protobuf:"bytes,4,opt,name=disconnect,proto3" json:"disconnect,omitempty"`
+	// heartbeats of many channels are batched into one event
+	Heartbeats           []*HeartbeatReq `protobuf:"bytes,5,rep,name=heartbeats,proto3" json:"heartbeats,omitempty"`
+	Receive              *ReceiveReq     `protobuf:"bytes,6,opt,name=receive,proto3" json:"receive,omitempty"`
+	Online               *OnlineDelta    `protobuf:"bytes,7,opt,name=online,proto3" json:"online,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
-func (m *ReceiveReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ReceiveReply.Marshal(b, m, deterministic)
+
+func (m *ConnEvent) Reset()         { *m = ConnEvent{} }
+func (m *ConnEvent) String() string { return proto.CompactTextString(m) }
+func (*ConnEvent) ProtoMessage()    {}
+func (*ConnEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{14}
 }
-func (m *ReceiveReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ReceiveReply.Merge(m, src)
+
+func (m *ConnEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConnEvent.Unmarshal(m, b)
 }
-func (m *ReceiveReply) XXX_Size() int {
-	return xxx_messageInfo_ReceiveReply.Size(m)
+func (m *ConnEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConnEvent.Marshal(b, m, deterministic)
 }
-func (m *ReceiveReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_ReceiveReply.DiscardUnknown(m)
+func (m *ConnEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConnEvent.Merge(m, src)
 }
-
-var xxx_messageInfo_ReceiveReply proto.InternalMessageInfo
-
-type NodesReq struct {
-	Platform             string   `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
-	ClientIP             string   `protobuf:"bytes,2,opt,name=clientIP,proto3" json:"clientIP,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *ConnEvent) XXX_Size() int {
+	return xxx_messageInfo_ConnEvent.Size(m)
+}
+func (m *ConnEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConnEvent.DiscardUnknown(m)
 }
 
-func (m *NodesReq) Reset()         { *m = NodesReq{} }
-func (m *NodesReq) String() string { return proto.CompactTextString(m) }
-func (*NodesReq) ProtoMessage()    {}
-func (*NodesReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{11}
+var xxx_messageInfo_ConnEvent proto.InternalMessageInfo
+
+func (m *ConnEvent) GetType() ConnEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return ConnEvent_CONNECT
 }
 
-func (m *NodesReq) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_NodesReq.Unmarshal(m, b)
-}
-func (m *NodesReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_NodesReq.Marshal(b, m, deterministic)
-}
-func (m *NodesReq) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_NodesReq.Merge(m, src)
+func (m *ConnEvent) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
 }
-func (m *NodesReq) XXX_Size() int {
-	return xxx_messageInfo_NodesReq.Size(m)
+
+func (m *ConnEvent) GetConnect() *ConnectReq {
+	if m != nil {
+		return m.Connect
+	}
+	return nil
 }
-func (m *NodesReq) XXX_DiscardUnknown() {
-	xxx_messageInfo_NodesReq.DiscardUnknown(m)
+
+func (m *ConnEvent) GetDisconnect() *DisconnectReq {
+	if m != nil {
+		return m.Disconnect
+	}
+	return nil
 }
 
-var xxx_messageInfo_NodesReq proto.InternalMessageInfo
+func (m *ConnEvent) GetHeartbeats() []*HeartbeatReq {
+	if m != nil {
+		return m.Heartbeats
+	}
+	return nil
+}
 
-func (m *NodesReq) GetPlatform() string {
+func (m *ConnEvent) GetReceive() *ReceiveReq {
 	if m != nil {
-		return m.Platform
+		return m.Receive
 	}
-	return ""
+	return nil
 }
 
-func (m *NodesReq) GetClientIP() string {
+func (m *ConnEvent) GetOnline() *OnlineDelta {
 	if m != nil {
-		return m.ClientIP
+		return m.Online
 	}
-	return ""
+	return nil
 }
 
-type NodesReply struct {
-	Domain               string   `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
-	TcpPort              int32    `protobuf:"varint,2,opt,name=tcp_port,json=tcpPort,proto3" json:"tcp_port,omitempty"`
-	WsPort               int32    `protobuf:"varint,3,opt,name=ws_port,json=wsPort,proto3" json:"ws_port,omitempty"`
-	WssPort              int32    `protobuf:"varint,4,opt,name=wss_port,json=wssPort,proto3" json:"wss_port,omitempty"`
-	Heartbeat            int32    `protobuf:"varint,5,opt,name=heartbeat,proto3" json:"heartbeat,omitempty"`
-	Nodes                []string `protobuf:"bytes,6,rep,name=nodes,proto3" json:"nodes,omitempty"`
-	Backoff              *Backoff `protobuf:"bytes,7,opt,name=backoff,proto3" json:"backoff,omitempty"`
-	HeartbeatMax         int32    `protobuf:"varint,8,opt,name=heartbeat_max,json=heartbeatMax,proto3" json:"heartbeat_max,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type ConnEventReply struct {
+	Type       ConnEvent_Type   `protobuf:"varint,1,opt,name=type,proto3,enum=goim.logic.ConnEvent_Type" json:"type,omitempty"`
+	Seq        int64            `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Error      string           `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Connect    *ConnectReply    `protobuf:"bytes,4,opt,name=connect,proto3" json:"connect,omitempty"`
+	Disconnect *DisconnectReply `protobuf:"bytes,5,opt,name=disconnect,proto3" json:"disconnect,omitempty"`
+	// online is the changes of all the room counts since the last reply
+	Online               *OnlineDelta `protobuf:"bytes,6,opt,name=online,proto3" json:"online,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
-func (m *NodesReply) Reset()         { *m = NodesReply{} }
-func (m *NodesReply) String() string { return proto.CompactTextString(m) }
-func (*NodesReply) ProtoMessage()    {}
-func (*NodesReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{12}
+func (m *ConnEventReply) Reset()         { *m = ConnEventReply{} }
+func (m *ConnEventReply) String() string { return proto.CompactTextString(m) }
+func (*ConnEventReply) ProtoMessage()    {}
+func (*ConnEventReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{15}
 }
 
-func (m *NodesReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_NodesReply.Unmarshal(m, b)
+func (m *ConnEventReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConnEventReply.Unmarshal(m, b)
 }
-func (m *NodesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_NodesReply.Marshal(b, m, deterministic)
+func (m *ConnEventReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConnEventReply.Marshal(b, m, deterministic)
 }
-func (m *NodesReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_NodesReply.Merge(m, src)
+func (m *ConnEventReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConnEventReply.Merge(m, src)
 }
-func (m *NodesReply) XXX_Size() int {
-	return xxx_messageInfo_NodesReply.Size(m)
+func (m *ConnEventReply) XXX_Size() int {
+	return xxx_messageInfo_ConnEventReply.Size(m)
 }
-func (m *NodesReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_NodesReply.DiscardUnknown(m)
+func (m *ConnEventReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConnEventReply.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_NodesReply proto.InternalMessageInfo
+var xxx_messageInfo_ConnEventReply proto.InternalMessageInfo
 
-func (m *NodesReply) GetDomain() string {
+func (m *ConnEventReply) GetType() ConnEvent_Type {
 	if m != nil {
-		return m.Domain
+		return m.Type
 	}
-	return ""
+	return ConnEvent_CONNECT
 }
 
-func (m *NodesReply) GetTcpPort() int32 {
+func (m *ConnEventReply) GetSeq() int64 {
 	if m != nil {
-		return m.TcpPort
+		return m.Seq
 	}
 	return 0
 }
 
-func (m *NodesReply) GetWsPort() int32 {
+func (m *ConnEventReply) GetError() string {
 	if m != nil {
-		return m.WsPort
+		return m.Error
 	}
-	return 0
+	return ""
 }
 
-func (m *NodesReply) GetWssPort() int32 {
+func (m *ConnEventReply) GetConnect() *ConnectReply {
 	if m != nil {
-		return m.WssPort
+		return m.Connect
 	}
-	return 0
+	return nil
 }
 
-func (m *NodesReply) GetHeartbeat() int32 {
+func (m *ConnEventReply) GetDisconnect() *DisconnectReply {
 	if m != nil {
-		return m.Heartbeat
+		return m.Disconnect
 	}
-	return 0
+	return nil
 }
 
-func (m *NodesReply) GetNodes() []string {
+func (m *ConnEventReply) GetOnline() *OnlineDelta {
 	if m != nil {
-		return m.Nodes
+		return m.Online
 	}
 	return nil
 }
 
-func (m *NodesReply) GetBackoff() *Backoff {
+// ReceiveReq roomID is the room joined by the connection.
+type ReceiveReq struct {
+	Mid                  int64           `protobuf:"varint,1,opt,name=mid,proto3" json:"mid,omitempty"`
+	Proto                *protocol.Proto `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`
+	RoomID               string          `protobuf:"bytes,3,opt,name=roomID,proto3" json:"roomID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ReceiveReq) Reset()         { *m = ReceiveReq{} }
+func (m *ReceiveReq) String() string { return proto.CompactTextString(m) }
+func (*ReceiveReq) ProtoMessage()    {}
+func (*ReceiveReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{16}
+}
+
+func (m *ReceiveReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReceiveReq.Unmarshal(m, b)
+}
+func (m *ReceiveReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReceiveReq.Marshal(b, m, deterministic)
+}
+func (m *ReceiveReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReceiveReq.Merge(m, src)
+}
+func (m *ReceiveReq) XXX_Size() int {
+	return xxx_messageInfo_ReceiveReq.Size(m)
+}
+func (m *ReceiveReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReceiveReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReceiveReq proto.InternalMessageInfo
+
+func (m *ReceiveReq) GetMid() int64 {
 	if m != nil {
-		return m.Backoff
+		return m.Mid
+	}
+	return 0
+}
+
+func (m *ReceiveReq) GetProto() *protocol.Proto {
+	if m != nil {
+		return m.Proto
 	}
 	return nil
 }
 
-func (m *NodesReply) GetHeartbeatMax() int32 {
+func (m *ReceiveReq) GetRoomID() string {
 	if m != nil {
-		return m.HeartbeatMax
+		return m.RoomID
 	}
-	return 0
+	return ""
 }
 
-type Backoff struct {
-	MaxDelay             int32    `protobuf:"varint,1,opt,name=max_delay,json=maxDelay,proto3" json:"max_delay,omitempty"`
-	BaseDelay            int32    `protobuf:"varint,2,opt,name=base_delay,json=baseDelay,proto3" json:"base_delay,omitempty"`
-	Factor               float32  `protobuf:"fixed32,3,opt,name=factor,proto3" json:"factor,omitempty"`
-	Jitter               float32  `protobuf:"fixed32,4,opt,name=jitter,proto3" json:"jitter,omitempty"`
+type ReceiveReply struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Backoff) Reset()         { *m = Backoff{} }
-func (m *Backoff) String() string { return proto.CompactTextString(m) }
-func (*Backoff) ProtoMessage()    {}
-func (*Backoff) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2dfb3aef05fe3328, []int{13}
+func (m *ReceiveReply) Reset()         { *m = ReceiveReply{} }
+func (m *ReceiveReply) String() string { return proto.CompactTextString(m) }
+func (*ReceiveReply) ProtoMessage()    {}
+func (*ReceiveReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{17}
 }
 
-func (m *Backoff) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Backoff.Unmarshal(m, b)
+func (m *ReceiveReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReceiveReply.Unmarshal(m, b)
 }
-func (m *Backoff) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Backoff.Marshal(b, m, deterministic)
+func (m *ReceiveReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReceiveReply.Marshal(b, m, deterministic)
 }
-func (m *Backoff) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Backoff.Merge(m, src)
+func (m *ReceiveReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReceiveReply.Merge(m, src)
 }
-func (m *Backoff) XXX_Size() int {
-	return xxx_messageInfo_Backoff.Size(m)
+func (m *ReceiveReply) XXX_Size() int {
+	return xxx_messageInfo_ReceiveReply.Size(m)
 }
-func (m *Backoff) XXX_DiscardUnknown() {
-	xxx_messageInfo_Backoff.DiscardUnknown(m)
+func (m *ReceiveReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReceiveReply.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Backoff proto.InternalMessageInfo
+var xxx_messageInfo_ReceiveReply proto.InternalMessageInfo
 
-func (m *Backoff) GetMaxDelay() int32 {
+type HistoryReq struct {
+	Mid                  int64           `protobuf:"varint,1,opt,name=mid,proto3" json:"mid,omitempty"`
+	Proto                *protocol.Proto `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`
+	RoomID               string          `protobuf:"bytes,3,opt,name=roomID,proto3" json:"roomID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *HistoryReq) Reset()         { *m = HistoryReq{} }
+func (m *HistoryReq) String() string { return proto.CompactTextString(m) }
+func (*HistoryReq) ProtoMessage()    {}
+func (*HistoryReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{18}
+}
+
+func (m *HistoryReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryReq.Unmarshal(m, b)
+}
+func (m *HistoryReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryReq.Marshal(b, m, deterministic)
+}
+func (m *HistoryReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryReq.Merge(m, src)
+}
+func (m *HistoryReq) XXX_Size() int {
+	return xxx_messageInfo_HistoryReq.Size(m)
+}
+func (m *HistoryReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryReq proto.InternalMessageInfo
+
+func (m *HistoryReq) GetMid() int64 {
 	if m != nil {
-		return m.MaxDelay
+		return m.Mid
 	}
 	return 0
 }
 
-func (m *Backoff) GetBaseDelay() int32 {
+func (m *HistoryReq) GetProto() *protocol.Proto {
 	if m != nil {
-		return m.BaseDelay
+		return m.Proto
+	}
+	return nil
+}
+
+func (m *HistoryReq) GetRoomID() string {
+	if m != nil {
+		return m.RoomID
+	}
+	return ""
+}
+
+type HistoryReply struct {
+	Body                 []byte   `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HistoryReply) Reset()         { *m = HistoryReply{} }
+func (m *HistoryReply) String() string { return proto.CompactTextString(m) }
+func (*HistoryReply) ProtoMessage()    {}
+func (*HistoryReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{19}
+}
+
+func (m *HistoryReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryReply.Unmarshal(m, b)
+}
+func (m *HistoryReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryReply.Marshal(b, m, deterministic)
+}
+func (m *HistoryReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryReply.Merge(m, src)
+}
+func (m *HistoryReply) XXX_Size() int {
+	return xxx_messageInfo_HistoryReply.Size(m)
+}
+func (m *HistoryReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryReply proto.InternalMessageInfo
+
+func (m *HistoryReply) GetBody() []byte {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+type SignalReq struct {
+	Mid                  int64           `protobuf:"varint,1,opt,name=mid,proto3" json:"mid,omitempty"`
+	Key                  string          `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	RoomID               string          `protobuf:"bytes,3,opt,name=roomID,proto3" json:"roomID,omitempty"`
+	Proto                *protocol.Proto `protobuf:"bytes,4,opt,name=proto,proto3" json:"proto,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *SignalReq) Reset()         { *m = SignalReq{} }
+func (m *SignalReq) String() string { return proto.CompactTextString(m) }
+func (*SignalReq) ProtoMessage()    {}
+func (*SignalReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{20}
+}
+
+func (m *SignalReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignalReq.Unmarshal(m, b)
+}
+func (m *SignalReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignalReq.Marshal(b, m, deterministic)
+}
+func (m *SignalReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignalReq.Merge(m, src)
+}
+func (m *SignalReq) XXX_Size() int {
+	return xxx_messageInfo_SignalReq.Size(m)
+}
+func (m *SignalReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignalReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignalReq proto.InternalMessageInfo
+
+func (m *SignalReq) GetMid() int64 {
+	if m != nil {
+		return m.Mid
 	}
 	return 0
 }
 
-func (m *Backoff) GetFactor() float32 {
+func (m *SignalReq) GetKey() string {
 	if m != nil {
-		return m.Factor
+		return m.Key
+	}
+	return ""
+}
+
+func (m *SignalReq) GetRoomID() string {
+	if m != nil {
+		return m.RoomID
+	}
+	return ""
+}
+
+func (m *SignalReq) GetProto() *protocol.Proto {
+	if m != nil {
+		return m.Proto
+	}
+	return nil
+}
+
+type SignalReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignalReply) Reset()         { *m = SignalReply{} }
+func (m *SignalReply) String() string { return proto.CompactTextString(m) }
+func (*SignalReply) ProtoMessage()    {}
+func (*SignalReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{21}
+}
+
+func (m *SignalReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignalReply.Unmarshal(m, b)
+}
+func (m *SignalReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignalReply.Marshal(b, m, deterministic)
+}
+func (m *SignalReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignalReply.Merge(m, src)
+}
+func (m *SignalReply) XXX_Size() int {
+	return xxx_messageInfo_SignalReply.Size(m)
+}
+func (m *SignalReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignalReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignalReply proto.InternalMessageInfo
+
+type NodesReq struct {
+	Platform             string   `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	ClientIP             string   `protobuf:"bytes,2,opt,name=clientIP,proto3" json:"clientIP,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NodesReq) Reset()         { *m = NodesReq{} }
+func (m *NodesReq) String() string { return proto.CompactTextString(m) }
+func (*NodesReq) ProtoMessage()    {}
+func (*NodesReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{22}
+}
+
+func (m *NodesReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodesReq.Unmarshal(m, b)
+}
+func (m *NodesReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodesReq.Marshal(b, m, deterministic)
+}
+func (m *NodesReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodesReq.Merge(m, src)
+}
+func (m *NodesReq) XXX_Size() int {
+	return xxx_messageInfo_NodesReq.Size(m)
+}
+func (m *NodesReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodesReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodesReq proto.InternalMessageInfo
+
+func (m *NodesReq) GetPlatform() string {
+	if m != nil {
+		return m.Platform
+	}
+	return ""
+}
+
+func (m *NodesReq) GetClientIP() string {
+	if m != nil {
+		return m.ClientIP
+	}
+	return ""
+}
+
+type NodesReply struct {
+	Domain               string   `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	TcpPort              int32    `protobuf:"varint,2,opt,name=tcp_port,json=tcpPort,proto3" json:"tcp_port,omitempty"`
+	WsPort               int32    `protobuf:"varint,3,opt,name=ws_port,json=wsPort,proto3" json:"ws_port,omitempty"`
+	WssPort              int32    `protobuf:"varint,4,opt,name=wss_port,json=wssPort,proto3" json:"wss_port,omitempty"`
+	Heartbeat            int32    `protobuf:"varint,5,opt,name=heartbeat,proto3" json:"heartbeat,omitempty"`
+	Nodes                []string `protobuf:"bytes,6,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Backoff              *Backoff `protobuf:"bytes,7,opt,name=backoff,proto3" json:"backoff,omitempty"`
+	HeartbeatMax         int32    `protobuf:"varint,8,opt,name=heartbeat_max,json=heartbeatMax,proto3" json:"heartbeat_max,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NodesReply) Reset()         { *m = NodesReply{} }
+func (m *NodesReply) String() string { return proto.CompactTextString(m) }
+func (*NodesReply) ProtoMessage()    {}
+func (*NodesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{23}
+}
+
+func (m *NodesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodesReply.Unmarshal(m, b)
+}
+func (m *NodesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodesReply.Marshal(b, m, deterministic)
+}
+func (m *NodesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodesReply.Merge(m, src)
+}
+func (m *NodesReply) XXX_Size() int {
+	return xxx_messageInfo_NodesReply.Size(m)
+}
+func (m *NodesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodesReply proto.InternalMessageInfo
+
+func (m *NodesReply) GetDomain() string {
+	if m != nil {
+		return m.Domain
+	}
+	return ""
+}
+
+func (m *NodesReply) GetTcpPort() int32 {
+	if m != nil {
+		return m.TcpPort
+	}
+	return 0
+}
+
+func (m *NodesReply) GetWsPort() int32 {
+	if m != nil {
+		return m.WsPort
+	}
+	return 0
+}
+
+func (m *NodesReply) GetWssPort() int32 {
+	if m != nil {
+		return m.WssPort
+	}
+	return 0
+}
+
+func (m *NodesReply) GetHeartbeat() int32 {
+	if m != nil {
+		return m.Heartbeat
+	}
+	return 0
+}
+
+func (m *NodesReply) GetNodes() []string {
+	if m != nil {
+		return m.Nodes
+	}
+	return nil
+}
+
+func (m *NodesReply) GetBackoff() *Backoff {
+	if m != nil {
+		return m.Backoff
+	}
+	return nil
+}
+
+func (m *NodesReply) GetHeartbeatMax() int32 {
+	if m != nil {
+		return m.HeartbeatMax
+	}
+	return 0
+}
+
+type Backoff struct {
+	MaxDelay             int32    `protobuf:"varint,1,opt,name=max_delay,json=maxDelay,proto3" json:"max_delay,omitempty"`
+	BaseDelay            int32    `protobuf:"varint,2,opt,name=base_delay,json=baseDelay,proto3" json:"base_delay,omitempty"`
+	Factor               float32  `protobuf:"fixed32,3,opt,name=factor,proto3" json:"factor,omitempty"`
+	Jitter               float32  `protobuf:"fixed32,4,opt,name=jitter,proto3" json:"jitter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Backoff) Reset()         { *m = Backoff{} }
+func (m *Backoff) String() string { return proto.CompactTextString(m) }
+func (*Backoff) ProtoMessage()    {}
+func (*Backoff) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{24}
+}
+
+func (m *Backoff) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Backoff.Unmarshal(m, b)
+}
+func (m *Backoff) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Backoff.Marshal(b, m, deterministic)
+}
+func (m *Backoff) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Backoff.Merge(m, src)
+}
+func (m *Backoff) XXX_Size() int {
+	return xxx_messageInfo_Backoff.Size(m)
+}
+func (m *Backoff) XXX_DiscardUnknown() {
+	xxx_messageInfo_Backoff.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Backoff proto.InternalMessageInfo
+
+func (m *Backoff) GetMaxDelay() int32 {
+	if m != nil {
+		return m.MaxDelay
+	}
+	return 0
+}
+
+func (m *Backoff) GetBaseDelay() int32 {
+	if m != nil {
+		return m.BaseDelay
+	}
+	return 0
+}
+
+func (m *Backoff) GetFactor() float32 {
+	if m != nil {
+		return m.Factor
+	}
+	return 0
+}
+
+func (m *Backoff) GetJitter() float32 {
+	if m != nil {
+		return m.Jitter
+	}
+	return 0
+}
+
+type PushKeysReq struct {
+	Operation            int32            `protobuf:"varint,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Priority             PushMsg_Priority `protobuf:"varint,2,opt,name=priority,proto3,enum=goim.logic.PushMsg_Priority" json:"priority,omitempty"`
+	MsgID                string           `protobuf:"bytes,3,opt,name=msgID,proto3" json:"msgID,omitempty"`
+	Keys                 []string         `protobuf:"bytes,4,rep,name=keys,proto3" json:"keys,omitempty"`
+	Msg                  []byte           `protobuf:"bytes,5,opt,name=msg,proto3" json:"msg,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PushKeysReq) Reset()         { *m = PushKeysReq{} }
+func (m *PushKeysReq) String() string { return proto.CompactTextString(m) }
+func (*PushKeysReq) ProtoMessage()    {}
+func (*PushKeysReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{25}
+}
+
+func (m *PushKeysReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushKeysReq.Unmarshal(m, b)
+}
+func (m *PushKeysReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushKeysReq.Marshal(b, m, deterministic)
+}
+func (m *PushKeysReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushKeysReq.Merge(m, src)
+}
+func (m *PushKeysReq) XXX_Size() int {
+	return xxx_messageInfo_PushKeysReq.Size(m)
+}
+func (m *PushKeysReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushKeysReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushKeysReq proto.InternalMessageInfo
+
+func (m *PushKeysReq) GetOperation() int32 {
+	if m != nil {
+		return m.Operation
+	}
+	return 0
+}
+
+func (m *PushKeysReq) GetPriority() PushMsg_Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return PushMsg_NORMAL
+}
+
+func (m *PushKeysReq) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
+func (m *PushKeysReq) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *PushKeysReq) GetMsg() []byte {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+// PushReport is the routing result of a push.
+type PushReport struct {
+	MsgID                string           `protobuf:"bytes,1,opt,name=msgID,proto3" json:"msgID,omitempty"`
+	Duplicated           bool             `protobuf:"varint,2,opt,name=duplicated,proto3" json:"duplicated,omitempty"`
+	OnlineMids           []int64          `protobuf:"varint,3,rep,packed,name=onlineMids,proto3" json:"onlineMids,omitempty"`
+	OfflineMids          []int64          `protobuf:"varint,4,rep,packed,name=offlineMids,proto3" json:"offlineMids,omitempty"`
+	OfflineKeys          []string         `protobuf:"bytes,5,rep,name=offlineKeys,proto3" json:"offlineKeys,omitempty"`
+	Servers              map[string]int32 `protobuf:"bytes,6,rep,name=servers,proto3" json:"servers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PushReport) Reset()         { *m = PushReport{} }
+func (m *PushReport) String() string { return proto.CompactTextString(m) }
+func (*PushReport) ProtoMessage()    {}
+func (*PushReport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{26}
+}
+
+func (m *PushReport) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushReport.Unmarshal(m, b)
+}
+func (m *PushReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushReport.Marshal(b, m, deterministic)
+}
+func (m *PushReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushReport.Merge(m, src)
+}
+func (m *PushReport) XXX_Size() int {
+	return xxx_messageInfo_PushReport.Size(m)
+}
+func (m *PushReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushReport proto.InternalMessageInfo
+
+func (m *PushReport) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
+func (m *PushReport) GetDuplicated() bool {
+	if m != nil {
+		return m.Duplicated
+	}
+	return false
+}
+
+func (m *PushReport) GetOnlineMids() []int64 {
+	if m != nil {
+		return m.OnlineMids
+	}
+	return nil
+}
+
+func (m *PushReport) GetOfflineMids() []int64 {
+	if m != nil {
+		return m.OfflineMids
+	}
+	return nil
+}
+
+func (m *PushReport) GetOfflineKeys() []string {
+	if m != nil {
+		return m.OfflineKeys
+	}
+	return nil
+}
+
+func (m *PushReport) GetServers() map[string]int32 {
+	if m != nil {
+		return m.Servers
+	}
+	return nil
+}
+
+type PushKeysReply struct {
+	Report               *PushReport `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *PushKeysReply) Reset()         { *m = PushKeysReply{} }
+func (m *PushKeysReply) String() string { return proto.CompactTextString(m) }
+func (*PushKeysReply) ProtoMessage()    {}
+func (*PushKeysReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{27}
+}
+
+func (m *PushKeysReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushKeysReply.Unmarshal(m, b)
+}
+func (m *PushKeysReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushKeysReply.Marshal(b, m, deterministic)
+}
+func (m *PushKeysReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushKeysReply.Merge(m, src)
+}
+func (m *PushKeysReply) XXX_Size() int {
+	return xxx_messageInfo_PushKeysReply.Size(m)
+}
+func (m *PushKeysReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushKeysReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushKeysReply proto.InternalMessageInfo
+
+func (m *PushKeysReply) GetReport() *PushReport {
+	if m != nil {
+		return m.Report
+	}
+	return nil
+}
+
+type PushMidsReq struct {
+	Operation int32            `protobuf:"varint,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Priority  PushMsg_Priority `protobuf:"varint,2,opt,name=priority,proto3,enum=goim.logic.PushMsg_Priority" json:"priority,omitempty"`
+	MsgID     string           `protobuf:"bytes,3,opt,name=msgID,proto3" json:"msgID,omitempty"`
+	Mids      []int64          `protobuf:"varint,4,rep,packed,name=mids,proto3" json:"mids,omitempty"`
+	Msg       []byte           `protobuf:"bytes,5,opt,name=msg,proto3" json:"msg,omitempty"`
+	// platforms only push to the sessions of these platforms, empty is all
+	Platforms []string `protobuf:"bytes,6,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	// latest only push to the most recent connected session of each mid
+	Latest               bool     `protobuf:"varint,7,opt,name=latest,proto3" json:"latest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PushMidsReq) Reset()         { *m = PushMidsReq{} }
+func (m *PushMidsReq) String() string { return proto.CompactTextString(m) }
+func (*PushMidsReq) ProtoMessage()    {}
+func (*PushMidsReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{28}
+}
+
+func (m *PushMidsReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushMidsReq.Unmarshal(m, b)
+}
+func (m *PushMidsReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushMidsReq.Marshal(b, m, deterministic)
+}
+func (m *PushMidsReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushMidsReq.Merge(m, src)
+}
+func (m *PushMidsReq) XXX_Size() int {
+	return xxx_messageInfo_PushMidsReq.Size(m)
+}
+func (m *PushMidsReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushMidsReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushMidsReq proto.InternalMessageInfo
+
+func (m *PushMidsReq) GetOperation() int32 {
+	if m != nil {
+		return m.Operation
+	}
+	return 0
+}
+
+func (m *PushMidsReq) GetPriority() PushMsg_Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return PushMsg_NORMAL
+}
+
+func (m *PushMidsReq) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
+func (m *PushMidsReq) GetMids() []int64 {
+	if m != nil {
+		return m.Mids
+	}
+	return nil
+}
+
+func (m *PushMidsReq) GetMsg() []byte {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (m *PushMidsReq) GetPlatforms() []string {
+	if m != nil {
+		return m.Platforms
+	}
+	return nil
+}
+
+func (m *PushMidsReq) GetLatest() bool {
+	if m != nil {
+		return m.Latest
+	}
+	return false
+}
+
+type PushMidsReply struct {
+	Report               *PushReport `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *PushMidsReply) Reset()         { *m = PushMidsReply{} }
+func (m *PushMidsReply) String() string { return proto.CompactTextString(m) }
+func (*PushMidsReply) ProtoMessage()    {}
+func (*PushMidsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{29}
+}
+
+func (m *PushMidsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushMidsReply.Unmarshal(m, b)
+}
+func (m *PushMidsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushMidsReply.Marshal(b, m, deterministic)
+}
+func (m *PushMidsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushMidsReply.Merge(m, src)
+}
+func (m *PushMidsReply) XXX_Size() int {
+	return xxx_messageInfo_PushMidsReply.Size(m)
+}
+func (m *PushMidsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushMidsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushMidsReply proto.InternalMessageInfo
+
+func (m *PushMidsReply) GetReport() *PushReport {
+	if m != nil {
+		return m.Report
+	}
+	return nil
+}
+
+type PushRoomReq struct {
+	Operation            int32            `protobuf:"varint,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Priority             PushMsg_Priority `protobuf:"varint,2,opt,name=priority,proto3,enum=goim.logic.PushMsg_Priority" json:"priority,omitempty"`
+	MsgID                string           `protobuf:"bytes,3,opt,name=msgID,proto3" json:"msgID,omitempty"`
+	Type                 string           `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Room                 string           `protobuf:"bytes,5,opt,name=room,proto3" json:"room,omitempty"`
+	Msg                  []byte           `protobuf:"bytes,6,opt,name=msg,proto3" json:"msg,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PushRoomReq) Reset()         { *m = PushRoomReq{} }
+func (m *PushRoomReq) String() string { return proto.CompactTextString(m) }
+func (*PushRoomReq) ProtoMessage()    {}
+func (*PushRoomReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{30}
+}
+
+func (m *PushRoomReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushRoomReq.Unmarshal(m, b)
+}
+func (m *PushRoomReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushRoomReq.Marshal(b, m, deterministic)
+}
+func (m *PushRoomReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushRoomReq.Merge(m, src)
+}
+func (m *PushRoomReq) XXX_Size() int {
+	return xxx_messageInfo_PushRoomReq.Size(m)
+}
+func (m *PushRoomReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushRoomReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushRoomReq proto.InternalMessageInfo
+
+func (m *PushRoomReq) GetOperation() int32 {
+	if m != nil {
+		return m.Operation
+	}
+	return 0
+}
+
+func (m *PushRoomReq) GetPriority() PushMsg_Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return PushMsg_NORMAL
+}
+
+func (m *PushRoomReq) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
+func (m *PushRoomReq) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *PushRoomReq) GetRoom() string {
+	if m != nil {
+		return m.Room
+	}
+	return ""
+}
+
+func (m *PushRoomReq) GetMsg() []byte {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+type PushRoomReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PushRoomReply) Reset()         { *m = PushRoomReply{} }
+func (m *PushRoomReply) String() string { return proto.CompactTextString(m) }
+func (*PushRoomReply) ProtoMessage()    {}
+func (*PushRoomReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{31}
+}
+
+func (m *PushRoomReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushRoomReply.Unmarshal(m, b)
+}
+func (m *PushRoomReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushRoomReply.Marshal(b, m, deterministic)
+}
+func (m *PushRoomReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushRoomReply.Merge(m, src)
+}
+func (m *PushRoomReply) XXX_Size() int {
+	return xxx_messageInfo_PushRoomReply.Size(m)
+}
+func (m *PushRoomReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushRoomReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushRoomReply proto.InternalMessageInfo
+
+type PushAllReq struct {
+	Operation            int32            `protobuf:"varint,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Priority             PushMsg_Priority `protobuf:"varint,2,opt,name=priority,proto3,enum=goim.logic.PushMsg_Priority" json:"priority,omitempty"`
+	MsgID                string           `protobuf:"bytes,3,opt,name=msgID,proto3" json:"msgID,omitempty"`
+	Speed                int32            `protobuf:"varint,4,opt,name=speed,proto3" json:"speed,omitempty"`
+	Msg                  []byte           `protobuf:"bytes,5,opt,name=msg,proto3" json:"msg,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PushAllReq) Reset()         { *m = PushAllReq{} }
+func (m *PushAllReq) String() string { return proto.CompactTextString(m) }
+func (*PushAllReq) ProtoMessage()    {}
+func (*PushAllReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{32}
+}
+
+func (m *PushAllReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushAllReq.Unmarshal(m, b)
+}
+func (m *PushAllReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushAllReq.Marshal(b, m, deterministic)
+}
+func (m *PushAllReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushAllReq.Merge(m, src)
+}
+func (m *PushAllReq) XXX_Size() int {
+	return xxx_messageInfo_PushAllReq.Size(m)
+}
+func (m *PushAllReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushAllReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushAllReq proto.InternalMessageInfo
+
+func (m *PushAllReq) GetOperation() int32 {
+	if m != nil {
+		return m.Operation
+	}
+	return 0
+}
+
+func (m *PushAllReq) GetPriority() PushMsg_Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return PushMsg_NORMAL
+}
+
+func (m *PushAllReq) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
+func (m *PushAllReq) GetSpeed() int32 {
+	if m != nil {
+		return m.Speed
+	}
+	return 0
+}
+
+func (m *PushAllReq) GetMsg() []byte {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+type PushAllReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PushAllReply) Reset()         { *m = PushAllReply{} }
+func (m *PushAllReply) String() string { return proto.CompactTextString(m) }
+func (*PushAllReply) ProtoMessage()    {}
+func (*PushAllReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{33}
+}
+
+func (m *PushAllReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushAllReply.Unmarshal(m, b)
+}
+func (m *PushAllReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushAllReply.Marshal(b, m, deterministic)
+}
+func (m *PushAllReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushAllReply.Merge(m, src)
+}
+func (m *PushAllReply) XXX_Size() int {
+	return xxx_messageInfo_PushAllReply.Size(m)
+}
+func (m *PushAllReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushAllReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushAllReply proto.InternalMessageInfo
+
+type OnlineTopReq struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OnlineTopReq) Reset()         { *m = OnlineTopReq{} }
+func (m *OnlineTopReq) String() string { return proto.CompactTextString(m) }
+func (*OnlineTopReq) ProtoMessage()    {}
+func (*OnlineTopReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{34}
+}
+
+func (m *OnlineTopReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnlineTopReq.Unmarshal(m, b)
+}
+func (m *OnlineTopReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnlineTopReq.Marshal(b, m, deterministic)
+}
+func (m *OnlineTopReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnlineTopReq.Merge(m, src)
+}
+func (m *OnlineTopReq) XXX_Size() int {
+	return xxx_messageInfo_OnlineTopReq.Size(m)
+}
+func (m *OnlineTopReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnlineTopReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnlineTopReq proto.InternalMessageInfo
+
+func (m *OnlineTopReq) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *OnlineTopReq) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type OnlineTopReply struct {
+	Tops                 []*OnlineTopReply_Top `protobuf:"bytes,1,rep,name=tops,proto3" json:"tops,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *OnlineTopReply) Reset()         { *m = OnlineTopReply{} }
+func (m *OnlineTopReply) String() string { return proto.CompactTextString(m) }
+func (*OnlineTopReply) ProtoMessage()    {}
+func (*OnlineTopReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{35}
+}
+
+func (m *OnlineTopReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnlineTopReply.Unmarshal(m, b)
+}
+func (m *OnlineTopReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnlineTopReply.Marshal(b, m, deterministic)
+}
+func (m *OnlineTopReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnlineTopReply.Merge(m, src)
+}
+func (m *OnlineTopReply) XXX_Size() int {
+	return xxx_messageInfo_OnlineTopReply.Size(m)
+}
+func (m *OnlineTopReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnlineTopReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnlineTopReply proto.InternalMessageInfo
+
+func (m *OnlineTopReply) GetTops() []*OnlineTopReply_Top {
+	if m != nil {
+		return m.Tops
+	}
+	return nil
+}
+
+type OnlineTopReply_Top struct {
+	RoomID               string   `protobuf:"bytes,1,opt,name=roomID,proto3" json:"roomID,omitempty"`
+	Count                int32    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OnlineTopReply_Top) Reset()         { *m = OnlineTopReply_Top{} }
+func (m *OnlineTopReply_Top) String() string { return proto.CompactTextString(m) }
+func (*OnlineTopReply_Top) ProtoMessage()    {}
+func (*OnlineTopReply_Top) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{35, 0}
+}
+
+func (m *OnlineTopReply_Top) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnlineTopReply_Top.Unmarshal(m, b)
+}
+func (m *OnlineTopReply_Top) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnlineTopReply_Top.Marshal(b, m, deterministic)
+}
+func (m *OnlineTopReply_Top) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnlineTopReply_Top.Merge(m, src)
+}
+func (m *OnlineTopReply_Top) XXX_Size() int {
+	return xxx_messageInfo_OnlineTopReply_Top.Size(m)
+}
+func (m *OnlineTopReply_Top) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnlineTopReply_Top.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnlineTopReply_Top proto.InternalMessageInfo
+
+func (m *OnlineTopReply_Top) GetRoomID() string {
+	if m != nil {
+		return m.RoomID
+	}
+	return ""
+}
+
+func (m *OnlineTopReply_Top) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type OnlineRoomReq struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Rooms                []string `protobuf:"bytes,2,rep,name=rooms,proto3" json:"rooms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OnlineRoomReq) Reset()         { *m = OnlineRoomReq{} }
+func (m *OnlineRoomReq) String() string { return proto.CompactTextString(m) }
+func (*OnlineRoomReq) ProtoMessage()    {}
+func (*OnlineRoomReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{36}
+}
+
+func (m *OnlineRoomReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnlineRoomReq.Unmarshal(m, b)
+}
+func (m *OnlineRoomReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnlineRoomReq.Marshal(b, m, deterministic)
+}
+func (m *OnlineRoomReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnlineRoomReq.Merge(m, src)
+}
+func (m *OnlineRoomReq) XXX_Size() int {
+	return xxx_messageInfo_OnlineRoomReq.Size(m)
+}
+func (m *OnlineRoomReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnlineRoomReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnlineRoomReq proto.InternalMessageInfo
+
+func (m *OnlineRoomReq) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *OnlineRoomReq) GetRooms() []string {
+	if m != nil {
+		return m.Rooms
+	}
+	return nil
+}
+
+type OnlineRoomReply struct {
+	Rooms                map[string]int32 `protobuf:"bytes,1,rep,name=rooms,proto3" json:"rooms,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *OnlineRoomReply) Reset()         { *m = OnlineRoomReply{} }
+func (m *OnlineRoomReply) String() string { return proto.CompactTextString(m) }
+func (*OnlineRoomReply) ProtoMessage()    {}
+func (*OnlineRoomReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{37}
+}
+
+func (m *OnlineRoomReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnlineRoomReply.Unmarshal(m, b)
+}
+func (m *OnlineRoomReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnlineRoomReply.Marshal(b, m, deterministic)
+}
+func (m *OnlineRoomReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnlineRoomReply.Merge(m, src)
+}
+func (m *OnlineRoomReply) XXX_Size() int {
+	return xxx_messageInfo_OnlineRoomReply.Size(m)
+}
+func (m *OnlineRoomReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnlineRoomReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnlineRoomReply proto.InternalMessageInfo
+
+func (m *OnlineRoomReply) GetRooms() map[string]int32 {
+	if m != nil {
+		return m.Rooms
+	}
+	return nil
+}
+
+type OnlineTotalReq struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OnlineTotalReq) Reset()         { *m = OnlineTotalReq{} }
+func (m *OnlineTotalReq) String() string { return proto.CompactTextString(m) }
+func (*OnlineTotalReq) ProtoMessage()    {}
+func (*OnlineTotalReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{38}
+}
+
+func (m *OnlineTotalReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnlineTotalReq.Unmarshal(m, b)
+}
+func (m *OnlineTotalReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnlineTotalReq.Marshal(b, m, deterministic)
+}
+func (m *OnlineTotalReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnlineTotalReq.Merge(m, src)
+}
+func (m *OnlineTotalReq) XXX_Size() int {
+	return xxx_messageInfo_OnlineTotalReq.Size(m)
+}
+func (m *OnlineTotalReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnlineTotalReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnlineTotalReq proto.InternalMessageInfo
+
+type OnlineTotalReply struct {
+	IpCount              int64    `protobuf:"varint,1,opt,name=ipCount,proto3" json:"ipCount,omitempty"`
+	ConnCount            int64    `protobuf:"varint,2,opt,name=connCount,proto3" json:"connCount,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OnlineTotalReply) Reset()         { *m = OnlineTotalReply{} }
+func (m *OnlineTotalReply) String() string { return proto.CompactTextString(m) }
+func (*OnlineTotalReply) ProtoMessage()    {}
+func (*OnlineTotalReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{39}
+}
+
+func (m *OnlineTotalReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnlineTotalReply.Unmarshal(m, b)
+}
+func (m *OnlineTotalReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnlineTotalReply.Marshal(b, m, deterministic)
+}
+func (m *OnlineTotalReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnlineTotalReply.Merge(m, src)
+}
+func (m *OnlineTotalReply) XXX_Size() int {
+	return xxx_messageInfo_OnlineTotalReply.Size(m)
+}
+func (m *OnlineTotalReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnlineTotalReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnlineTotalReply proto.InternalMessageInfo
+
+func (m *OnlineTotalReply) GetIpCount() int64 {
+	if m != nil {
+		return m.IpCount
+	}
+	return 0
+}
+
+func (m *OnlineTotalReply) GetConnCount() int64 {
+	if m != nil {
+		return m.ConnCount
+	}
+	return 0
+}
+
+type PresenceReq struct {
+	Mids                 []int64  `protobuf:"varint,1,rep,packed,name=mids,proto3" json:"mids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PresenceReq) Reset()         { *m = PresenceReq{} }
+func (m *PresenceReq) String() string { return proto.CompactTextString(m) }
+func (*PresenceReq) ProtoMessage()    {}
+func (*PresenceReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{40}
+}
+
+func (m *PresenceReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PresenceReq.Unmarshal(m, b)
+}
+func (m *PresenceReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PresenceReq.Marshal(b, m, deterministic)
+}
+func (m *PresenceReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PresenceReq.Merge(m, src)
+}
+func (m *PresenceReq) XXX_Size() int {
+	return xxx_messageInfo_PresenceReq.Size(m)
+}
+func (m *PresenceReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_PresenceReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PresenceReq proto.InternalMessageInfo
+
+func (m *PresenceReq) GetMids() []int64 {
+	if m != nil {
+		return m.Mids
+	}
+	return nil
+}
+
+type PresenceReply struct {
+	Online               map[int64]bool `protobuf:"bytes,1,rep,name=online,proto3" json:"online,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *PresenceReply) Reset()         { *m = PresenceReply{} }
+func (m *PresenceReply) String() string { return proto.CompactTextString(m) }
+func (*PresenceReply) ProtoMessage()    {}
+func (*PresenceReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2dfb3aef05fe3328, []int{41}
+}
+
+func (m *PresenceReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PresenceReply.Unmarshal(m, b)
+}
+func (m *PresenceReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PresenceReply.Marshal(b, m, deterministic)
+}
+func (m *PresenceReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PresenceReply.Merge(m, src)
+}
+func (m *PresenceReply) XXX_Size() int {
+	return xxx_messageInfo_PresenceReply.Size(m)
+}
+func (m *PresenceReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PresenceReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PresenceReply proto.InternalMessageInfo
+
+func (m *PresenceReply) GetOnline() map[int64]bool {
+	if m != nil {
+		return m.Online
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("goim.logic.PushMsg_Type", PushMsg_Type_name, PushMsg_Type_value)
+	proto.RegisterEnum("goim.logic.PushMsg_Priority", PushMsg_Priority_name, PushMsg_Priority_value)
+	proto.RegisterEnum("goim.logic.ConnEvent_Type", ConnEvent_Type_name, ConnEvent_Type_value)
+	proto.RegisterType((*PushMsg)(nil), "goim.logic.PushMsg")
+	proto.RegisterType((*PushItem)(nil), "goim.logic.PushItem")
+	proto.RegisterType((*PushBatchReq)(nil), "goim.logic.PushBatchReq")
+	proto.RegisterType((*PushBatchReply)(nil), "goim.logic.PushBatchReply")
+	proto.RegisterType((*ConnectReq)(nil), "goim.logic.ConnectReq")
+	proto.RegisterType((*ConnectReply)(nil), "goim.logic.ConnectReply")
+	proto.RegisterType((*DisconnectReq)(nil), "goim.logic.DisconnectReq")
+	proto.RegisterType((*DisconnectReply)(nil), "goim.logic.DisconnectReply")
+	proto.RegisterType((*HeartbeatReq)(nil), "goim.logic.HeartbeatReq")
+	proto.RegisterType((*HeartbeatReply)(nil), "goim.logic.HeartbeatReply")
+	proto.RegisterType((*HeartbeatsReq)(nil), "goim.logic.HeartbeatsReq")
+	proto.RegisterType((*OnlineReq)(nil), "goim.logic.OnlineReq")
+	proto.RegisterMapType((map[string]int32)(nil), "goim.logic.OnlineReq.RoomCountEntry")
+	proto.RegisterType((*OnlineReply)(nil), "goim.logic.OnlineReply")
+	proto.RegisterMapType((map[string]int32)(nil), "goim.logic.OnlineReply.AllRoomCountEntry")
+	proto.RegisterType((*OnlineDelta)(nil), "goim.logic.OnlineDelta")
+	proto.RegisterMapType((map[string]int32)(nil), "goim.logic.OnlineDelta.RoomCountEntry")
+	proto.RegisterType((*ConnEvent)(nil), "goim.logic.ConnEvent")
+	proto.RegisterType((*ConnEventReply)(nil), "goim.logic.ConnEventReply")
+	proto.RegisterType((*ReceiveReq)(nil), "goim.logic.ReceiveReq")
+	proto.RegisterType((*ReceiveReply)(nil), "goim.logic.ReceiveReply")
+	proto.RegisterType((*HistoryReq)(nil), "goim.logic.HistoryReq")
+	proto.RegisterType((*HistoryReply)(nil), "goim.logic.HistoryReply")
+	proto.RegisterType((*SignalReq)(nil), "goim.logic.SignalReq")
+	proto.RegisterType((*SignalReply)(nil), "goim.logic.SignalReply")
+	proto.RegisterType((*NodesReq)(nil), "goim.logic.NodesReq")
+	proto.RegisterType((*NodesReply)(nil), "goim.logic.NodesReply")
+	proto.RegisterType((*Backoff)(nil), "goim.logic.Backoff")
+	proto.RegisterType((*PushKeysReq)(nil), "goim.logic.PushKeysReq")
+	proto.RegisterType((*PushReport)(nil), "goim.logic.PushReport")
+	proto.RegisterMapType((map[string]int32)(nil), "goim.logic.PushReport.ServersEntry")
+	proto.RegisterType((*PushKeysReply)(nil), "goim.logic.PushKeysReply")
+	proto.RegisterType((*PushMidsReq)(nil), "goim.logic.PushMidsReq")
+	proto.RegisterType((*PushMidsReply)(nil), "goim.logic.PushMidsReply")
+	proto.RegisterType((*PushRoomReq)(nil), "goim.logic.PushRoomReq")
+	proto.RegisterType((*PushRoomReply)(nil), "goim.logic.PushRoomReply")
+	proto.RegisterType((*PushAllReq)(nil), "goim.logic.PushAllReq")
+	proto.RegisterType((*PushAllReply)(nil), "goim.logic.PushAllReply")
+	proto.RegisterType((*OnlineTopReq)(nil), "goim.logic.OnlineTopReq")
+	proto.RegisterType((*OnlineTopReply)(nil), "goim.logic.OnlineTopReply")
+	proto.RegisterType((*OnlineTopReply_Top)(nil), "goim.logic.OnlineTopReply.Top")
+	proto.RegisterType((*OnlineRoomReq)(nil), "goim.logic.OnlineRoomReq")
+	proto.RegisterType((*OnlineRoomReply)(nil), "goim.logic.OnlineRoomReply")
+	proto.RegisterMapType((map[string]int32)(nil), "goim.logic.OnlineRoomReply.RoomsEntry")
+	proto.RegisterType((*OnlineTotalReq)(nil), "goim.logic.OnlineTotalReq")
+	proto.RegisterType((*OnlineTotalReply)(nil), "goim.logic.OnlineTotalReply")
+	proto.RegisterType((*PresenceReq)(nil), "goim.logic.PresenceReq")
+	proto.RegisterType((*PresenceReply)(nil), "goim.logic.PresenceReply")
+	proto.RegisterMapType((map[int64]bool)(nil), "goim.logic.PresenceReply.OnlineEntry")
+}
+
+func init() { proto.RegisterFile("logic/logic.proto", fileDescriptor_2dfb3aef05fe3328) }
+
+var fileDescriptor_2dfb3aef05fe3328 = []byte{
+	// 2033 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x59, 0xcd, 0x6e, 0xe3, 0xc8,
+	0x11, 0x5e, 0x9a, 0xa4, 0x7e, 0x4a, 0xb2, 0x47, 0xdb, 0xf1, 0xda, 0x34, 0x67, 0xb2, 0x50, 0x38,
+	0x49, 0xe0, 0x2c, 0xb2, 0xf2, 0x42, 0x9b, 0x00, 0x9e, 0x9d, 0x9d, 0x0c, 0xfc, 0x23, 0xac, 0xbd,
+	0x33, 0xfe, 0x41, 0xdb, 0xc9, 0x21, 0x97, 0x01, 0x4d, 0xb5, 0x65, 0xc6, 0x14, 0xc9, 0x15, 0xe9,
+	0xb1, 0x95, 0x63, 0x6e, 0xc9, 0x2d, 0x0f, 0x90, 0xc3, 0x06, 0x79, 0x85, 0x9c, 0xf2, 0x10, 0x39,
+	0xe6, 0x94, 0x27, 0x09, 0x02, 0x04, 0xd5, 0xdd, 0x24, 0x9b, 0x16, 0xe5, 0x19, 0xef, 0x20, 0x3b,
+	0x17, 0xa1, 0xab, 0xba, 0xaa, 0xba, 0xfa, 0xab, 0xee, 0xaa, 0x6a, 0x0a, 0x3e, 0x0c, 0xa2, 0x91,
+	0xef, 0x6d, 0xf0, 0xdf, 0x5e, 0x3c, 0x89, 0xd2, 0x88, 0xc0, 0x28, 0xf2, 0xc7, 0x3d, 0xce, 0xb1,
+	0x9f, 0x8c, 0xfc, 0xf4, 0xe2, 0xea, 0xac, 0xe7, 0x45, 0xe3, 0x8d, 0xd0, 0x0f, 0x47, 0xde, 0x05,
+	0x0b, 0x47, 0xbf, 0x67, 0xe1, 0x68, 0x03, 0x85, 0x36, 0xdc, 0xd8, 0xdf, 0xe0, 0x4a, 0x5e, 0x14,
+	0xe4, 0x03, 0x61, 0xc6, 0xf9, 0xb3, 0x0e, 0xf5, 0xe3, 0xab, 0xe4, 0xe2, 0x20, 0x19, 0x91, 0x9f,
+	0x83, 0x91, 0x4e, 0x63, 0x66, 0x69, 0x5d, 0x6d, 0x7d, 0xa9, 0x6f, 0xf5, 0x8a, 0x15, 0x7a, 0x52,
+	0xa4, 0x77, 0x3a, 0x8d, 0x19, 0xe5, 0x52, 0xe4, 0x11, 0x34, 0xa3, 0x98, 0x4d, 0xdc, 0xd4, 0x8f,
+	0x42, 0x6b, 0xa1, 0xab, 0xad, 0x9b, 0xb4, 0x60, 0x90, 0x65, 0x30, 0x93, 0x98, 0xb1, 0xa1, 0xa5,
+	0xf3, 0x19, 0x41, 0x90, 0x15, 0xa8, 0x25, 0x6c, 0xf2, 0x9a, 0x4d, 0x2c, 0xa3, 0xab, 0xad, 0x37,
+	0xa9, 0xa4, 0x08, 0x01, 0x63, 0x12, 0x45, 0x63, 0xcb, 0xe4, 0x5c, 0x3e, 0x46, 0xde, 0x25, 0x9b,
+	0x26, 0x56, 0xad, 0xab, 0x23, 0x0f, 0xc7, 0xa4, 0x03, 0xfa, 0x38, 0x19, 0x59, 0xf5, 0xae, 0xb6,
+	0xde, 0xa6, 0x38, 0x24, 0x9b, 0xd0, 0x88, 0x27, 0x7e, 0x34, 0xf1, 0xd3, 0xa9, 0xd5, 0xe0, 0x7e,
+	0x3f, 0xaa, 0xf2, 0xfb, 0x58, 0xca, 0xd0, 0x5c, 0x1a, 0x3d, 0x1c, 0x27, 0xa3, 0xfd, 0x5d, 0xab,
+	0xc9, 0x17, 0x15, 0x04, 0xf9, 0x04, 0x4c, 0x3f, 0x65, 0xe3, 0xc4, 0x82, 0xae, 0xbe, 0xde, 0xea,
+	0x2f, 0xdf, 0x36, 0xb6, 0x9f, 0xb2, 0x31, 0x15, 0x22, 0xce, 0x2f, 0xc0, 0x40, 0x3c, 0x48, 0x03,
+	0x8c, 0xe3, 0x5f, 0x9f, 0xec, 0x75, 0x3e, 0xc0, 0x11, 0x3d, 0x3a, 0x3a, 0xe8, 0x68, 0x64, 0x11,
+	0x9a, 0xdb, 0xf4, 0x68, 0x6b, 0x77, 0x67, 0xeb, 0xe4, 0xb4, 0xb3, 0x40, 0x9a, 0x60, 0x6e, 0x6f,
+	0x9d, 0xee, 0xec, 0x75, 0x74, 0xa7, 0x0b, 0x8d, 0xcc, 0x1b, 0x02, 0x50, 0x3b, 0x3c, 0xa2, 0x07,
+	0x5b, 0x2f, 0x85, 0xee, 0xde, 0xfe, 0x57, 0x7b, 0x1d, 0xcd, 0xb9, 0x81, 0x46, 0xb6, 0x54, 0x19,
+	0x65, 0xed, 0x36, 0xca, 0x19, 0x46, 0x0b, 0x0a, 0x46, 0x04, 0x8c, 0xb1, 0x3f, 0x4c, 0x2c, 0xbd,
+	0xab, 0xaf, 0xeb, 0x94, 0x8f, 0x33, 0xdc, 0x8c, 0x02, 0xb7, 0x7c, 0xf7, 0xa6, 0xb2, 0x7b, 0x27,
+	0x85, 0x36, 0xae, 0xbc, 0xed, 0xa6, 0xde, 0x05, 0x65, 0xdf, 0x14, 0x68, 0x68, 0x6f, 0x44, 0xa3,
+	0x14, 0x89, 0x85, 0xfb, 0x44, 0xc2, 0xe9, 0xc0, 0x92, 0xb2, 0x6a, 0x1c, 0x4c, 0x1d, 0x0a, 0xb0,
+	0x13, 0x85, 0x21, 0xf3, 0x52, 0xf4, 0xa2, 0x38, 0x35, 0x5a, 0xe9, 0xd4, 0xac, 0x40, 0xcd, 0x8b,
+	0xa2, 0x4b, 0x9f, 0xf1, 0xf5, 0x9a, 0x54, 0x52, 0xb8, 0xb7, 0x34, 0xba, 0x64, 0x21, 0x3f, 0x7b,
+	0x6d, 0x2a, 0x08, 0xe7, 0x0f, 0x1a, 0xb4, 0x73, 0xa3, 0x71, 0x30, 0xe5, 0xa0, 0xf8, 0x43, 0x6e,
+	0x53, 0xa7, 0x38, 0x44, 0xce, 0x25, 0x9b, 0x4a, 0x6b, 0x38, 0xc4, 0x25, 0xf0, 0x30, 0xee, 0xef,
+	0x72, 0x5b, 0x4d, 0x2a, 0x29, 0x62, 0x41, 0xdd, 0xf5, 0x3c, 0x16, 0xa7, 0x89, 0x65, 0x74, 0xf5,
+	0x75, 0x93, 0x66, 0x24, 0x06, 0xec, 0x82, 0xb9, 0x93, 0xf4, 0x8c, 0xb9, 0x29, 0x07, 0x57, 0xa7,
+	0x05, 0xc3, 0x79, 0x01, 0x8b, 0xbb, 0x7e, 0xe2, 0x15, 0x7b, 0x7b, 0x4b, 0x27, 0xe4, 0xfe, 0x75,
+	0x75, 0xff, 0xce, 0x63, 0x78, 0xa0, 0x1a, 0x93, 0x7b, 0xba, 0x70, 0x13, 0x6e, 0xae, 0x41, 0x71,
+	0xe8, 0x7c, 0x0d, 0xed, 0xbd, 0x6c, 0xf9, 0x77, 0x5d, 0xb0, 0x03, 0x4b, 0x8a, 0x2d, 0x0c, 0x94,
+	0x0b, 0x8b, 0x39, 0x27, 0xb9, 0x2b, 0x56, 0x9b, 0x00, 0x39, 0x0a, 0xe2, 0xbc, 0xb6, 0xca, 0x19,
+	0x46, 0x75, 0x92, 0x2a, 0xb2, 0xce, 0x3f, 0x34, 0x68, 0x1e, 0x85, 0x81, 0x1f, 0xb2, 0xbb, 0xec,
+	0x6f, 0x43, 0x13, 0x43, 0xb3, 0x13, 0x5d, 0x85, 0xa9, 0x34, 0xff, 0x63, 0xd5, 0x7c, 0x6e, 0xa1,
+	0x47, 0x33, 0xb1, 0x41, 0x98, 0x4e, 0xa6, 0xb4, 0x50, 0xc3, 0x73, 0x33, 0x64, 0x41, 0xea, 0xf2,
+	0x5d, 0x37, 0xa8, 0x20, 0xec, 0x2f, 0x61, 0xa9, 0xac, 0x92, 0x01, 0xa6, 0x15, 0x80, 0x2d, 0x83,
+	0xf9, 0xda, 0x0d, 0xae, 0x98, 0xcc, 0x83, 0x82, 0xf8, 0x62, 0x61, 0x53, 0x73, 0xfe, 0xa2, 0x41,
+	0x2b, 0x5b, 0x1b, 0x03, 0x74, 0x00, 0x6d, 0x37, 0x08, 0x72, 0x83, 0xf2, 0x62, 0xfd, 0xac, 0xca,
+	0xd5, 0x38, 0x98, 0xf6, 0xb6, 0x82, 0xa0, 0xbc, 0x38, 0x2d, 0xa9, 0xdb, 0xcf, 0xe1, 0xc3, 0x19,
+	0x91, 0x7b, 0xf9, 0xf7, 0xcf, 0xdc, 0xbf, 0x5d, 0xdc, 0xed, 0x5c, 0x7c, 0x77, 0x67, 0xf1, 0xfd,
+	0xe9, 0xac, 0xd3, 0xdc, 0xc6, 0x1d, 0x08, 0x13, 0x30, 0xce, 0xaf, 0x82, 0x40, 0x02, 0xcc, 0xc7,
+	0xe8, 0xad, 0xeb, 0x5d, 0xf2, 0xdc, 0xa4, 0x53, 0x1c, 0xbe, 0x23, 0xe2, 0x7f, 0xd3, 0xa1, 0x89,
+	0xf7, 0x7c, 0xf0, 0x9a, 0x85, 0x29, 0xe9, 0x95, 0x6a, 0x9a, 0xad, 0xba, 0x9c, 0x0b, 0xa9, 0x55,
+	0xad, 0x03, 0x7a, 0xc2, 0xbe, 0xe1, 0x56, 0x75, 0x8a, 0x43, 0xf2, 0x19, 0xd4, 0xe5, 0x15, 0xe3,
+	0x6e, 0xb7, 0xfa, 0x2b, 0xb7, 0x8d, 0x88, 0xab, 0x4c, 0x33, 0x31, 0xf2, 0x04, 0x60, 0x98, 0xdf,
+	0x4b, 0xbe, 0xb1, 0x56, 0x7f, 0x4d, 0x55, 0x2a, 0xa5, 0x00, 0xaa, 0x08, 0xdf, 0xba, 0x26, 0xe6,
+	0xdb, 0x5f, 0x13, 0x74, 0x73, 0xc2, 0x3c, 0xe6, 0xbf, 0x66, 0x56, 0x6d, 0xd6, 0x4d, 0x2a, 0xa6,
+	0xb8, 0x9b, 0x52, 0x8c, 0x6c, 0x40, 0x2d, 0xe2, 0x51, 0xe3, 0xf5, 0xb4, 0xd5, 0x5f, 0x9d, 0x13,
+	0x4f, 0x2a, 0xc5, 0x9c, 0x17, 0xb2, 0xde, 0xb5, 0xa0, 0xbe, 0x73, 0x74, 0x78, 0x38, 0xd8, 0x39,
+	0xed, 0x7c, 0x40, 0x96, 0x00, 0x76, 0xf7, 0x4f, 0x32, 0x9a, 0x17, 0xbe, 0xbd, 0xc1, 0x16, 0x3d,
+	0xdd, 0x1e, 0x6c, 0x61, 0xe1, 0x6b, 0x41, 0x9d, 0x0e, 0x76, 0x06, 0xfb, 0xbf, 0x19, 0x74, 0x74,
+	0x2c, 0x77, 0x47, 0x87, 0x2f, 0xf7, 0x0f, 0x07, 0x1d, 0xc3, 0xf9, 0xd3, 0x02, 0x2c, 0xe5, 0x11,
+	0x10, 0x77, 0xe3, 0xdd, 0x63, 0xb5, 0x0c, 0x26, 0x9b, 0x4c, 0xa2, 0x2c, 0x6f, 0x09, 0x82, 0xf4,
+	0xa1, 0x5e, 0x0e, 0x86, 0x55, 0x19, 0xc1, 0x38, 0x98, 0x16, 0x31, 0x7c, 0x5a, 0x8a, 0xa1, 0xc9,
+	0xd5, 0x1e, 0xce, 0x8b, 0x21, 0x6a, 0xaa, 0x51, 0x2c, 0x90, 0xad, 0xbd, 0x1d, 0xb2, 0x67, 0x00,
+	0x45, 0x84, 0x2a, 0x52, 0xf4, 0x27, 0x60, 0xf2, 0x76, 0x8d, 0xef, 0x35, 0xaf, 0xc3, 0x79, 0x2b,
+	0x77, 0x8c, 0x03, 0x2a, 0x44, 0xe6, 0x95, 0x2c, 0x67, 0x09, 0xda, 0xf9, 0x1a, 0x98, 0xba, 0xcf,
+	0x00, 0xf6, 0xfc, 0x24, 0x8d, 0x26, 0xd3, 0xff, 0xdf, 0x9a, 0x0e, 0xb4, 0xf3, 0x35, 0x30, 0xc2,
+	0x04, 0x8c, 0xb3, 0x68, 0x28, 0x2e, 0x72, 0x9b, 0xf2, 0xb1, 0x93, 0x40, 0xf3, 0xc4, 0x1f, 0x85,
+	0x6e, 0x70, 0x8f, 0xea, 0x54, 0x59, 0x93, 0x73, 0x87, 0x8d, 0x37, 0x3a, 0xec, 0x2c, 0x42, 0x2b,
+	0x5b, 0x14, 0xb1, 0xd8, 0x86, 0xc6, 0x61, 0x34, 0x64, 0xbc, 0x82, 0xd9, 0xd0, 0x88, 0x03, 0x37,
+	0x3d, 0x8f, 0x26, 0x63, 0x99, 0x70, 0x72, 0x1a, 0xe7, 0xbc, 0xc0, 0x67, 0x61, 0xba, 0x7f, 0x2c,
+	0x3d, 0xca, 0x69, 0xe7, 0x3f, 0x1a, 0x80, 0x34, 0x82, 0x5b, 0x5d, 0x81, 0xda, 0x30, 0x1a, 0xbb,
+	0x7e, 0x98, 0x25, 0x52, 0x41, 0x91, 0x35, 0x68, 0xa4, 0x5e, 0xfc, 0x2a, 0x8e, 0x26, 0xa9, 0xcc,
+	0x5d, 0xf5, 0xd4, 0x8b, 0x8f, 0xa3, 0x49, 0x4a, 0x56, 0xa1, 0x7e, 0x9d, 0x88, 0x19, 0xd1, 0x35,
+	0xd7, 0xae, 0x13, 0x3e, 0xb1, 0x06, 0x8d, 0xeb, 0x44, 0xce, 0x18, 0x42, 0xe7, 0x3a, 0x11, 0x53,
+	0x33, 0xed, 0x86, 0xa9, 0xb4, 0x1b, 0x78, 0x1f, 0x42, 0x74, 0x49, 0x36, 0xd1, 0x82, 0x20, 0x9f,
+	0x42, 0xfd, 0xcc, 0xf5, 0x2e, 0xa3, 0xf3, 0x73, 0x79, 0xf3, 0x7f, 0xa0, 0x9e, 0xcf, 0x6d, 0x31,
+	0x45, 0x33, 0x19, 0xf2, 0x18, 0x16, 0x73, 0x8b, 0xaf, 0xc6, 0xee, 0x0d, 0xef, 0xb3, 0x4d, 0xda,
+	0xce, 0x99, 0x07, 0xee, 0x8d, 0x73, 0x05, 0x75, 0xa9, 0x48, 0x1e, 0x42, 0x73, 0xec, 0xde, 0xbc,
+	0x1a, 0xb2, 0xc0, 0x9d, 0xca, 0x96, 0xb5, 0x31, 0x76, 0x6f, 0x76, 0x91, 0x26, 0x3f, 0x04, 0x38,
+	0x73, 0x13, 0x26, 0x67, 0xe5, 0xb3, 0x01, 0x39, 0x62, 0x7a, 0x05, 0x6a, 0xe7, 0xae, 0x97, 0xca,
+	0x1b, 0xbc, 0x40, 0x25, 0x85, 0xfc, 0xdf, 0xf9, 0x69, 0x2a, 0x1f, 0x0e, 0x0b, 0x54, 0x52, 0xce,
+	0xb7, 0x1a, 0xb4, 0xb0, 0x77, 0x7c, 0xc1, 0xa6, 0x3c, 0x78, 0x77, 0xb7, 0xcb, 0xdf, 0xb9, 0x45,
+	0x2d, 0xda, 0x65, 0x5d, 0x7d, 0x2c, 0x64, 0xed, 0xb7, 0x31, 0xfb, 0x44, 0x31, 0xf3, 0x56, 0xdb,
+	0xf9, 0x76, 0x01, 0x00, 0x4d, 0x53, 0x86, 0x01, 0x2c, 0x4c, 0x69, 0xaa, 0xa9, 0x8f, 0x01, 0x86,
+	0x57, 0x71, 0xe0, 0x7b, 0x6e, 0xca, 0x86, 0xdc, 0xb9, 0x06, 0x55, 0x38, 0x38, 0x2f, 0x72, 0xc5,
+	0x41, 0xd1, 0xdb, 0x2b, 0x1c, 0xd2, 0x85, 0x56, 0x74, 0x7e, 0x9e, 0x0b, 0x18, 0x5c, 0x40, 0x65,
+	0x29, 0x12, 0x08, 0x16, 0xaf, 0x2d, 0x4d, 0xaa, 0xb2, 0xc8, 0x33, 0xa8, 0x8b, 0x6a, 0x2f, 0xce,
+	0x4b, 0xab, 0xff, 0xf8, 0x36, 0x3a, 0x62, 0x0b, 0xbd, 0x13, 0x21, 0x25, 0xca, 0x7b, 0xa6, 0x63,
+	0x7f, 0x01, 0x6d, 0x75, 0xe2, 0x5e, 0x45, 0xfb, 0x39, 0x2c, 0x16, 0x61, 0x14, 0xb5, 0xa0, 0x36,
+	0xe1, 0x8b, 0x59, 0xda, 0x6c, 0x35, 0x2b, 0x5c, 0xa1, 0x52, 0xca, 0xf9, 0x97, 0x3c, 0x08, 0xb8,
+	0xd5, 0xf7, 0x72, 0x10, 0xc6, 0x05, 0xec, 0xa5, 0x37, 0x57, 0x71, 0x10, 0xd0, 0xa7, 0x2c, 0x93,
+	0x64, 0x37, 0xb2, 0x60, 0xe0, 0x11, 0x0f, 0xdc, 0x94, 0x25, 0x29, 0xbf, 0x94, 0x0d, 0x2a, 0xa9,
+	0x0c, 0x1a, 0xb1, 0xb1, 0xef, 0x02, 0xcd, 0xdf, 0x25, 0x34, 0xd8, 0x53, 0xbd, 0x17, 0x68, 0x78,
+	0x51, 0x17, 0x0f, 0x7e, 0x3e, 0xae, 0x7c, 0xee, 0x4b, 0xb8, 0x6a, 0xc5, 0xbd, 0x79, 0x00, 0x8b,
+	0x85, 0xdb, 0x98, 0xa5, 0xff, 0xaa, 0x89, 0x8b, 0x84, 0x1d, 0xef, 0xf7, 0xbe, 0x8f, 0xfc, 0x83,
+	0x86, 0xa1, 0x7e, 0xd0, 0x98, 0xbd, 0xed, 0x4b, 0xd0, 0xce, 0x7d, 0x44, 0xa7, 0x37, 0xa1, 0x2d,
+	0x2a, 0xfe, 0x69, 0x14, 0xa3, 0xd7, 0x44, 0x69, 0x72, 0x32, 0x3c, 0x96, 0xc1, 0x0c, 0xfc, 0xb1,
+	0x9f, 0x15, 0x04, 0x41, 0x38, 0x53, 0x58, 0x52, 0x34, 0x31, 0xf2, 0x7d, 0x30, 0xd2, 0x28, 0xce,
+	0x5e, 0xe3, 0x1f, 0xcf, 0x76, 0x15, 0x99, 0x64, 0x0f, 0x07, 0x5c, 0xd6, 0xfe, 0x1c, 0xf4, 0xd3,
+	0x28, 0x56, 0x8a, 0xa6, 0x56, 0x2a, 0x9a, 0xcb, 0x60, 0x7a, 0xb2, 0xa7, 0xe7, 0x4b, 0x73, 0xc2,
+	0x79, 0x02, 0x8b, 0xc2, 0x60, 0x76, 0x66, 0xe6, 0x78, 0x8d, 0x46, 0xb2, 0xaf, 0x0f, 0x82, 0x70,
+	0xfe, 0xa8, 0xc1, 0x03, 0x55, 0x17, 0xfd, 0xfe, 0x32, 0x93, 0xd4, 0xe6, 0x3d, 0x1c, 0x72, 0x59,
+	0xfe, 0x78, 0x90, 0x99, 0x45, 0x28, 0xd9, 0x9b, 0x00, 0x05, 0xf3, 0x5e, 0x59, 0xa5, 0x53, 0x20,
+	0x98, 0xf2, 0xfe, 0xc2, 0xf9, 0x1a, 0x3a, 0x25, 0x0e, 0x7a, 0x67, 0x41, 0xdd, 0x8f, 0xb3, 0xd7,
+	0x18, 0xf6, 0x1d, 0x19, 0x89, 0x27, 0x0c, 0x5b, 0xba, 0x9d, 0x1c, 0x20, 0x9d, 0x16, 0x0c, 0xe7,
+	0x47, 0xd0, 0x3a, 0x9e, 0xb0, 0x84, 0x85, 0x1e, 0x93, 0x10, 0xf1, 0x1c, 0xa0, 0x15, 0x39, 0x00,
+	0xc1, 0x58, 0x2c, 0x64, 0x70, 0xb1, 0x67, 0x79, 0x6b, 0x28, 0xb0, 0xf8, 0x49, 0xe9, 0x50, 0xaa,
+	0xa2, 0x12, 0x19, 0x01, 0x85, 0x54, 0xb2, 0x9f, 0x40, 0x4b, 0x61, 0xab, 0x60, 0xe8, 0x15, 0x60,
+	0x34, 0x14, 0x30, 0xfa, 0xff, 0x35, 0xc0, 0x7c, 0x89, 0xcb, 0x90, 0xa7, 0x50, 0x97, 0x4d, 0x2f,
+	0x99, 0xf3, 0x96, 0xb1, 0xe7, 0x76, 0xc8, 0x64, 0x17, 0xa0, 0x68, 0x7d, 0xc9, 0xfc, 0x67, 0x8d,
+	0x7d, 0x57, 0xb7, 0x4c, 0xb6, 0xa0, 0x99, 0xbf, 0x64, 0xc8, 0xdc, 0x07, 0x8e, 0x6d, 0xcf, 0x99,
+	0x41, 0x13, 0x3b, 0x00, 0x7b, 0xc5, 0xf3, 0x67, 0xad, 0x52, 0x32, 0x79, 0x93, 0x91, 0x67, 0xd0,
+	0xa2, 0x2c, 0x64, 0xd7, 0x02, 0x54, 0xf2, 0x51, 0xe5, 0x27, 0x03, 0x7b, 0x75, 0xce, 0xf3, 0x1c,
+	0x91, 0x94, 0x3d, 0x35, 0x99, 0xf3, 0xdc, 0xb2, 0xad, 0x4a, 0x3e, 0x2a, 0x3f, 0x87, 0x1a, 0x7f,
+	0xd2, 0x24, 0xe4, 0xa3, 0xdb, 0x68, 0x73, 0xbe, 0x5d, 0xfd, 0x02, 0xe2, 0xca, 0xeb, 0xda, 0x67,
+	0x1a, 0xd9, 0x84, 0x9a, 0x68, 0x62, 0xcb, 0x06, 0xf2, 0x6e, 0xda, 0x5e, 0xad, 0x62, 0x4b, 0xbf,
+	0x65, 0x5f, 0x5e, 0xf6, 0xbb, 0x78, 0x10, 0xd8, 0x56, 0x25, 0x1f, 0x95, 0x7f, 0x09, 0x26, 0xef,
+	0x73, 0x49, 0xe9, 0x73, 0x60, 0xd6, 0x3f, 0xdb, 0x2b, 0x15, 0xdc, 0x38, 0x98, 0xf6, 0xff, 0x6d,
+	0x40, 0x93, 0x9f, 0x3f, 0x4c, 0x8f, 0xe4, 0x57, 0xd0, 0xc8, 0x0a, 0x3e, 0x59, 0xbd, 0x9d, 0x98,
+	0x65, 0x37, 0x67, 0xaf, 0x55, 0x4f, 0xa0, 0x13, 0x52, 0x9f, 0x77, 0x36, 0x33, 0xfa, 0xb2, 0x09,
+	0xb0, 0xd7, 0xaa, 0x27, 0x14, 0x7d, 0x4c, 0x2c, 0xb3, 0xfa, 0x32, 0xeb, 0xd9, 0x6b, 0xd5, 0x13,
+	0x12, 0x41, 0x99, 0xe6, 0xc9, 0x4c, 0xfd, 0x15, 0xf5, 0xc9, 0xb6, 0x2a, 0xf9, 0xf2, 0xf4, 0xe7,
+	0x1f, 0x3c, 0xc9, 0x8c, 0x58, 0xf6, 0xf5, 0xd5, 0xb6, 0xe7, 0xcc, 0x48, 0x13, 0x79, 0xca, 0x2f,
+	0x9b, 0x50, 0xab, 0x8d, 0x6d, 0xcf, 0x99, 0x91, 0x37, 0xb9, 0x48, 0xbe, 0xe5, 0x0b, 0x54, 0x4a,
+	0xfe, 0xf6, 0xc3, 0x79, 0x53, 0x68, 0xe5, 0x2b, 0x68, 0x29, 0x19, 0x95, 0x54, 0x2e, 0x28, 0x92,
+	0xaf, 0xfd, 0x68, 0xee, 0x5c, 0x16, 0x11, 0x99, 0xff, 0x6e, 0x45, 0xa4, 0x48, 0xb2, 0xf6, 0x5a,
+	0xf5, 0x44, 0x1c, 0x4c, 0xb7, 0x37, 0x7e, 0xfb, 0xe9, 0x9b, 0xff, 0x06, 0xe1, 0x8a, 0x4f, 0xf9,
+	0xef, 0x59, 0x8d, 0x3f, 0x05, 0x3f, 0xff, 0xdf, 0x00, 0x8b, 0xbd, 0x5e, 0x85, 0x5d, 0x19, 0x00,
+	0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// LogicClient is the client API for Logic service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LogicClient interface {
+	// Connect
+	Connect(ctx context.Context, in *ConnectReq, opts ...grpc.CallOption) (*ConnectReply, error)
+	// Disconnect
+	Disconnect(ctx context.Context, in *DisconnectReq, opts ...grpc.CallOption) (*DisconnectReply, error)
+	// Heartbeat
+	Heartbeat(ctx context.Context, in *HeartbeatReq, opts ...grpc.CallOption) (*HeartbeatReply, error)
+	// Heartbeats renew the heartbeats of many connections at once
+	Heartbeats(ctx context.Context, in *HeartbeatsReq, opts ...grpc.CallOption) (*HeartbeatReply, error)
+	// RenewOnline
+	RenewOnline(ctx context.Context, in *OnlineReq, opts ...grpc.CallOption) (*OnlineReply, error)
+	// Receive
+	Receive(ctx context.Context, in *ReceiveReq, opts ...grpc.CallOption) (*ReceiveReply, error)
+	// Events multiplex the connection events of a comet, the unary rpcs above are the fallback
+	Events(ctx context.Context, opts ...grpc.CallOption) (Logic_EventsClient, error)
+	// Signal route an ephemeral signal to the target comets
+	Signal(ctx context.Context, in *SignalReq, opts ...grpc.CallOption) (*SignalReply, error)
+	// History query the history messages
+	History(ctx context.Context, in *HistoryReq, opts ...grpc.CallOption) (*HistoryReply, error)
+	//ServerList
+	Nodes(ctx context.Context, in *NodesReq, opts ...grpc.CallOption) (*NodesReply, error)
+}
+
+type logicClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLogicClient(cc *grpc.ClientConn) LogicClient {
+	return &logicClient{cc}
+}
+
+func (c *logicClient) Connect(ctx context.Context, in *ConnectReq, opts ...grpc.CallOption) (*ConnectReply, error) {
+	out := new(ConnectReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Connect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logicClient) Disconnect(ctx context.Context, in *DisconnectReq, opts ...grpc.CallOption) (*DisconnectReply, error) {
+	out := new(DisconnectReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Disconnect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logicClient) Heartbeat(ctx context.Context, in *HeartbeatReq, opts ...grpc.CallOption) (*HeartbeatReply, error) {
+	out := new(HeartbeatReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Heartbeat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logicClient) Heartbeats(ctx context.Context, in *HeartbeatsReq, opts ...grpc.CallOption) (*HeartbeatReply, error) {
+	out := new(HeartbeatReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Heartbeats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logicClient) RenewOnline(ctx context.Context, in *OnlineReq, opts ...grpc.CallOption) (*OnlineReply, error) {
+	out := new(OnlineReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/RenewOnline", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logicClient) Receive(ctx context.Context, in *ReceiveReq, opts ...grpc.CallOption) (*ReceiveReply, error) {
+	out := new(ReceiveReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Receive", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logicClient) Events(ctx context.Context, opts ...grpc.CallOption) (Logic_EventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Logic_serviceDesc.Streams[0], "/goim.logic.Logic/Events", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logicEventsClient{stream}
+	return x, nil
+}
+
+type Logic_EventsClient interface {
+	Send(*ConnEvent) error
+	Recv() (*ConnEventReply, error)
+	grpc.ClientStream
+}
+
+type logicEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *logicEventsClient) Send(m *ConnEvent) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *logicEventsClient) Recv() (*ConnEventReply, error) {
+	m := new(ConnEventReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *logicClient) Signal(ctx context.Context, in *SignalReq, opts ...grpc.CallOption) (*SignalReply, error) {
+	out := new(SignalReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Signal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logicClient) History(ctx context.Context, in *HistoryReq, opts ...grpc.CallOption) (*HistoryReply, error) {
+	out := new(HistoryReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/History", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logicClient) Nodes(ctx context.Context, in *NodesReq, opts ...grpc.CallOption) (*NodesReply, error) {
+	out := new(NodesReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Nodes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LogicServer is the server API for Logic service.
+type LogicServer interface {
+	// Connect
+	Connect(context.Context, *ConnectReq) (*ConnectReply, error)
+	// Disconnect
+	Disconnect(context.Context, *DisconnectReq) (*DisconnectReply, error)
+	// Heartbeat
+	Heartbeat(context.Context, *HeartbeatReq) (*HeartbeatReply, error)
+	// Heartbeats renew the heartbeats of many connections at once
+	Heartbeats(context.Context, *HeartbeatsReq) (*HeartbeatReply, error)
+	// RenewOnline
+	RenewOnline(context.Context, *OnlineReq) (*OnlineReply, error)
+	// Receive
+	Receive(context.Context, *ReceiveReq) (*ReceiveReply, error)
+	// Events multiplex the connection events of a comet, the unary rpcs above are the fallback
+	Events(Logic_EventsServer) error
+	// Signal route an ephemeral signal to the target comets
+	Signal(context.Context, *SignalReq) (*SignalReply, error)
+	// History query the history messages
+	History(context.Context, *HistoryReq) (*HistoryReply, error)
+	//ServerList
+	Nodes(context.Context, *NodesReq) (*NodesReply, error)
+}
+
+// UnimplementedLogicServer can be embedded to have forward compatible implementations.
+type UnimplementedLogicServer struct {
+}
+
+func (*UnimplementedLogicServer) Connect(ctx context.Context, req *ConnectReq) (*ConnectReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Connect not implemented")
+}
+func (*UnimplementedLogicServer) Disconnect(ctx context.Context, req *DisconnectReq) (*DisconnectReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Disconnect not implemented")
+}
+func (*UnimplementedLogicServer) Heartbeat(ctx context.Context, req *HeartbeatReq) (*HeartbeatReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (*UnimplementedLogicServer) Heartbeats(ctx context.Context, req *HeartbeatsReq) (*HeartbeatReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeats not implemented")
+}
+func (*UnimplementedLogicServer) RenewOnline(ctx context.Context, req *OnlineReq) (*OnlineReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method RenewOnline not implemented")
+}
+func (*UnimplementedLogicServer) Receive(ctx context.Context, req *ReceiveReq) (*ReceiveReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Receive not implemented")
+}
+func (*UnimplementedLogicServer) Events(srv Logic_EventsServer) error {
+	return status.Error(codes.Unimplemented, "method Events not implemented")
+}
+func (*UnimplementedLogicServer) Signal(ctx context.Context, req *SignalReq) (*SignalReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Signal not implemented")
+}
+func (*UnimplementedLogicServer) History(ctx context.Context, req *HistoryReq) (*HistoryReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method History not implemented")
+}
+func (*UnimplementedLogicServer) Nodes(ctx context.Context, req *NodesReq) (*NodesReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Nodes not implemented")
+}
+
+func RegisterLogicServer(s *grpc.Server, srv LogicServer) {
+	s.RegisterService(&_Logic_serviceDesc, srv)
+}
+
+func _Logic_Connect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).Connect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/Connect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).Connect(ctx, req.(*ConnectReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Logic_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).Disconnect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/Disconnect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).Disconnect(ctx, req.(*DisconnectReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Logic_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/Heartbeat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).Heartbeat(ctx, req.(*HeartbeatReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Logic_Heartbeats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).Heartbeats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/Heartbeats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).Heartbeats(ctx, req.(*HeartbeatsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Logic_RenewOnline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).RenewOnline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/RenewOnline",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).RenewOnline(ctx, req.(*OnlineReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Logic_Receive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReceiveReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).Receive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/Receive",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).Receive(ctx, req.(*ReceiveReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Logic_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LogicServer).Events(&logicEventsServer{stream})
+}
+
+type Logic_EventsServer interface {
+	Send(*ConnEventReply) error
+	Recv() (*ConnEvent, error)
+	grpc.ServerStream
+}
+
+type logicEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *logicEventsServer) Send(m *ConnEventReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *logicEventsServer) Recv() (*ConnEvent, error) {
+	m := new(ConnEvent)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Logic_Signal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).Signal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/Signal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).Signal(ctx, req.(*SignalReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Logic_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/History",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).History(ctx, req.(*HistoryReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Logic_Nodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicServer).Nodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.Logic/Nodes",
 	}
-	return 0
-}
-
-func (m *Backoff) GetJitter() float32 {
-	if m != nil {
-		return m.Jitter
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicServer).Nodes(ctx, req.(*NodesReq))
 	}
-	return 0
+	return interceptor(ctx, in, info, handler)
 }
 
-func init() {
-	proto.RegisterEnum("goim.logic.PushMsg_Type", PushMsg_Type_name, PushMsg_Type_value)
-	proto.RegisterType((*PushMsg)(nil), "goim.logic.PushMsg")
-	proto.RegisterType((*ConnectReq)(nil), "goim.logic.ConnectReq")
-	proto.RegisterType((*ConnectReply)(nil), "goim.logic.ConnectReply")
-	proto.RegisterType((*DisconnectReq)(nil), "goim.logic.DisconnectReq")
-	proto.RegisterType((*DisconnectReply)(nil), "goim.logic.DisconnectReply")
-	proto.RegisterType((*HeartbeatReq)(nil), "goim.logic.HeartbeatReq")
-	proto.RegisterType((*HeartbeatReply)(nil), "goim.logic.HeartbeatReply")
-	proto.RegisterType((*OnlineReq)(nil), "goim.logic.OnlineReq")
-	proto.RegisterMapType((map[string]int32)(nil), "goim.logic.OnlineReq.RoomCountEntry")
-	proto.RegisterType((*OnlineReply)(nil), "goim.logic.OnlineReply")
-	proto.RegisterMapType((map[string]int32)(nil), "goim.logic.OnlineReply.AllRoomCountEntry")
-	proto.RegisterType((*ReceiveReq)(nil), "goim.logic.ReceiveReq")
-	proto.RegisterType((*ReceiveReply)(nil), "goim.logic.ReceiveReply")
-	proto.RegisterType((*NodesReq)(nil), "goim.logic.NodesReq")
-	proto.RegisterType((*NodesReply)(nil), "goim.logic.NodesReply")
-	proto.RegisterType((*Backoff)(nil), "goim.logic.Backoff")
+var _Logic_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "goim.logic.Logic",
+	HandlerType: (*LogicServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Connect",
+			Handler:    _Logic_Connect_Handler,
+		},
+		{
+			MethodName: "Disconnect",
+			Handler:    _Logic_Disconnect_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Logic_Heartbeat_Handler,
+		},
+		{
+			MethodName: "Heartbeats",
+			Handler:    _Logic_Heartbeats_Handler,
+		},
+		{
+			MethodName: "RenewOnline",
+			Handler:    _Logic_RenewOnline_Handler,
+		},
+		{
+			MethodName: "Receive",
+			Handler:    _Logic_Receive_Handler,
+		},
+		{
+			MethodName: "Signal",
+			Handler:    _Logic_Signal_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _Logic_History_Handler,
+		},
+		{
+			MethodName: "Nodes",
+			Handler:    _Logic_Nodes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Events",
+			Handler:       _Logic_Events_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "logic/logic.proto",
 }
 
-func init() { proto.RegisterFile("logic/logic.proto", fileDescriptor_2dfb3aef05fe3328) }
-
-var fileDescriptor_2dfb3aef05fe3328 = []byte{
-	// 893 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0x4f, 0x6f, 0xe3, 0x44,
-	0x14, 0xc7, 0x71, 0x9c, 0xc4, 0x2f, 0x69, 0xc9, 0x0e, 0x25, 0xeb, 0x7a, 0x41, 0x8a, 0xbc, 0x1c,
-	0x52, 0x60, 0x13, 0x29, 0x68, 0x25, 0xc4, 0x82, 0x50, 0xd3, 0x20, 0xed, 0x2e, 0x84, 0x46, 0xb3,
-	0xe5, 0xc2, 0xa5, 0x9a, 0x38, 0xd3, 0xd4, 0xc4, 0xf6, 0x18, 0x7b, 0xd2, 0xd4, 0x37, 0xc4, 0xf7,
-	0xe0, 0xc8, 0x77, 0xe3, 0x3b, 0x70, 0x41, 0xf3, 0x27, 0xb6, 0xa3, 0x4d, 0x57, 0x20, 0x2e, 0xd6,
-	0xfb, 0x37, 0xbf, 0xf9, 0xbd, 0xf7, 0xe6, 0x3d, 0xc3, 0xa3, 0x90, 0xad, 0x02, 0x7f, 0x24, 0xbf,
-	0xc3, 0x24, 0x65, 0x9c, 0x21, 0x58, 0xb1, 0x20, 0x1a, 0x4a, 0x8b, 0xfb, 0x7c, 0x15, 0xf0, 0xdb,
-	0xcd, 0x62, 0xe8, 0xb3, 0x68, 0x74, 0x45, 0xd3, 0x34, 0x7f, 0x36, 0x23, 0x6c, 0x24, 0x02, 0x46,
-	0x24, 0x09, 0x46, 0xf2, 0x80, 0xcf, 0xc2, 0x42, 0x50, 0x10, 0xde, 0x5f, 0x06, 0x34, 0xe7, 0x9b,
-	0xec, 0x76, 0x96, 0xad, 0xd0, 0xe7, 0x50, 0xe7, 0x79, 0x42, 0x1d, 0xa3, 0x6f, 0x0c, 0x8e, 0xc7,
-	0xce, 0xb0, 0x44, 0x1f, 0xea, 0x90, 0xe1, 0x55, 0x9e, 0x50, 0x2c, 0xa3, 0xd0, 0x47, 0x60, 0xb3,
-	0x84, 0xa6, 0x84, 0x07, 0x2c, 0x76, 0x6a, 0x7d, 0x63, 0x60, 0xe1, 0xd2, 0x80, 0x4e, 0xc0, 0xca,
-	0x12, 0x4a, 0x97, 0x8e, 0x29, 0x3d, 0x4a, 0x41, 0x3d, 0x68, 0x64, 0x34, 0xbd, 0xa3, 0xa9, 0x53,
-	0xef, 0x1b, 0x03, 0x1b, 0x6b, 0x0d, 0x21, 0xa8, 0xa7, 0x8c, 0x45, 0x8e, 0x25, 0xad, 0x52, 0x16,
-	0xb6, 0x35, 0xcd, 0x33, 0xa7, 0xd1, 0x37, 0x85, 0x4d, 0xc8, 0xa8, 0x0b, 0x66, 0x94, 0xad, 0x9c,
-	0x66, 0xdf, 0x18, 0x74, 0xb0, 0x10, 0xbd, 0x33, 0xa8, 0x0b, 0x4e, 0xa8, 0x05, 0xf5, 0xf9, 0x4f,
-	0x6f, 0x5e, 0x76, 0xdf, 0x13, 0x12, 0xbe, 0xbc, 0x9c, 0x75, 0x0d, 0x74, 0x04, 0xf6, 0x04, 0x5f,
-	0x9e, 0x4f, 0x2f, 0xce, 0xdf, 0x5c, 0x75, 0x6b, 0x1e, 0x06, 0xb8, 0x60, 0x71, 0x4c, 0x7d, 0x8e,
-	0xe9, 0xaf, 0x15, 0x2a, 0xc6, 0x1e, 0x95, 0x1e, 0x34, 0x7c, 0xc6, 0xd6, 0x01, 0x95, 0x39, 0xd9,
-	0x58, 0x6b, 0x22, 0x21, 0xce, 0xd6, 0x34, 0x96, 0x09, 0x75, 0xb0, 0x52, 0xbc, 0xdf, 0x0d, 0xe8,
-	0x14, 0xa0, 0x49, 0x98, 0x4b, 0x86, 0xc1, 0x52, 0x62, 0x9a, 0x58, 0x88, 0xc2, 0xb2, 0xa6, 0xb9,
-	0x46, 0x13, 0xa2, 0xb8, 0x42, 0x64, 0xf8, 0x6a, 0x2a, 0xb1, 0x6c, 0xac, 0x35, 0xe4, 0x40, 0x93,
-	0xf8, 0x3e, 0x4d, 0x78, 0xe6, 0xd4, 0xfb, 0xe6, 0xc0, 0xc2, 0x3b, 0x55, 0xd4, 0xfa, 0x96, 0x92,
-	0x94, 0x2f, 0x28, 0xe1, 0xb2, 0x48, 0x26, 0x2e, 0x0d, 0xde, 0xf7, 0x70, 0x34, 0x0d, 0x32, 0xbf,
-	0xcc, 0xed, 0x5f, 0x92, 0xd0, 0xf9, 0x9b, 0xd5, 0xfc, 0xbd, 0xa7, 0xf0, 0x7e, 0x15, 0x4c, 0xe7,
-	0x74, 0x4b, 0x32, 0x09, 0xd7, 0xc2, 0x42, 0xf4, 0x5e, 0x43, 0xe7, 0xe5, 0xee, 0xfa, 0xff, 0x7b,
-	0x61, 0x17, 0x8e, 0x2b, 0x58, 0x49, 0x98, 0x7b, 0x7f, 0x1a, 0x60, 0x5f, 0xc6, 0x61, 0x10, 0xd3,
-	0x77, 0x35, 0x6a, 0x02, 0xb6, 0xa8, 0xdb, 0x05, 0xdb, 0xc4, 0xdc, 0xa9, 0xf5, 0xcd, 0x41, 0x7b,
-	0xfc, 0x49, 0xf5, 0xc9, 0x16, 0x08, 0x43, 0xbc, 0x0b, 0xfb, 0x2e, 0xe6, 0x69, 0x8e, 0xcb, 0x63,
-	0xee, 0xd7, 0x70, 0xbc, 0xef, 0xdc, 0xf1, 0x36, 0x4a, 0xde, 0x27, 0x60, 0xdd, 0x91, 0x70, 0x43,
-	0xf5, 0x1b, 0x57, 0xca, 0x57, 0xb5, 0x2f, 0x0d, 0xef, 0x0f, 0x03, 0xda, 0xbb, 0x5b, 0x44, 0x9d,
-	0x66, 0xd0, 0x21, 0x61, 0x58, 0x00, 0x3a, 0x86, 0x24, 0x75, 0x76, 0x88, 0x54, 0x12, 0xe6, 0xc3,
-	0xf3, 0x4a, 0xac, 0x62, 0xb6, 0x77, 0xdc, 0xfd, 0x16, 0x1e, 0xbd, 0x15, 0xf2, 0x9f, 0xf8, 0xbd,
-	0x06, 0xc0, 0xd4, 0xa7, 0xc1, 0x1d, 0x3d, 0xdc, 0xa3, 0x4f, 0xc1, 0x92, 0x4b, 0x40, 0x9e, 0x6c,
-	0x8f, 0x4f, 0x14, 0xd1, 0x62, 0x41, 0xcc, 0x85, 0x80, 0x55, 0x88, 0x77, 0x0c, 0x9d, 0x02, 0x4b,
-	0xf4, 0x68, 0x02, 0xad, 0x1f, 0xd9, 0x92, 0x66, 0x02, 0xd9, 0x85, 0x56, 0x12, 0x12, 0x7e, 0xc3,
-	0xd2, 0x48, 0x13, 0x2b, 0x74, 0xe1, 0xf3, 0xc3, 0x80, 0xc6, 0xfc, 0xd5, 0x5c, 0x3f, 0x86, 0x42,
-	0xf7, 0xfe, 0x36, 0x00, 0x34, 0x88, 0x28, 0x5f, 0x0f, 0x1a, 0x4b, 0x16, 0x91, 0x20, 0xde, 0x35,
-	0x5a, 0x69, 0xe8, 0x14, 0x5a, 0xdc, 0x4f, 0xae, 0x13, 0x96, 0x72, 0x9d, 0x63, 0x93, 0xfb, 0xc9,
-	0x9c, 0xa5, 0x1c, 0x3d, 0x86, 0xe6, 0x36, 0x53, 0x1e, 0xb5, 0x67, 0x1a, 0xdb, 0x4c, 0x3a, 0x4e,
-	0xa1, 0xb5, 0xcd, 0xb4, 0xa7, 0xae, 0xce, 0x6c, 0x33, 0xe5, 0x7a, 0x6b, 0x96, 0xac, 0xca, 0x2c,
-	0x89, 0x6a, 0xc6, 0x82, 0x92, 0x5e, 0x3b, 0x4a, 0x41, 0xcf, 0xa0, 0xb9, 0x20, 0xfe, 0x9a, 0xdd,
-	0xdc, 0xc8, 0xdd, 0xd3, 0x1e, 0x7f, 0x50, 0x6d, 0xea, 0x44, 0xb9, 0xf0, 0x2e, 0x06, 0x3d, 0x85,
-	0xa3, 0x02, 0xf1, 0x3a, 0x22, 0xf7, 0x4e, 0x4b, 0x5e, 0xd3, 0x29, 0x8c, 0x33, 0x72, 0xef, 0x6d,
-	0xa0, 0xa9, 0x0f, 0xa2, 0x27, 0x60, 0x47, 0xe4, 0xfe, 0x7a, 0x49, 0x43, 0xa2, 0x5a, 0x6b, 0xe1,
-	0x56, 0x44, 0xee, 0xa7, 0x42, 0x47, 0x1f, 0x03, 0x2c, 0x48, 0x46, 0xb5, 0x57, 0x2f, 0x5a, 0x61,
-	0x51, 0xee, 0x1e, 0x34, 0x6e, 0x88, 0xcf, 0x99, 0x1a, 0xab, 0x1a, 0xd6, 0x9a, 0xb0, 0xff, 0x12,
-	0x70, 0xae, 0x57, 0x6d, 0x0d, 0x6b, 0x6d, 0xfc, 0x9b, 0x09, 0xd6, 0x0f, 0x82, 0x36, 0x7a, 0x01,
-	0x4d, 0xbd, 0xba, 0x50, 0xaf, 0x9a, 0x4e, 0xb9, 0x24, 0x5d, 0xe7, 0xa0, 0x5d, 0x34, 0x6b, 0x0a,
-	0x50, 0xae, 0x09, 0x74, 0x5a, 0x8d, 0xdb, 0xdb, 0x45, 0xee, 0x93, 0x87, 0x5c, 0x02, 0xe5, 0x1c,
-	0xec, 0x62, 0xf6, 0xd1, 0xde, 0x65, 0xd5, 0xf5, 0xe2, 0xba, 0x0f, 0x78, 0x04, 0xc4, 0x37, 0xd0,
-	0xc6, 0x34, 0xa6, 0x5b, 0x35, 0x59, 0xe8, 0xc3, 0x83, 0x2b, 0xc0, 0x7d, 0xfc, 0xc0, 0x10, 0x8a,
-	0x22, 0xe8, 0x77, 0xbd, 0x5f, 0x84, 0x72, 0x70, 0xf6, 0x8b, 0x50, 0x1d, 0x02, 0xf4, 0x1c, 0x2c,
-	0xf9, 0x7e, 0xd1, 0x49, 0x35, 0x64, 0x37, 0x17, 0x6e, 0xef, 0x80, 0x35, 0x09, 0xf3, 0xc9, 0x67,
-	0x3f, 0x9f, 0xbd, 0xfb, 0x67, 0x2d, 0x4f, 0xbc, 0x90, 0xdf, 0x45, 0x43, 0xce, 0xdf, 0x17, 0xff,
-	0x04, 0x00, 0x00, 0xff, 0xff, 0x6f, 0x34, 0x54, 0x90, 0xff, 0x07, 0x00, 0x00,
+// LogicPushClient is the client API for LogicPush service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LogicPushClient interface {
+	// PushKeys push a message by keys
+	PushKeys(ctx context.Context, in *PushKeysReq, opts ...grpc.CallOption) (*PushKeysReply, error)
+	// PushMids push a message by mids
+	PushMids(ctx context.Context, in *PushMidsReq, opts ...grpc.CallOption) (*PushMidsReply, error)
+	// PushRoom push a message to a room
+	PushRoom(ctx context.Context, in *PushRoomReq, opts ...grpc.CallOption) (*PushRoomReply, error)
+	// PushAll push a message to all
+	PushAll(ctx context.Context, in *PushAllReq, opts ...grpc.CallOption) (*PushAllReply, error)
+	// PushBatch push many messages in one request
+	PushBatch(ctx context.Context, in *PushBatchReq, opts ...grpc.CallOption) (*PushBatchReply, error)
+	// OnlineTop get the top online rooms
+	OnlineTop(ctx context.Context, in *OnlineTopReq, opts ...grpc.CallOption) (*OnlineTopReply, error)
+	// OnlineRoom get the online of rooms
+	OnlineRoom(ctx context.Context, in *OnlineRoomReq, opts ...grpc.CallOption) (*OnlineRoomReply, error)
+	// OnlineTotal get the total online
+	OnlineTotal(ctx context.Context, in *OnlineTotalReq, opts ...grpc.CallOption) (*OnlineTotalReply, error)
+	// Presence get the online state of mids
+	Presence(ctx context.Context, in *PresenceReq, opts ...grpc.CallOption) (*PresenceReply, error)
+}
+
+type logicPushClient struct {
+	cc *grpc.ClientConn
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func NewLogicPushClient(cc *grpc.ClientConn) LogicPushClient {
+	return &logicPushClient{cc}
+}
 
-// LogicClient is the client API for Logic service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type LogicClient interface {
-	// Connect
-	Connect(ctx context.Context, in *ConnectReq, opts ...grpc.CallOption) (*ConnectReply, error)
-	// Disconnect
-	Disconnect(ctx context.Context, in *DisconnectReq, opts ...grpc.CallOption) (*DisconnectReply, error)
-	// Heartbeat
-	Heartbeat(ctx context.Context, in *HeartbeatReq, opts ...grpc.CallOption) (*HeartbeatReply, error)
-	// RenewOnline
-	RenewOnline(ctx context.Context, in *OnlineReq, opts ...grpc.CallOption) (*OnlineReply, error)
-	// Receive
-	Receive(ctx context.Context, in *ReceiveReq, opts ...grpc.CallOption) (*ReceiveReply, error)
-	//ServerList
-	Nodes(ctx context.Context, in *NodesReq, opts ...grpc.CallOption) (*NodesReply, error)
+func (c *logicPushClient) PushKeys(ctx context.Context, in *PushKeysReq, opts ...grpc.CallOption) (*PushKeysReply, error) {
+	out := new(PushKeysReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/PushKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type logicClient struct {
-	cc *grpc.ClientConn
+func (c *logicPushClient) PushMids(ctx context.Context, in *PushMidsReq, opts ...grpc.CallOption) (*PushMidsReply, error) {
+	out := new(PushMidsReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/PushMids", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func NewLogicClient(cc *grpc.ClientConn) LogicClient {
-	return &logicClient{cc}
+func (c *logicPushClient) PushRoom(ctx context.Context, in *PushRoomReq, opts ...grpc.CallOption) (*PushRoomReply, error) {
+	out := new(PushRoomReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/PushRoom", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *logicClient) Connect(ctx context.Context, in *ConnectReq, opts ...grpc.CallOption) (*ConnectReply, error) {
-	out := new(ConnectReply)
-	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Connect", in, out, opts...)
+func (c *logicPushClient) PushAll(ctx context.Context, in *PushAllReq, opts ...grpc.CallOption) (*PushAllReply, error) {
+	out := new(PushAllReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/PushAll", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *logicClient) Disconnect(ctx context.Context, in *DisconnectReq, opts ...grpc.CallOption) (*DisconnectReply, error) {
-	out := new(DisconnectReply)
-	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Disconnect", in, out, opts...)
+func (c *logicPushClient) PushBatch(ctx context.Context, in *PushBatchReq, opts ...grpc.CallOption) (*PushBatchReply, error) {
+	out := new(PushBatchReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/PushBatch", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *logicClient) Heartbeat(ctx context.Context, in *HeartbeatReq, opts ...grpc.CallOption) (*HeartbeatReply, error) {
-	out := new(HeartbeatReply)
-	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Heartbeat", in, out, opts...)
+func (c *logicPushClient) OnlineTop(ctx context.Context, in *OnlineTopReq, opts ...grpc.CallOption) (*OnlineTopReply, error) {
+	out := new(OnlineTopReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/OnlineTop", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *logicClient) RenewOnline(ctx context.Context, in *OnlineReq, opts ...grpc.CallOption) (*OnlineReply, error) {
-	out := new(OnlineReply)
-	err := c.cc.Invoke(ctx, "/goim.logic.Logic/RenewOnline", in, out, opts...)
+func (c *logicPushClient) OnlineRoom(ctx context.Context, in *OnlineRoomReq, opts ...grpc.CallOption) (*OnlineRoomReply, error) {
+	out := new(OnlineRoomReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/OnlineRoom", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *logicClient) Receive(ctx context.Context, in *ReceiveReq, opts ...grpc.CallOption) (*ReceiveReply, error) {
-	out := new(ReceiveReply)
-	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Receive", in, out, opts...)
+func (c *logicPushClient) OnlineTotal(ctx context.Context, in *OnlineTotalReq, opts ...grpc.CallOption) (*OnlineTotalReply, error) {
+	out := new(OnlineTotalReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/OnlineTotal", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *logicClient) Nodes(ctx context.Context, in *NodesReq, opts ...grpc.CallOption) (*NodesReply, error) {
-	out := new(NodesReply)
-	err := c.cc.Invoke(ctx, "/goim.logic.Logic/Nodes", in, out, opts...)
+func (c *logicPushClient) Presence(ctx context.Context, in *PresenceReq, opts ...grpc.CallOption) (*PresenceReply, error) {
+	out := new(PresenceReply)
+	err := c.cc.Invoke(ctx, "/goim.logic.LogicPush/Presence", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// LogicServer is the server API for Logic service.
-type LogicServer interface {
-	// Connect
-	Connect(context.Context, *ConnectReq) (*ConnectReply, error)
-	// Disconnect
-	Disconnect(context.Context, *DisconnectReq) (*DisconnectReply, error)
-	// Heartbeat
-	Heartbeat(context.Context, *HeartbeatReq) (*HeartbeatReply, error)
-	// RenewOnline
-	RenewOnline(context.Context, *OnlineReq) (*OnlineReply, error)
-	// Receive
-	Receive(context.Context, *ReceiveReq) (*ReceiveReply, error)
-	//ServerList
-	Nodes(context.Context, *NodesReq) (*NodesReply, error)
+// LogicPushServer is the server API for LogicPush service.
+type LogicPushServer interface {
+	// PushKeys push a message by keys
+	PushKeys(context.Context, *PushKeysReq) (*PushKeysReply, error)
+	// PushMids push a message by mids
+	PushMids(context.Context, *PushMidsReq) (*PushMidsReply, error)
+	// PushRoom push a message to a room
+	PushRoom(context.Context, *PushRoomReq) (*PushRoomReply, error)
+	// PushAll push a message to all
+	PushAll(context.Context, *PushAllReq) (*PushAllReply, error)
+	// PushBatch push many messages in one request
+	PushBatch(context.Context, *PushBatchReq) (*PushBatchReply, error)
+	// OnlineTop get the top online rooms
+	OnlineTop(context.Context, *OnlineTopReq) (*OnlineTopReply, error)
+	// OnlineRoom get the online of rooms
+	OnlineRoom(context.Context, *OnlineRoomReq) (*OnlineRoomReply, error)
+	// OnlineTotal get the total online
+	OnlineTotal(context.Context, *OnlineTotalReq) (*OnlineTotalReply, error)
+	// Presence get the online state of mids
+	Presence(context.Context, *PresenceReq) (*PresenceReply, error)
 }
 
-// UnimplementedLogicServer can be embedded to have forward compatible implementations.
-type UnimplementedLogicServer struct {
+// UnimplementedLogicPushServer can be embedded to have forward compatible implementations.
+type UnimplementedLogicPushServer struct {
 }
 
-func (*UnimplementedLogicServer) Connect(ctx context.Context, req *ConnectReq) (*ConnectReply, error) {
-	return nil, status.Error(codes.Unimplemented, "method Connect not implemented")
+func (*UnimplementedLogicPushServer) PushKeys(ctx context.Context, req *PushKeysReq) (*PushKeysReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method PushKeys not implemented")
 }
-func (*UnimplementedLogicServer) Disconnect(ctx context.Context, req *DisconnectReq) (*DisconnectReply, error) {
-	return nil, status.Error(codes.Unimplemented, "method Disconnect not implemented")
+func (*UnimplementedLogicPushServer) PushMids(ctx context.Context, req *PushMidsReq) (*PushMidsReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method PushMids not implemented")
 }
-func (*UnimplementedLogicServer) Heartbeat(ctx context.Context, req *HeartbeatReq) (*HeartbeatReply, error) {
-	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+func (*UnimplementedLogicPushServer) PushRoom(ctx context.Context, req *PushRoomReq) (*PushRoomReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method PushRoom not implemented")
 }
-func (*UnimplementedLogicServer) RenewOnline(ctx context.Context, req *OnlineReq) (*OnlineReply, error) {
-	return nil, status.Error(codes.Unimplemented, "method RenewOnline not implemented")
+func (*UnimplementedLogicPushServer) PushAll(ctx context.Context, req *PushAllReq) (*PushAllReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method PushAll not implemented")
 }
-func (*UnimplementedLogicServer) Receive(ctx context.Context, req *ReceiveReq) (*ReceiveReply, error) {
-	return nil, status.Error(codes.Unimplemented, "method Receive not implemented")
+func (*UnimplementedLogicPushServer) PushBatch(ctx context.Context, req *PushBatchReq) (*PushBatchReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method PushBatch not implemented")
 }
-func (*UnimplementedLogicServer) Nodes(ctx context.Context, req *NodesReq) (*NodesReply, error) {
-	return nil, status.Error(codes.Unimplemented, "method Nodes not implemented")
+func (*UnimplementedLogicPushServer) OnlineTop(ctx context.Context, req *OnlineTopReq) (*OnlineTopReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method OnlineTop not implemented")
+}
+func (*UnimplementedLogicPushServer) OnlineRoom(ctx context.Context, req *OnlineRoomReq) (*OnlineRoomReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method OnlineRoom not implemented")
+}
+func (*UnimplementedLogicPushServer) OnlineTotal(ctx context.Context, req *OnlineTotalReq) (*OnlineTotalReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method OnlineTotal not implemented")
+}
+func (*UnimplementedLogicPushServer) Presence(ctx context.Context, req *PresenceReq) (*PresenceReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Presence not implemented")
 }
 
-func RegisterLogicServer(s *grpc.Server, srv LogicServer) {
-	s.RegisterService(&_Logic_serviceDesc, srv)
+func RegisterLogicPushServer(s *grpc.Server, srv LogicPushServer) {
+	s.RegisterService(&_LogicPush_serviceDesc, srv)
 }
 
-func _Logic_Connect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ConnectReq)
+func _LogicPush_PushKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushKeysReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(LogicServer).Connect(ctx, in)
+		return srv.(LogicPushServer).PushKeys(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/goim.logic.Logic/Connect",
+		FullMethod: "/goim.logic.LogicPush/PushKeys",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LogicServer).Connect(ctx, req.(*ConnectReq))
+		return srv.(LogicPushServer).PushKeys(ctx, req.(*PushKeysReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Logic_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DisconnectReq)
+func _LogicPush_PushMids_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushMidsReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(LogicServer).Disconnect(ctx, in)
+		return srv.(LogicPushServer).PushMids(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/goim.logic.Logic/Disconnect",
+		FullMethod: "/goim.logic.LogicPush/PushMids",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LogicServer).Disconnect(ctx, req.(*DisconnectReq))
+		return srv.(LogicPushServer).PushMids(ctx, req.(*PushMidsReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Logic_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HeartbeatReq)
+func _LogicPush_PushRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushRoomReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(LogicServer).Heartbeat(ctx, in)
+		return srv.(LogicPushServer).PushRoom(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/goim.logic.Logic/Heartbeat",
+		FullMethod: "/goim.logic.LogicPush/PushRoom",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LogicServer).Heartbeat(ctx, req.(*HeartbeatReq))
+		return srv.(LogicPushServer).PushRoom(ctx, req.(*PushRoomReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Logic_RenewOnline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(OnlineReq)
+func _LogicPush_PushAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushAllReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(LogicServer).RenewOnline(ctx, in)
+		return srv.(LogicPushServer).PushAll(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/goim.logic.Logic/RenewOnline",
+		FullMethod: "/goim.logic.LogicPush/PushAll",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LogicServer).RenewOnline(ctx, req.(*OnlineReq))
+		return srv.(LogicPushServer).PushAll(ctx, req.(*PushAllReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Logic_Receive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReceiveReq)
+func _LogicPush_PushBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushBatchReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(LogicServer).Receive(ctx, in)
+		return srv.(LogicPushServer).PushBatch(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/goim.logic.Logic/Receive",
+		FullMethod: "/goim.logic.LogicPush/PushBatch",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LogicServer).Receive(ctx, req.(*ReceiveReq))
+		return srv.(LogicPushServer).PushBatch(ctx, req.(*PushBatchReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Logic_Nodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(NodesReq)
+func _LogicPush_OnlineTop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineTopReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(LogicServer).Nodes(ctx, in)
+		return srv.(LogicPushServer).OnlineTop(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/goim.logic.Logic/Nodes",
+		FullMethod: "/goim.logic.LogicPush/OnlineTop",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LogicServer).Nodes(ctx, req.(*NodesReq))
+		return srv.(LogicPushServer).OnlineTop(ctx, req.(*OnlineTopReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Logic_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "goim.logic.Logic",
-	HandlerType: (*LogicServer)(nil),
+func _LogicPush_OnlineRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineRoomReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicPushServer).OnlineRoom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.LogicPush/OnlineRoom",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicPushServer).OnlineRoom(ctx, req.(*OnlineRoomReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogicPush_OnlineTotal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineTotalReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicPushServer).OnlineTotal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.LogicPush/OnlineTotal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicPushServer).OnlineTotal(ctx, req.(*OnlineTotalReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogicPush_Presence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PresenceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogicPushServer).Presence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goim.logic.LogicPush/Presence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogicPushServer).Presence(ctx, req.(*PresenceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _LogicPush_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "goim.logic.LogicPush",
+	HandlerType: (*LogicPushServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Connect",
-			Handler:    _Logic_Connect_Handler,
+			MethodName: "PushKeys",
+			Handler:    _LogicPush_PushKeys_Handler,
 		},
 		{
-			MethodName: "Disconnect",
-			Handler:    _Logic_Disconnect_Handler,
+			MethodName: "PushMids",
+			Handler:    _LogicPush_PushMids_Handler,
 		},
 		{
-			MethodName: "Heartbeat",
-			Handler:    _Logic_Heartbeat_Handler,
+			MethodName: "PushRoom",
+			Handler:    _LogicPush_PushRoom_Handler,
 		},
 		{
-			MethodName: "RenewOnline",
-			Handler:    _Logic_RenewOnline_Handler,
+			MethodName: "PushAll",
+			Handler:    _LogicPush_PushAll_Handler,
 		},
 		{
-			MethodName: "Receive",
-			Handler:    _Logic_Receive_Handler,
+			MethodName: "PushBatch",
+			Handler:    _LogicPush_PushBatch_Handler,
 		},
 		{
-			MethodName: "Nodes",
-			Handler:    _Logic_Nodes_Handler,
+			MethodName: "OnlineTop",
+			Handler:    _LogicPush_OnlineTop_Handler,
+		},
+		{
+			MethodName: "OnlineRoom",
+			Handler:    _LogicPush_OnlineRoom_Handler,
+		},
+		{
+			MethodName: "OnlineTotal",
+			Handler:    _LogicPush_OnlineTotal_Handler,
+		},
+		{
+			MethodName: "Presence",
+			Handler:    _LogicPush_Presence_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
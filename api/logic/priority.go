@@ -0,0 +1,13 @@
+package logic
+
+// Valid check the priority is a defined one.
+func (x PushMsg_Priority) Valid() bool {
+	_, ok := PushMsg_Priority_name[int32(x)]
+	return ok
+}
+
+// IsHigh check the priority goes through the high priority lane,
+// logic, job and comet all use it so that they agree on the lane.
+func (x PushMsg_Priority) IsHigh() bool {
+	return x == PushMsg_HIGH
+}
@@ -46,4 +46,27 @@ const (
 	OpUnsub = int32(16)
 	// OpUnsubReply unsubscribe operation reply
 	OpUnsubReply = int32(17)
+
+	// OpPresence friend presence changed
+	OpPresence = int32(18)
+
+	// OpRead mark messages read
+	OpRead = int32(19)
+	// OpReadReply mark messages read reply
+	OpReadReply = int32(20)
+	// OpReadReceipt the peer read the messages
+	OpReadReceipt = int32(21)
+
+	// OpHistory query the history messages
+	OpHistory = int32(22)
+	// OpHistoryReply query the history messages reply
+	OpHistoryReply = int32(23)
+
+	// OpMessageControl a message is recalled, deleted or flagged, clients remove it
+	OpMessageControl = int32(24)
+
+	// OpUpstreamLimited the upstream operation is over the rate limit, the body is the limited operation
+	OpUpstreamLimited = int32(25)
+	// OpQueueFull the upstream queue of the comet is full, the message is dropped
+	OpQueueFull = int32(26)
 )
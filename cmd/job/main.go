@@ -32,7 +32,6 @@ func main() {
 
 	// job
 	j := job.New(conf.Conf)
-	consumer, _ := job.NewNsqConsumer(conf.Conf.Nsq, j)
 	// signal
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT)
@@ -41,8 +40,9 @@ func main() {
 		log.Info("goim-job get a signal %s", s.String())
 		switch s {
 		case syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT:
-			j.Close()
-			consumer.Close()
+			if err := j.Close(); err != nil {
+				log.Error("goim-job close error(%v)", err)
+			}
 			log.Info("goim-job [version: %s] exit", ver)
 			log.Close()
 			return
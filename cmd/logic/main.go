@@ -36,6 +36,7 @@ func main() {
 	resolver.Register(dis)
 	// logic
 	srv := logic.New(conf.Conf)
+	conf.OnReload(srv.ReloadFilter)
 	httpSrv := http.New(conf.Conf.HTTPServer, srv)
 	rpcSrv := grpc.New(conf.Conf.RPCServer, srv)
 	cancel := register(dis, srv)
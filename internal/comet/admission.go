@@ -0,0 +1,230 @@
+package comet
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	pb "github.com/ningchengzeng/goim/api/comet"
+	"github.com/ningchengzeng/goim/internal/comet/conf"
+	"github.com/ningchengzeng/goim/internal/comet/errors"
+	"github.com/ningchengzeng/goim/pkg/ratelimit"
+)
+
+// _banSweep is the interval to remove the expired bans.
+const _banSweep = time.Minute
+
+// admission is the connection admission control before the handshake,
+// it counts the connections and keeps the ban list of ips and mids.
+type admission struct {
+	mutex   sync.Mutex
+	c       *conf.Admission
+	accepts *ratelimit.Bucket
+	conns   int
+	banIPs  map[string]int64 // ip -> expire unix seconds, 0 is forever
+	banMids map[int64]int64  // mid -> expire unix seconds, 0 is forever
+}
+
+func newAdmission() *admission {
+	return &admission{
+		banIPs:  make(map[string]int64),
+		banMids: make(map[int64]int64),
+	}
+}
+
+// banned check the expire of a ban.
+func banned(expire int64, ok bool, now int64) bool {
+	return ok && (expire == 0 || expire > now)
+}
+
+// admit check the ip ban, the accept rate, the total and the ip connections,
+// ipConns is the handshaked connections of the ip counted by the buckets.
+// An admitted connection must be released after closed.
+func (a *admission) admit(c *conf.Admission, ip string, ipConns int32) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	expire, ok := a.banIPs[ip]
+	if banned(expire, ok, time.Now().Unix()) {
+		return errors.ErrBanned
+	}
+	// the accept bucket is rebuilt after the config reloaded
+	if a.c != c {
+		a.c = c
+		a.accepts = nil
+		if c.AcceptRate > 0 {
+			a.accepts = ratelimit.New(c.AcceptRate, c.AcceptBurst)
+		}
+	}
+	if a.accepts != nil && !a.accepts.Allow() {
+		return errors.ErrAdmission
+	}
+	if c.MaxConns > 0 && a.conns >= c.MaxConns {
+		return errors.ErrAdmission
+	}
+	if c.MaxIPConns > 0 && int(ipConns) >= c.MaxIPConns {
+		return errors.ErrAdmission
+	}
+	a.conns++
+	return nil
+}
+
+// release release an admitted connection.
+func (a *admission) release() {
+	a.mutex.Lock()
+	a.conns--
+	a.mutex.Unlock()
+}
+
+// bannedMid check the mid is banned.
+func (a *admission) bannedMid(mid int64) bool {
+	a.mutex.Lock()
+	expire, ok := a.banMids[mid]
+	a.mutex.Unlock()
+	return banned(expire, ok, time.Now().Unix())
+}
+
+// ban add the bans, a ban of both ip and mid is added as two bans.
+func (a *admission) ban(bans []*pb.Ban) {
+	a.mutex.Lock()
+	for _, b := range bans {
+		if b.Ip != "" {
+			a.banIPs[b.Ip] = b.Expire
+		}
+		if b.Mid != 0 {
+			a.banMids[b.Mid] = b.Expire
+		}
+	}
+	a.mutex.Unlock()
+}
+
+// unban remove the bans of the ips and mids.
+func (a *admission) unban(ips []string, mids []int64) {
+	a.mutex.Lock()
+	for _, ip := range ips {
+		delete(a.banIPs, ip)
+	}
+	for _, mid := range mids {
+		delete(a.banMids, mid)
+	}
+	a.mutex.Unlock()
+}
+
+// bans get the bans, the expired ones are removed.
+func (a *admission) bans() (res []*pb.Ban) {
+	a.sweep(time.Now().Unix())
+	a.mutex.Lock()
+	for ip, expire := range a.banIPs {
+		res = append(res, &pb.Ban{Ip: ip, Expire: expire})
+	}
+	for mid, expire := range a.banMids {
+		res = append(res, &pb.Ban{Mid: mid, Expire: expire})
+	}
+	a.mutex.Unlock()
+	return
+}
+
+// sweep remove the bans expired at now.
+func (a *admission) sweep(now int64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for ip, expire := range a.banIPs {
+		if !banned(expire, true, now) {
+			delete(a.banIPs, ip)
+		}
+	}
+	for mid, expire := range a.banMids {
+		if !banned(expire, true, now) {
+			delete(a.banMids, mid)
+		}
+	}
+}
+
+// remoteIP get the ip of the remote address.
+func remoteIP(conn net.Conn) string {
+	ip, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	return ip
+}
+
+// ipConns get the connection count of the ip in all the buckets.
+func (s *Server) ipConns(ip string) (n int32) {
+	for _, b := range s.buckets {
+		n += b.IPConns(ip)
+	}
+	return
+}
+
+// Admit check whether a new connection is admitted before the handshake.
+func (s *Server) Admit(conn net.Conn) (err error) {
+	ip := remoteIP(conn)
+	var ipConns int32
+	if s.c.Admission.MaxIPConns > 0 {
+		ipConns = s.ipConns(ip)
+	}
+	if err = s.admission.admit(s.c.Admission, ip, ipConns); err != nil {
+		log.Warn("admission refused remoteIP:%s error(%v)", conn.RemoteAddr().String(), err)
+	}
+	return
+}
+
+// Release release an admitted connection after it's closed.
+func (s *Server) Release(conn net.Conn) {
+	s.admission.release()
+}
+
+// banproc remove the expired bans.
+func (s *Server) banproc() {
+	for {
+		time.Sleep(_banSweep)
+		s.admission.sweep(time.Now().Unix())
+	}
+}
+
+// admitMid check the authorized mid is not banned, a banned one is disconnected from logic.
+func (s *Server) admitMid(ctx context.Context, mid int64, key string) (err error) {
+	if !s.admission.bannedMid(mid) {
+		return
+	}
+	if err = s.Disconnect(ctx, mid, key); err != nil {
+		log.Error("s.Disconnect(%d,%s) error(%v)", mid, key, err)
+	}
+	return errors.ErrBanned
+}
+
+// Ban ban the ips and mids, then close their connections.
+func (s *Server) Ban(bans []*pb.Ban) {
+	s.admission.ban(bans)
+	var (
+		ips  = make(map[string]struct{})
+		mids = make(map[int64]struct{})
+	)
+	for _, b := range bans {
+		if b.Ip != "" {
+			ips[b.Ip] = struct{}{}
+		}
+		if b.Mid != 0 {
+			mids[b.Mid] = struct{}{}
+		}
+	}
+	for _, bucket := range s.buckets {
+		for _, ch := range bucket.Channels(func(ch *Channel) bool {
+			_, ipOK := ips[ch.IP]
+			_, midOK := mids[ch.Mid]
+			return ipOK || midOK
+		}) {
+			log.Info("ban close key:%s mid:%d ip:%s", ch.Key, ch.Mid, ch.IP)
+			ch.Close()
+		}
+	}
+}
+
+// Unban remove the bans of the ips and mids.
+func (s *Server) Unban(ips []string, mids []int64) {
+	s.admission.unban(ips, mids)
+}
+
+// Bans get all the bans.
+func (s *Server) Bans() []*pb.Ban {
+	return s.admission.bans()
+}
@@ -0,0 +1,53 @@
+package comet
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/ningchengzeng/goim/api/comet"
+	"github.com/ningchengzeng/goim/internal/comet/conf"
+	"github.com/ningchengzeng/goim/internal/comet/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmissionAdmit(t *testing.T) {
+	var (
+		a = newAdmission()
+		c = &conf.Admission{MaxConns: 2, MaxIPConns: 1}
+	)
+	assert.Nil(t, a.admit(c, "10.0.0.1", 0))
+	assert.Equal(t, errors.ErrAdmission, a.admit(c, "10.0.0.1", 1))
+	assert.Nil(t, a.admit(c, "10.0.0.2", 0))
+	assert.Equal(t, errors.ErrAdmission, a.admit(c, "10.0.0.3", 0))
+	a.release()
+	assert.Nil(t, a.admit(c, "10.0.0.3", 0))
+	// the accept rate
+	c = &conf.Admission{AcceptRate: 1, AcceptBurst: 1}
+	assert.Nil(t, a.admit(c, "10.0.0.4", 0))
+	assert.Equal(t, errors.ErrAdmission, a.admit(c, "10.0.0.4", 0))
+}
+
+func TestAdmissionBan(t *testing.T) {
+	var (
+		a   = newAdmission()
+		c   = &conf.Admission{}
+		now = time.Now().Unix()
+	)
+	a.ban([]*pb.Ban{
+		{Ip: "10.0.0.1"},
+		{Mid: 1, Expire: now + 60},
+		{Mid: 2, Expire: now - 1},
+	})
+	assert.Equal(t, errors.ErrBanned, a.admit(c, "10.0.0.1", 0))
+	assert.Nil(t, a.admit(c, "10.0.0.2", 0))
+	assert.True(t, a.bannedMid(1))
+	assert.False(t, a.bannedMid(2))
+	assert.False(t, a.bannedMid(3))
+	// the expired ones are swept
+	assert.Len(t, a.bans(), 2)
+	a.sweep(now + 61)
+	assert.Len(t, a.bans(), 1)
+	a.unban([]string{"10.0.0.1"}, nil)
+	assert.Empty(t, a.bans())
+	assert.Nil(t, a.admit(c, "10.0.0.1", 0))
+}
@@ -5,6 +5,7 @@ import (
 	"sync/atomic"
 
 	pb "github.com/ningchengzeng/goim/api/comet"
+	"github.com/ningchengzeng/goim/api/logic"
 	"github.com/ningchengzeng/goim/api/protocol"
 	"github.com/ningchengzeng/goim/internal/comet/conf"
 )
@@ -151,6 +152,18 @@ func (b *Bucket) Del(dch *Channel) {
 	}
 }
 
+// Channels get the channels matched.
+func (b *Bucket) Channels(match func(ch *Channel) bool) (res []*Channel) {
+	b.cLock.RLock()
+	for _, ch := range b.chs {
+		if match(ch) {
+			res = append(res, ch)
+		}
+	}
+	b.cLock.RUnlock()
+	return
+}
+
 // Channel get a channel by sub key.
 func (b *Bucket) Channel(key string) (ch *Channel) {
 	b.cLock.RLock()
@@ -160,14 +173,18 @@ func (b *Bucket) Channel(key string) (ch *Channel) {
 }
 
 // Broadcast push msgs to all channels in the bucket.
-func (b *Bucket) Broadcast(p *protocol.Proto, op int32) {
+func (b *Bucket) Broadcast(p *protocol.Proto, op int32, priority bool) {
 	var ch *Channel
 	b.cLock.RLock()
 	for _, ch = range b.chs {
 		if !ch.NeedPush(op) {
 			continue
 		}
-		_ = ch.Push(p)
+		if priority {
+			_ = ch.PushPriority(p)
+		} else {
+			_ = ch.Push(p)
+		}
 	}
 	b.cLock.RUnlock()
 }
@@ -211,6 +228,14 @@ func (b *Bucket) Rooms() (res map[string]struct{}) {
 	return
 }
 
+// IPConns get the connection count of the ip.
+func (b *Bucket) IPConns(ip string) (n int32) {
+	b.cLock.RLock()
+	n = b.ipCnts[ip]
+	b.cLock.RUnlock()
+	return
+}
+
 // IPCount get ip count.
 func (b *Bucket) IPCount() (res map[string]struct{}) {
 	var (
@@ -243,7 +268,7 @@ func (b *Bucket) roomproc(c chan *pb.BroadcastRoomReq) {
 	for {
 		arg := <-c
 		if room := b.Room(arg.RoomID); room != nil {
-			room.Push(arg.Proto)
+			room.Push(arg.Proto, logic.PushMsg_Priority(arg.Priority).IsHigh())
 		}
 	}
 }
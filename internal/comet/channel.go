@@ -2,9 +2,12 @@ package comet
 
 import (
 	"sync"
+	"time"
 
 	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/comet/errors"
 	"github.com/ningchengzeng/goim/pkg/bufio"
+	"github.com/ningchengzeng/goim/pkg/ratelimit"
 )
 
 // Channel used by message pusher send msg to write goroutine.
@@ -12,6 +15,9 @@ type Channel struct {
 	Room     *Room
 	CliProto Ring
 	signal   chan *protocol.Proto
+	priority chan *protocol.Proto
+	done     chan struct{} // closed if the finish can't be queued
+	doneOnce sync.Once
 	Writer   bufio.Writer
 	Reader   bufio.Reader
 	Next     *Channel
@@ -21,6 +27,12 @@ type Channel struct {
 	Key      string
 	IP       string
 	watchOps map[int32]struct{}
+	msgIDs   *delivered                  // recently delivered message ids, nil until the first one
+	upstream map[int32]*ratelimit.Bucket // upstream limits by operation
+	strikes  int
+	struck   time.Time
+	pending  []*protocol.Proto // upstream messages waiting for logic
+	sending  bool              // the pending messages are being sent
 	mutex    sync.RWMutex
 }
 
@@ -29,6 +41,8 @@ func NewChannel(cli, svr int) *Channel {
 	c := new(Channel)
 	c.CliProto.Init(cli)
 	c.signal = make(chan *protocol.Proto, svr)
+	c.priority = make(chan *protocol.Proto, svr)
+	c.done = make(chan struct{})
 	c.watchOps = make(map[int32]struct{})
 	return c
 }
@@ -62,6 +76,48 @@ func (c *Channel) NeedPush(op int32) bool {
 	return false
 }
 
+// Delivered check whether the message id was delivered recently, or record it.
+func (c *Channel) Delivered(msgID string) bool {
+	if msgID == "" {
+		return false
+	}
+	c.mutex.Lock()
+	if c.msgIDs == nil {
+		c.msgIDs = newDelivered(_msgIDSize)
+	}
+	ids := c.msgIDs
+	c.mutex.Unlock()
+	return !ids.add(msgID)
+}
+
+// AllowUpstream check the upstream rate of the operation on the channel.
+func (c *Channel) AllowUpstream(op int32, rate float64, burst int) bool {
+	c.mutex.Lock()
+	if c.upstream == nil {
+		c.upstream = make(map[int32]*ratelimit.Bucket)
+	}
+	b, ok := c.upstream[op]
+	if !ok {
+		b = ratelimit.New(rate, burst)
+		c.upstream[op] = b
+	}
+	c.mutex.Unlock()
+	return b.AllowRateAt(rate, burst, time.Now())
+}
+
+// Strike record a limited frame, return the number of the limited frames within the window.
+func (c *Channel) Strike(window time.Duration) int {
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if now.Sub(c.struck) > window {
+		c.strikes = 0
+		c.struck = now
+	}
+	c.strikes++
+	return c.strikes
+}
+
 // Push server push message.
 func (c *Channel) Push(p *protocol.Proto) (err error) {
 	select {
@@ -71,17 +127,60 @@ func (c *Channel) Push(p *protocol.Proto) (err error) {
 	return
 }
 
+// Reply push the reply of an upstream message, it reports the drop if the channel is full.
+func (c *Channel) Reply(p *protocol.Proto) (err error) {
+	select {
+	case c.signal <- p:
+	default:
+		err = errors.ErrSignalFull
+	}
+	return
+}
+
+// PushPriority server push a high priority message, it is written before the normal ones.
+// If the priority lane is full, it falls back to the normal lane.
+func (c *Channel) PushPriority(p *protocol.Proto) (err error) {
+	select {
+	case c.priority <- p:
+	default:
+		return c.Push(p)
+	}
+	return
+}
+
 // Ready check the channel ready or close?
+// The priority lane always goes first.
 func (c *Channel) Ready() *protocol.Proto {
-	return <-c.signal
+	select {
+	case p := <-c.priority:
+		return p
+	default:
+	}
+	select {
+	case p := <-c.priority:
+		return p
+	case p := <-c.signal:
+		return p
+	case <-c.done:
+		return protocol.ProtoFinish
+	}
 }
 
 // Signal send signal to the channel, protocol ready.
+// It returns at once if the channel is done, the writer is gone then.
 func (c *Channel) Signal() {
-	c.signal <- protocol.ProtoReady
+	select {
+	case c.signal <- protocol.ProtoReady:
+	case <-c.done:
+	}
 }
 
-// Close close the channel.
+// Close close the channel without blocking, the finish is queued after the
+// pushed messages, or the channel is done at once if the queue is full.
 func (c *Channel) Close() {
-	c.signal <- protocol.ProtoFinish
+	select {
+	case c.signal <- protocol.ProtoFinish:
+	default:
+		c.doneOnce.Do(func() { close(c.done) })
+	}
 }
@@ -0,0 +1,26 @@
+package comet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelSignalDone(t *testing.T) {
+	ch := NewChannel(1, 1)
+	assert.Nil(t, ch.Push(&protocol.Proto{Op: protocol.OpRaw}))
+	// the queue is full, so the channel is done at once
+	ch.Close()
+	done := make(chan struct{})
+	go func() {
+		ch.Signal()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("signal blocked on a done channel")
+	}
+}
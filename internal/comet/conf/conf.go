@@ -1,6 +1,7 @@
 package conf
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -38,6 +39,9 @@ type Config struct {
 	RPCClient *RPCClient
 	RPCServer *RPCServer
 	Whitelist *Whitelist
+	Ephemeral *Ephemeral
+	Upstream  *Upstream
+	Admission *Admission
 	Log       *log.Config
 }
 
@@ -57,10 +61,90 @@ type Env struct {
 	Addrs     []string
 }
 
+// Ephemeral is the ephemeral signal config, like typing indicators,
+// the signals are routed straight to the target comets and never persisted.
+type Ephemeral struct {
+	Ops []int32
+	// Rate and Burst limit the signals of a sender per second.
+	Rate  float64
+	Burst int
+}
+
+// IsEphemeral check the upstream operation is an ephemeral signal.
+func (e *Ephemeral) IsEphemeral(op int32) bool {
+	for _, o := range e.Ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Upstream is the config of the upstream client operations.
+// The rate limits are enabled by Enable, every operation has its own token buckets
+// per connection, per user and per ip.
+// The upstream messages are queued by connection up to Queue and sent in order,
+// at most Workers messages of all the connections are sent to logic at the same time.
+type Upstream struct {
+	Enable  bool
+	Workers int
+	Queue   int
+	Timeout xtime.Duration
+	// Default is the limit of the operations not in Ops.
+	Default *UpstreamLimit
+	// Ops are the limits by operation.
+	Ops map[string]*UpstreamLimit
+	// Strikes is the number of the limited frames within Window before the connection is closed.
+	Strikes int
+	Window  xtime.Duration
+	// Idle is how long the idle buckets of users and ips are kept.
+	Idle xtime.Duration
+	ops  map[int32]*UpstreamLimit
+}
+
+// UpstreamLimit limits an operation per connection, per user and per ip.
+type UpstreamLimit struct {
+	Channel Limit
+	Mid     Limit
+	IP      Limit
+}
+
+// Limit is a token bucket limit, a zero rate is unlimited.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Limit get the limit of the operation.
+func (u *Upstream) Limit(op int32) *UpstreamLimit {
+	if l, ok := u.ops[op]; ok {
+		return l
+	}
+	return u.Default
+}
+
+// Admission is the connection admission config checked before the handshake, zero is unlimited.
+type Admission struct {
+	// MaxConns is the max connections of the comet.
+	MaxConns int
+	// MaxIPConns is the max connections of an ip.
+	MaxIPConns int
+	// AcceptRate and AcceptBurst limit the new connections per second.
+	AcceptRate  float64
+	AcceptBurst int
+}
+
 // RPCClient is RPC client config.
 type RPCClient struct {
 	Dial    xtime.Duration
 	Timeout xtime.Duration
+	// Stream multiplexes the connection events on a stream, the unary rpcs are the fallback.
+	Stream bool
+	// HeartbeatBatch and HeartbeatFlush batch the heartbeats sent to logic.
+	HeartbeatBatch int
+	HeartbeatFlush xtime.Duration
+	// OnlineTick is the interval of the room counts sent to logic, only the changed ones are sent.
+	OnlineTick xtime.Duration
 }
 
 // RPCServer is RPC server config.
@@ -156,6 +240,15 @@ func (r *RPCClient) fix() error {
 	if r.Timeout == 0 {
 		r.Timeout = xtime.Duration(time.Second)
 	}
+	if r.HeartbeatBatch == 0 {
+		r.HeartbeatBatch = 100
+	}
+	if r.HeartbeatFlush == 0 {
+		r.HeartbeatFlush = xtime.Duration(time.Second)
+	}
+	if r.OnlineTick == 0 {
+		r.OnlineTick = xtime.Duration(time.Second)
+	}
 	return nil
 }
 
@@ -259,6 +352,56 @@ func (b *Bucket) fix() error {
 	return nil
 }
 
+func (e *Ephemeral) fix() error {
+	if e.Rate == 0 {
+		e.Rate = 5
+	}
+	if e.Burst == 0 {
+		e.Burst = 10
+	}
+	return nil
+}
+
+func (u *Upstream) fix() (err error) {
+	if u.Default == nil {
+		u.Default = &UpstreamLimit{}
+	}
+	if u.Strikes == 0 {
+		u.Strikes = 10
+	}
+	if u.Workers == 0 {
+		u.Workers = 32
+	}
+	if u.Queue == 0 {
+		u.Queue = 64
+	}
+	if u.Timeout == 0 {
+		u.Timeout = xtime.Duration(time.Second)
+	}
+	if u.Window == 0 {
+		u.Window = xtime.Duration(10 * time.Second)
+	}
+	if u.Idle == 0 {
+		u.Idle = xtime.Duration(time.Minute)
+	}
+	u.ops = make(map[int32]*UpstreamLimit, len(u.Ops))
+	for op, l := range u.Ops {
+		var o int64
+		if o, err = strconv.ParseInt(op, 10, 32); err != nil {
+			return
+		}
+		u.ops[int32(o)] = l
+	}
+	return
+}
+
+func (a *Admission) fix() (err error) {
+	if a.AcceptRate > 0 && a.AcceptBurst == 0 {
+		a.AcceptBurst = int(a.AcceptRate)
+	}
+	return
+}
+
 func (c *Config) fix() (err error) {
 	if c.Env == nil {
 		c.Env = new(Env)
@@ -346,6 +489,27 @@ func (c *Config) fix() (err error) {
 	if err = c.Bucket.fix(); err != nil {
 		return
 	}
+
+	if c.Ephemeral == nil {
+		c.Ephemeral = &Ephemeral{}
+	}
+	if err = c.Ephemeral.fix(); err != nil {
+		return
+	}
+
+	if c.Upstream == nil {
+		c.Upstream = &Upstream{}
+	}
+	if err = c.Upstream.fix(); err != nil {
+		return
+	}
+
+	if c.Admission == nil {
+		c.Admission = &Admission{}
+	}
+	if err = c.Admission.fix(); err != nil {
+		return
+	}
 	return
 }
 
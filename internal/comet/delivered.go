@@ -0,0 +1,39 @@
+package comet
+
+import "sync"
+
+const (
+	// _msgIDSize is the number of recently delivered message ids kept by a channel.
+	_msgIDSize = 128
+	// _broadcastIDSize is the number of recently delivered broadcast ids kept by the server.
+	_broadcastIDSize = 4096
+)
+
+// delivered is a fixed size set of the recently delivered message ids, the
+// oldest id is evicted when it's full.
+type delivered struct {
+	mutex sync.Mutex
+	ids   []string
+	idx   int
+	seen  map[string]struct{}
+}
+
+func newDelivered(size int) *delivered {
+	return &delivered{ids: make([]string, size), seen: make(map[string]struct{}, size)}
+}
+
+// add record the id, ok is false if it was delivered recently.
+func (d *delivered) add(id string) (ok bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if _, dup := d.seen[id]; dup {
+		return false
+	}
+	if old := d.ids[d.idx]; old != "" {
+		delete(d.seen, old)
+	}
+	d.ids[d.idx] = id
+	d.seen[id] = struct{}{}
+	d.idx = (d.idx + 1) % len(d.ids)
+	return true
+}
@@ -9,6 +9,10 @@ var (
 	// server
 	ErrHandshake = errors.New("handshake failed")
 	ErrOperation = errors.New("request operation not valid")
+	ErrUpstream  = errors.New("upstream operations over the rate limit")
+	// admission
+	ErrAdmission = errors.New("connection not admitted")
+	ErrBanned    = errors.New("connection banned")
 	// ring
 	ErrRingEmpty = errors.New("ring buffer empty")
 	ErrRingFull  = errors.New("ring buffer full")
@@ -17,6 +21,7 @@ var (
 	ErrTimerEmpty  = errors.New("timer empty")
 	ErrTimerNoItem = errors.New("timer item not exist")
 	// channel
+	ErrSignalFull   = errors.New("signal channel full, msg dropped")
 	ErrPushMsgArg   = errors.New("rpc pushmsg arg error")
 	ErrPushMsgsArg  = errors.New("rpc pushmsgs arg error")
 	ErrMPushMsgArg  = errors.New("rpc mpushmsg arg error")
@@ -26,7 +31,9 @@ var (
 	ErrBroadCastRoomArg = errors.New("rpc broadcast  room arg error")
 
 	// room
-	ErrRoomDroped = errors.New("room droped")
+	ErrRoomDroped        = errors.New("room droped")
+	ErrRoomMembersArg    = errors.New("rpc room members arg error")
+	ErrRoomMembersCursor = errors.New("rpc room members cursor left the room")
 	// rpc
 	ErrLogic = errors.New("logic rpc is not available")
 )
@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+
+	pb "github.com/ningchengzeng/goim/api/comet"
+	"github.com/ningchengzeng/goim/internal/comet"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type adminServer struct {
+	srv *comet.Server
+}
+
+var _ pb.CometAdminServer = &adminServer{}
+
+// Ban ban the ips and mids.
+func (s *adminServer) Ban(ctx context.Context, req *pb.BanReq) (*pb.BanReply, error) {
+	for _, b := range req.Bans {
+		if b.Ip == "" && b.Mid == 0 {
+			return nil, status.Error(codes.InvalidArgument, "ban ip and mid are empty")
+		}
+	}
+	s.srv.Ban(req.Bans)
+	return &pb.BanReply{}, nil
+}
+
+// Unban remove the bans of the ips and mids.
+func (s *adminServer) Unban(ctx context.Context, req *pb.UnbanReq) (*pb.UnbanReply, error) {
+	s.srv.Unban(req.Ips, req.Mids)
+	return &pb.UnbanReply{}, nil
+}
+
+// Bans get all the bans.
+func (s *adminServer) Bans(ctx context.Context, req *pb.BansReq) (*pb.BansReply, error) {
+	return &pb.BansReply{Bans: s.srv.Bans()}, nil
+}
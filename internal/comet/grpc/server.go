@@ -2,18 +2,26 @@ package grpc
 
 import (
 	"context"
+	"io"
 	"net"
+	"sync"
 	"time"
 
+	log "github.com/go-kratos/kratos/pkg/log"
 	pb "github.com/ningchengzeng/goim/api/comet"
+	"github.com/ningchengzeng/goim/api/logic"
 	"github.com/ningchengzeng/goim/internal/comet"
 	"github.com/ningchengzeng/goim/internal/comet/conf"
 	"github.com/ningchengzeng/goim/internal/comet/errors"
+	"github.com/zhenjl/cityhash"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
 
+// _pushStreamWorkers is the workers handling the pushes of a stream.
+const _pushStreamWorkers = 16
+
 // New comet grpc server.
 func New(c *conf.RPCServer, s *comet.Server) *grpc.Server {
 	keepParams := grpc.KeepaliveParams(keepalive.ServerParameters{
@@ -25,6 +33,7 @@ func New(c *conf.RPCServer, s *comet.Server) *grpc.Server {
 	})
 	srv := grpc.NewServer(keepParams)
 	pb.RegisterCometServer(srv, &server{s})
+	pb.RegisterCometAdminServer(srv, &adminServer{s})
 	lis, err := net.Listen(c.Network, c.Addr)
 	if err != nil {
 		panic(err)
@@ -37,6 +46,10 @@ func New(c *conf.RPCServer, s *comet.Server) *grpc.Server {
 	return srv
 }
 
+const (
+	_maxRoomMembers = 1000
+)
+
 type server struct {
 	srv *comet.Server
 }
@@ -53,7 +66,15 @@ func (s *server) PushMsg(ctx context.Context, req *pb.PushMsgReq) (reply *pb.Pus
 			if !channel.NeedPush(req.ProtoOp) {
 				continue
 			}
-			if err = channel.Push(req.Proto); err != nil {
+			if channel.Delivered(req.MsgID) {
+				continue
+			}
+			if logic.PushMsg_Priority(req.Priority).IsHigh() {
+				err = channel.PushPriority(req.Proto)
+			} else {
+				err = channel.Push(req.Proto)
+			}
+			if err != nil {
 				return
 			}
 		}
@@ -66,10 +87,13 @@ func (s *server) Broadcast(ctx context.Context, req *pb.BroadcastReq) (*pb.Broad
 	if req.Proto == nil {
 		return nil, errors.ErrBroadCastArg
 	}
+	if s.srv.Delivered("", req.MsgID) {
+		return &pb.BroadcastReply{}, nil
+	}
 	// TODO use broadcast queue
 	go func() {
 		for _, bucket := range s.srv.Buckets() {
-			bucket.Broadcast(req.GetProto(), req.ProtoOp)
+			bucket.Broadcast(req.GetProto(), req.ProtoOp, logic.PushMsg_Priority(req.Priority).IsHigh())
 			if req.Speed > 0 {
 				t := bucket.ChannelCount() / int(req.Speed)
 				time.Sleep(time.Duration(t) * time.Second)
@@ -84,6 +108,9 @@ func (s *server) BroadcastRoom(ctx context.Context, req *pb.BroadcastRoomReq) (*
 	if req.Proto == nil || req.RoomID == "" {
 		return nil, errors.ErrBroadCastRoomArg
 	}
+	if s.srv.Delivered(req.RoomID, req.MsgID) {
+		return &pb.BroadcastRoomReply{}, nil
+	}
 	for _, bucket := range s.srv.Buckets() {
 		bucket.BroadcastRoom(req)
 	}
@@ -102,3 +129,120 @@ func (s *server) Rooms(ctx context.Context, req *pb.RoomsReq) (*pb.RoomsReply, e
 	}
 	return &pb.RoomsReply{Rooms: roomIds}, nil
 }
+
+// RoomMembers page the members of a room in all the buckets, the cursor is
+// the key of the last member paged, it's in the bucket of the key.
+func (s *server) RoomMembers(ctx context.Context, req *pb.RoomMembersReq) (*pb.RoomMembersReply, error) {
+	if req.RoomID == "" || req.Limit <= 0 {
+		return nil, errors.ErrRoomMembersArg
+	}
+	if req.Limit > _maxRoomMembers {
+		req.Limit = _maxRoomMembers
+	}
+	var (
+		reply   = &pb.RoomMembersReply{}
+		limit   = int(req.Limit)
+		buckets = s.srv.Buckets()
+		from    int
+	)
+	if req.Cursor != "" {
+		from = s.srv.BucketIndex(req.Cursor)
+	}
+	for i := from; i < len(buckets); i++ {
+		var after string
+		if i == from {
+			after = req.Cursor
+		}
+		room := buckets[i].Room(req.RoomID)
+		if room == nil {
+			if after != "" {
+				return nil, errors.ErrRoomMembersCursor
+			}
+			continue
+		}
+		chs, ok := room.Channels(after, limit-len(reply.Members))
+		if !ok {
+			return nil, errors.ErrRoomMembersCursor
+		}
+		for _, ch := range chs {
+			reply.Members = append(reply.Members, &pb.RoomMember{Mid: ch.Mid, Key: ch.Key})
+		}
+		if len(reply.Members) >= limit {
+			reply.Next = reply.Members[len(reply.Members)-1].Key
+			break
+		}
+	}
+	return reply, nil
+}
+
+// PushStream receive the pushes on a stream, they are handled by the workers
+// and every push is acked with its seq. The pushes of the same keys or room go
+// to the same worker to keep the order.
+func (s *server) PushStream(stream pb.Comet_PushStreamServer) error {
+	var (
+		mutex sync.Mutex // grpc stream send is not safe for concurrent use
+		wg    sync.WaitGroup
+		works = make([]chan *pb.PushStreamReq, _pushStreamWorkers)
+	)
+	for i := range works {
+		works[i] = make(chan *pb.PushStreamReq, _pushStreamWorkers)
+		wg.Add(1)
+		go func(reqs chan *pb.PushStreamReq) {
+			defer wg.Done()
+			for req := range reqs {
+				ack := s.pushStream(stream.Context(), req)
+				mutex.Lock()
+				err := stream.Send(ack)
+				mutex.Unlock()
+				if err != nil {
+					log.Error("stream.Send(%d) error(%v)", ack.Seq, err)
+				}
+			}
+		}(works[i])
+	}
+	defer func() {
+		for _, reqs := range works {
+			close(reqs)
+		}
+		wg.Wait()
+	}()
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		works[pushStreamWorker(req)] <- req
+	}
+}
+
+// pushStreamWorker get the worker of a push by its keys or room.
+func pushStreamWorker(req *pb.PushStreamReq) uint32 {
+	var target string
+	switch {
+	case req.PushMsg != nil && len(req.PushMsg.Keys) > 0:
+		target = req.PushMsg.Keys[0]
+	case req.BroadcastRoom != nil:
+		target = req.BroadcastRoom.RoomID
+	}
+	return cityhash.CityHash32([]byte(target), uint32(len(target))) % _pushStreamWorkers
+}
+
+func (s *server) pushStream(ctx context.Context, req *pb.PushStreamReq) (ack *pb.PushStreamAck) {
+	var err error
+	switch {
+	case req.PushMsg != nil:
+		_, err = s.PushMsg(ctx, req.PushMsg)
+	case req.BroadcastRoom != nil:
+		_, err = s.BroadcastRoom(ctx, req.BroadcastRoom)
+	case req.Broadcast != nil:
+		_, err = s.Broadcast(ctx, req.Broadcast)
+	}
+	ack = &pb.PushStreamAck{Seq: req.Seq}
+	if err != nil {
+		ack.Error = err.Error()
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package comet
+
+import (
+	"context"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/internal/comet/conf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// heartbeats aggregates the heartbeats of the channels into batches, a batch is
+// sent on the stream if connected, or by the Heartbeats rpc.
+// The mappings in logic keep their redis expire, so if the comet dies they
+// expire like before, a batch only delays the renewals by HeartbeatFlush.
+type heartbeats struct {
+	c      *conf.RPCClient
+	client logic.LogicClient
+	stream *logicStream
+	server string
+	reqs   chan *logic.HeartbeatReq
+	unary  bool // logic has no Heartbeats rpc, send them one by one
+}
+
+func newHeartbeats(c *conf.RPCClient, client logic.LogicClient, stream *logicStream, server string) *heartbeats {
+	h := &heartbeats{
+		c:      c,
+		client: client,
+		stream: stream,
+		server: server,
+		reqs:   make(chan *logic.HeartbeatReq, c.HeartbeatBatch*2),
+	}
+	go h.heartbeatproc()
+	return h
+}
+
+// add queue the heartbeat to the next batch, ok is false if the queue is full.
+func (h *heartbeats) add(req *logic.HeartbeatReq) (ok bool) {
+	select {
+	case h.reqs <- req:
+		return true
+	default:
+		return false
+	}
+}
+
+// heartbeatproc send the heartbeats in batches.
+func (h *heartbeats) heartbeatproc() {
+	var (
+		batch  []*logic.HeartbeatReq
+		ticker = time.NewTicker(time.Duration(h.c.HeartbeatFlush))
+	)
+	for {
+		select {
+		case req := <-h.reqs:
+			if batch = append(batch, req); len(batch) >= h.c.HeartbeatBatch {
+				h.flush(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				h.flush(batch)
+				batch = nil
+			}
+		}
+	}
+}
+
+// flush send the batch on the stream, or by the Heartbeats rpc, a failed batch
+// falls back to the Heartbeat rpc one by one.
+func (h *heartbeats) flush(batch []*logic.HeartbeatReq) {
+	ok, err := h.stream.heartbeats(context.Background(), batch)
+	if ok && err == nil {
+		return
+	}
+	if err != nil {
+		log.Error("logic stream heartbeats(%d) error(%v)", len(batch), err)
+	}
+	if !h.unary {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.c.Timeout))
+		_, err = h.client.Heartbeats(ctx, &logic.HeartbeatsReq{Server: h.server, Heartbeats: batch})
+		cancel()
+		if err == nil {
+			return
+		}
+		if status.Code(err) == codes.Unimplemented {
+			log.Warn("logic has no Heartbeats rpc, send the heartbeats one by one")
+			h.unary = true
+		} else {
+			log.Error("logic Heartbeats(%d) error(%v), send them one by one", len(batch), err)
+		}
+	}
+	for _, req := range batch {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.c.Timeout))
+		if _, err := h.client.Heartbeat(ctx, req); err != nil {
+			log.Error("logic Heartbeat(%d,%s) error(%v)", req.Mid, req.Key, err)
+		}
+		cancel()
+	}
+}
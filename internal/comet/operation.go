@@ -2,11 +2,15 @@ package comet
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	log "github.com/go-kratos/kratos/pkg/log"
 	"github.com/ningchengzeng/goim/api/logic"
 	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/comet/errors"
+	"github.com/ningchengzeng/goim/pkg/ratelimit"
+	"github.com/ningchengzeng/goim/pkg/room"
 	"github.com/ningchengzeng/goim/pkg/strings"
 
 	"google.golang.org/grpc"
@@ -15,57 +19,151 @@ import (
 
 // Connect connected a connection.
 func (s *Server) Connect(c context.Context, p *protocol.Proto, cookie string) (mid int64, key, rid string, accepts []int32, heartbeat time.Duration, err error) {
-	reply, err := s.rpcClient.Connect(c, &logic.ConnectReq{
+	req := &logic.ConnectReq{
 		Server: s.serverID,
 		Cookie: cookie,
 		Token:  p.Body,
-	})
-	if err != nil {
+	}
+	var reply *logic.ConnectReply
+	if ev, ok, e := s.stream.call(c, &logic.ConnEvent{Type: logic.ConnEvent_CONNECT, Connect: req}); ok {
+		if err = e; err != nil {
+			return
+		}
+		reply = ev.Connect
+	} else if reply, err = s.rpcClient.Connect(c, req); err != nil {
 		return
 	}
 	return reply.Mid, reply.Key, reply.RoomID, reply.Accepts, time.Duration(reply.Heartbeat), nil
 }
 
-// Disconnect disconnected a connection.
+// Disconnect disconnected a connection, it's sent even if c is canceled,
+// but no longer than the rpc timeout.
 func (s *Server) Disconnect(c context.Context, mid int64, key string) (err error) {
-	_, err = s.rpcClient.Disconnect(context.Background(), &logic.DisconnectReq{
+	req := &logic.DisconnectReq{
 		Server: s.serverID,
 		Mid:    mid,
 		Key:    key,
-	})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.c.RPCClient.Timeout))
+	defer cancel()
+	if _, ok, err := s.stream.call(ctx, &logic.ConnEvent{Type: logic.ConnEvent_DISCONNECT, Disconnect: req}); ok {
+		return err
+	}
+	_, err = s.rpcClient.Disconnect(ctx, req)
 	return
 }
 
-// Heartbeat heartbeat a connection session.
+// Heartbeat heartbeat a connection session, it's sent in the next batch.
 func (s *Server) Heartbeat(ctx context.Context, mid int64, key string) (err error) {
-	_, err = s.rpcClient.Heartbeat(ctx, &logic.HeartbeatReq{
+	req := &logic.HeartbeatReq{
 		Server: s.serverID,
 		Mid:    mid,
 		Key:    key,
-	})
+	}
+	if s.heartbeats.add(req) {
+		return
+	}
+	_, err = s.rpcClient.Heartbeat(ctx, req)
 	return
 }
 
-// RenewOnline renew room online.
+// RenewOnline renew room online, only the changed room counts are sent.
 func (s *Server) RenewOnline(ctx context.Context, serverID string, rommCount map[string]int32) (allRoom map[string]int32, err error) {
-	reply, err := s.rpcClient.RenewOnline(ctx, &logic.OnlineReq{
+	if allRoom, ok, err := s.stream.online(ctx, s.serverID, rommCount); ok {
+		// the counts sent on the stream are unknown to the unary delta
+		s.rooms = nil
+		return allRoom, err
+	}
+	req := &logic.OnlineReq{
 		Server:    s.serverID,
 		RoomCount: rommCount,
-	}, grpc.UseCompressor(gzip.Name))
+	}
+	if s.rooms != nil {
+		req.RoomCount = room.CountDelta(s.rooms, rommCount)
+		req.Delta = true
+	}
+	reply, err := s.rpcClient.RenewOnline(ctx, req, grpc.UseCompressor(gzip.Name))
 	if err != nil {
+		s.rooms = nil
 		return
 	}
+	s.rooms = rommCount
 	return reply.AllRoomCount, nil
 }
 
 // Receive receive a message.
-func (s *Server) Receive(ctx context.Context, mid int64, p *protocol.Proto) (err error) {
-	_, err = s.rpcClient.Receive(ctx, &logic.ReceiveReq{Mid: mid, Proto: p})
+func (s *Server) Receive(ctx context.Context, ch *Channel, p *protocol.Proto) (err error) {
+	var roomID string
+	if room := ch.Room; room != nil {
+		roomID = room.ID
+	}
+	req := &logic.ReceiveReq{Mid: ch.Mid, RoomID: roomID, Proto: p}
+	if _, ok, err := s.stream.call(ctx, &logic.ConnEvent{Type: logic.ConnEvent_RECEIVE, Receive: req}); ok {
+		return err
+	}
+	_, err = s.rpcClient.Receive(ctx, req)
 	return
 }
 
+// Signal send an ephemeral signal, it is never persisted or retried.
+func (s *Server) Signal(ctx context.Context, ch *Channel, p *protocol.Proto) (err error) {
+	var roomID string
+	if ch.Room != nil {
+		roomID = ch.Room.ID
+	}
+	_, err = s.rpcClient.Signal(ctx, &logic.SignalReq{Mid: ch.Mid, Key: ch.Key, RoomID: roomID, Proto: p})
+	return
+}
+
+// History query the history messages of the user or the room of the channel.
+func (s *Server) History(ctx context.Context, ch *Channel, p *protocol.Proto) (body []byte, err error) {
+	var roomID string
+	if room := ch.Room; room != nil {
+		roomID = room.ID
+	}
+	reply, err := s.rpcClient.History(ctx, &logic.HistoryReq{Mid: ch.Mid, RoomID: roomID, Proto: p})
+	if err != nil {
+		return
+	}
+	return reply.Body, nil
+}
+
+// allowUpstream check the upstream rate of the operation by the channel, the user and the ip.
+func (s *Server) allowUpstream(ch *Channel, op int32) bool {
+	l := s.c.Upstream.Limit(op)
+	if l.Channel.Rate > 0 && !ch.AllowUpstream(op, l.Channel.Rate, l.Channel.Burst) {
+		return false
+	}
+	if l.Mid.Rate > 0 && ch.Mid != 0 && !s.midLimits.Allow(ratelimit.Key{ID: ch.Mid, Op: op}, l.Mid.Rate, l.Mid.Burst) {
+		return false
+	}
+	if l.IP.Rate > 0 && ch.IP != "" && !s.ipLimits.Allow(ratelimit.Key{Name: ch.IP, Op: op}, l.IP.Rate, l.IP.Burst) {
+		return false
+	}
+	return true
+}
+
+// allowSignal check the ephemeral signal rate of the sender, a guest is
+// limited by the key of the channel.
+func (s *Server) allowSignal(ch *Channel) bool {
+	key := ratelimit.Key{ID: ch.Mid}
+	if ch.Mid == 0 {
+		key = ratelimit.Key{Name: ch.Key}
+	}
+	return s.signalLimits.Allow(key, s.c.Ephemeral.Rate, s.c.Ephemeral.Burst)
+}
+
 // Operate operate.
 func (s *Server) Operate(ctx context.Context, p *protocol.Proto, ch *Channel, b *Bucket) error {
+	if s.c.Upstream.Enable && !s.allowUpstream(ch, p.Op) {
+		if ch.Strike(time.Duration(s.c.Upstream.Window)) >= s.c.Upstream.Strikes {
+			log.Warn("upstream key:%s mid:%d ip:%s op:%d over rate too many times, close it", ch.Key, ch.Mid, ch.IP, p.Op)
+			return errors.ErrUpstream
+		}
+		p.Body = []byte(strconv.FormatInt(int64(p.Op), 10))
+		p.Op = protocol.OpUpstreamLimited
+		return nil
+	}
 	switch p.Op {
 	case protocol.OpChangeRoom:
 		if err := b.ChangeRoom(string(p.Body), ch); err != nil {
@@ -82,10 +180,34 @@ func (s *Server) Operate(ctx context.Context, p *protocol.Proto, ch *Channel, b
 			ch.UnWatch(ops...)
 		}
 		p.Op = protocol.OpUnsubReply
+	case protocol.OpRead, protocol.OpHistory:
+		// OpReadReply and OpHistoryReply are pushed after logic handled them
+		if s.ReceiveAsync(ch, p) {
+			p.Op = protocol.OpProtoReady
+		} else {
+			log.Warn("upstream queue is full, drop key:%s op:%d", ch.Key, p.Op)
+			p.Op = protocol.OpQueueFull
+		}
+		p.Body = nil
 	default:
-		// TODO ack ok&failed
-		if err := s.Receive(ctx, ch.Mid, p); err != nil {
-			log.Error("s.Report(%d) op:%d error(%v)", ch.Mid, p.Op, err)
+		if s.c.Ephemeral.IsEphemeral(p.Op) {
+			// the signal is sent by the upstream queue, it is dropped if the queue is full
+			if !s.allowSignal(ch) {
+				log.Warn("signal key:%s op:%d over rate, drop it", ch.Key, p.Op)
+			} else if !s.ReceiveAsync(ch, p) {
+				log.Warn("upstream queue is full, drop signal key:%s op:%d", ch.Key, p.Op)
+			}
+			// a signal has no reply
+			p.Op = protocol.OpProtoReady
+			p.Body = nil
+			break
+		}
+		// OpSendMsgReply is pushed after logic received it
+		if s.ReceiveAsync(ch, p) {
+			p.Op = protocol.OpProtoReady
+		} else {
+			log.Warn("upstream queue is full, drop key:%s op:%d", ch.Key, p.Op)
+			p.Op = protocol.OpQueueFull
 		}
 		p.Body = nil
 	}
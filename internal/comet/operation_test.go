@@ -0,0 +1,27 @@
+package comet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ningchengzeng/goim/internal/comet/conf"
+	"github.com/ningchengzeng/goim/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowSignal(t *testing.T) {
+	s := &Server{
+		c:            &conf.Config{Ephemeral: &conf.Ephemeral{Rate: 1, Burst: 1}},
+		signalLimits: ratelimit.NewGroup(time.Minute),
+	}
+	defer s.signalLimits.Close()
+	ch1, ch2, guest := NewChannel(1, 1), NewChannel(1, 1), NewChannel(1, 1)
+	ch1.Mid, ch1.Key = 1, "key1"
+	ch2.Mid, ch2.Key = 1, "key2"
+	guest.Key = "key3"
+	// the connections of a user share the limit
+	assert.True(t, s.allowSignal(ch1))
+	assert.False(t, s.allowSignal(ch2))
+	assert.True(t, s.allowSignal(guest))
+	assert.False(t, s.allowSignal(guest))
+}
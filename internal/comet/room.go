@@ -12,6 +12,7 @@ type Room struct {
 	ID        string
 	rLock     sync.RWMutex
 	next      *Channel
+	chs       map[string]*Channel // key -> channel, the cursor of Channels
 	drop      bool
 	Online    int32 // dirty read is ok
 	AllOnline int32
@@ -23,6 +24,7 @@ func NewRoom(id string) (r *Room) {
 	r.ID = id
 	r.drop = false
 	r.next = nil
+	r.chs = make(map[string]*Channel)
 	r.Online = 0
 	return
 }
@@ -37,6 +39,7 @@ func (r *Room) Put(ch *Channel) (err error) {
 		ch.Next = r.next
 		ch.Prev = nil
 		r.next = ch // insert to header
+		r.chs[ch.Key] = ch
 		r.Online++
 	} else {
 		err = errors.ErrRoomDroped
@@ -58,17 +61,44 @@ func (r *Room) Del(ch *Channel) bool {
 	} else {
 		r.next = ch.Next
 	}
+	if r.chs[ch.Key] == ch {
+		delete(r.chs, ch.Key)
+	}
 	r.Online--
 	r.drop = (r.Online == 0)
 	r.rLock.Unlock()
 	return r.drop
 }
 
+// Channels get at most limit channels after the channel of the key, or from
+// the first one if the key is empty. ok is false if the key left the room.
+func (r *Room) Channels(after string, limit int) (chs []*Channel, ok bool) {
+	r.rLock.RLock()
+	ch := r.next
+	if after != "" {
+		var last *Channel
+		if last, ok = r.chs[after]; !ok {
+			r.rLock.RUnlock()
+			return
+		}
+		ch = last.Next
+	}
+	for ; ch != nil && len(chs) < limit; ch = ch.Next {
+		chs = append(chs, ch)
+	}
+	r.rLock.RUnlock()
+	return chs, true
+}
+
 // Push push msg to the room, if chan full discard it.
-func (r *Room) Push(p *protocol.Proto) {
+func (r *Room) Push(p *protocol.Proto, priority bool) {
 	r.rLock.RLock()
 	for ch := r.next; ch != nil; ch = ch.Next {
-		_ = ch.Push(p)
+		if priority {
+			_ = ch.PushPriority(p)
+		} else {
+			_ = ch.Push(p)
+		}
 	}
 	r.rLock.RUnlock()
 }
@@ -0,0 +1,38 @@
+package comet
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomChannels(t *testing.T) {
+	r := NewRoom("test://room")
+	var chs []*Channel
+	for i := 0; i < 5; i++ {
+		ch := NewChannel(1, 1)
+		ch.Key = fmt.Sprintf("key_%d", i)
+		assert.Nil(t, r.Put(ch))
+		chs = append(chs, ch)
+	}
+	// the last put is the first
+	page, ok := r.Channels("", 2)
+	assert.True(t, ok)
+	assert.Equal(t, []*Channel{chs[4], chs[3]}, page)
+	page, ok = r.Channels(chs[3].Key, 2)
+	assert.True(t, ok)
+	assert.Equal(t, []*Channel{chs[2], chs[1]}, page)
+	// a channel left before the cursor doesn't move the page
+	r.Del(chs[4])
+	page, ok = r.Channels(chs[1].Key, 2)
+	assert.True(t, ok)
+	assert.Equal(t, []*Channel{chs[0]}, page)
+	page, ok = r.Channels(chs[0].Key, 2)
+	assert.True(t, ok)
+	assert.Empty(t, page)
+	// the cursor left the room
+	r.Del(chs[1])
+	_, ok = r.Channels(chs[1].Key, 2)
+	assert.False(t, ok)
+}
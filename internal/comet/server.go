@@ -8,6 +8,7 @@ import (
 	log "github.com/go-kratos/kratos/pkg/log"
 	"github.com/ningchengzeng/goim/api/logic"
 	"github.com/ningchengzeng/goim/internal/comet/conf"
+	"github.com/ningchengzeng/goim/pkg/ratelimit"
 	"github.com/zhenjl/cityhash"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/balancer/roundrobin"
@@ -58,8 +59,21 @@ type Server struct {
 	buckets   []*Bucket // subkey bucket
 	bucketIdx uint32
 
-	serverID  string
-	rpcClient logic.LogicClient
+	serverID   string
+	rpcClient  logic.LogicClient
+	stream     *logicStream // nil if the stream is disabled
+	heartbeats *heartbeats
+	// rooms is the room counts sent by the unary rpc, nil to send them full.
+	rooms map[string]int32
+	// upstream limits of users and ips
+	midLimits *ratelimit.Group
+	ipLimits  *ratelimit.Group
+	// signal limits of the senders, keyed by the user or the guest key
+	signalLimits *ratelimit.Group
+	admission    *admission
+	upstream     *upstream
+	// broadcasts is the recently delivered broadcast ids
+	broadcasts *delivered
 }
 
 // NewServer returns a new Server.
@@ -68,6 +82,12 @@ func NewServer(c *conf.Config) *Server {
 		c:         c,
 		round:     NewRound(c),
 		rpcClient: newLogicClient(c.RPCClient),
+		midLimits: ratelimit.NewGroup(time.Duration(c.Upstream.Idle)),
+		ipLimits:  ratelimit.NewGroup(time.Duration(c.Upstream.Idle)),
+		admission: newAdmission(),
+
+		signalLimits: ratelimit.NewGroup(time.Duration(c.Upstream.Idle)),
+		broadcasts:   newDelivered(_broadcastIDSize),
 	}
 	// init bucket
 	s.buckets = make([]*Bucket, c.Bucket.Size)
@@ -76,7 +96,13 @@ func NewServer(c *conf.Config) *Server {
 		s.buckets[i] = NewBucket(c.Bucket)
 	}
 	s.serverID = c.Env.Host
+	if c.RPCClient.Stream {
+		s.stream = newLogicStream(c.RPCClient, s.rpcClient)
+	}
+	s.heartbeats = newHeartbeats(c.RPCClient, s.rpcClient, s.stream, s.serverID)
+	s.upstream = newUpstream(s, c.Upstream.Workers, c.Upstream.Queue)
 	go s.onlineproc()
+	go s.banproc()
 	return s
 }
 
@@ -87,13 +113,27 @@ func (s *Server) Buckets() []*Bucket {
 
 // Bucket get the bucket by subkey.
 func (s *Server) Bucket(subKey string) *Bucket {
-	idx := cityhash.CityHash32([]byte(subKey), uint32(len(subKey))) % s.bucketIdx
+	idx := s.BucketIndex(subKey)
 	if conf.Conf.Debug {
 		log.Info("%s hit channel bucket index: %d use cityhash", subKey, idx)
 	}
 	return s.buckets[idx]
 }
 
+// BucketIndex get the index of the bucket in Buckets by subkey.
+func (s *Server) BucketIndex(subKey string) int {
+	return int(cityhash.CityHash32([]byte(subKey), uint32(len(subKey))) % s.bucketIdx)
+}
+
+// Delivered check whether the broadcast of the message id to the target was
+// delivered recently, or record it.
+func (s *Server) Delivered(target, msgID string) bool {
+	if msgID == "" {
+		return false
+	}
+	return !s.broadcasts.add(target + ":" + msgID)
+}
+
 // RandServerHearbeat rand server heartbeat.
 func (s *Server) RandServerHearbeat() time.Duration {
 	return (minServerHeartbeat + time.Duration(rand.Int63n(int64(maxServerHeartbeat-minServerHeartbeat))))
@@ -101,6 +141,10 @@ func (s *Server) RandServerHearbeat() time.Duration {
 
 // Close close the server.
 func (s *Server) Close() (err error) {
+	s.upstream.close()
+	s.midLimits.Close()
+	s.ipLimits.Close()
+	s.signalLimits.Close()
 	return
 }
 
@@ -123,6 +167,6 @@ func (s *Server) onlineproc() {
 		for _, bucket := range s.buckets {
 			bucket.UpRoomsCount(allRoomsCount)
 		}
-		time.Sleep(time.Second * 10)
+		time.Sleep(time.Duration(s.c.RPCClient.OnlineTick))
 	}
 }
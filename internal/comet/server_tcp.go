@@ -71,6 +71,10 @@ func acceptTCP(server *Server, lis *net.TCPListener) {
 			log.Error("conn.SetWriteBuffer() error(%v)", err)
 			return
 		}
+		if err = server.Admit(conn); err != nil {
+			conn.Close()
+			continue
+		}
 		go serveTCP(server, conn, r)
 		if r++; r == maxInt {
 			r = 0
@@ -92,6 +96,7 @@ func serveTCP(s *Server, conn *net.TCPConn, r int) {
 		log.Info("start tcp serve \"%s\" with \"%s\"", lAddr, rAddr)
 	}
 	s.ServeTCP(conn, rp, wp, tr)
+	s.Release(conn)
 }
 
 // ServeTCP serve a tcp connection.
@@ -127,6 +132,9 @@ func (s *Server) ServeTCP(conn *net.TCPConn, rp, wp *bytes.Pool, tr *xtime.Timer
 	step = 1
 	if p, err = ch.CliProto.Set(); err == nil {
 		if ch.Mid, ch.Key, rid, accepts, hb, err = s.authTCP(ctx, rr, wr, p); err == nil {
+			err = s.admitMid(ctx, ch.Mid, ch.Key)
+		}
+		if err == nil {
 			ch.Watch(accepts...)
 			b = s.Bucket(ch.Key)
 			err = b.Put(rid, ch)
@@ -234,6 +242,7 @@ func (s *Server) dispatchTCP(conn *net.TCPConn, wr *bufio.Writer, wp *bytes.Pool
 		if white {
 			whitelist.Printf("key: %s wait proto ready\n", ch.Key)
 		}
+		// high priority frames are always ready first
 		var p = ch.Ready()
 		if white {
 			whitelist.Printf("key: %s proto ready\n", ch.Key)
@@ -260,7 +269,9 @@ func (s *Server) dispatchTCP(conn *net.TCPConn, wr *bufio.Writer, wp *bytes.Pool
 				if white {
 					whitelist.Printf("key: %s start write client proto%v\n", ch.Key, p)
 				}
-				if p.Op == protocol.OpHeartbeatReply {
+				if p.Op == protocol.OpProtoReady {
+					// the reply is pushed later, like an async upstream message
+				} else if p.Op == protocol.OpHeartbeatReply {
 					if ch.Room != nil {
 						online = ch.Room.OnlineNum()
 					}
@@ -102,6 +102,10 @@ func acceptWebsocket(server *Server, lis *net.TCPListener) {
 			log.Error("conn.SetWriteBuffer() error(%v)", err)
 			return
 		}
+		if err = server.Admit(conn); err != nil {
+			conn.Close()
+			continue
+		}
 		go serveWebsocket(server, conn, r)
 		if r++; r == maxInt {
 			r = 0
@@ -124,6 +128,10 @@ func acceptWebsocketWithTLS(server *Server, lis net.Listener) {
 			log.Error("listener.Accept(\"%s\") error(%v)", lis.Addr().String(), err)
 			return
 		}
+		if err = server.Admit(conn); err != nil {
+			conn.Close()
+			continue
+		}
 		go serveWebsocket(server, conn, r)
 		if r++; r == maxInt {
 			r = 0
@@ -145,6 +153,7 @@ func serveWebsocket(s *Server, conn net.Conn, r int) {
 		log.Info("start tcp serve \"%s\" with \"%s\"", lAddr, rAddr)
 	}
 	s.ServeWebsocket(conn, rp, wp, tr)
+	s.Release(conn)
 }
 
 // ServeWebsocket serve a websocket connection.
@@ -208,6 +217,9 @@ func (s *Server) ServeWebsocket(conn net.Conn, rp, wp *bytes.Pool, tr *xtime.Tim
 	step = 3
 	if p, err = ch.CliProto.Set(); err == nil {
 		if ch.Mid, ch.Key, rid, accepts, hb, err = s.authWebsocket(ctx, ws, p, req.Header.Get("Cookie")); err == nil {
+			err = s.admitMid(ctx, ch.Mid, ch.Key)
+		}
+		if err == nil {
 			ch.Watch(accepts...)
 			b = s.Bucket(ch.Key)
 			err = b.Put(rid, ch)
@@ -317,6 +329,7 @@ func (s *Server) dispatchWebsocket(ws *websocket.Conn, wp *bytes.Pool, wb *bytes
 		if white {
 			whitelist.Printf("key: %s wait proto ready\n", ch.Key)
 		}
+		// high priority frames are always ready first
 		var p = ch.Ready()
 		if white {
 			whitelist.Printf("key: %s proto ready\n", ch.Key)
@@ -343,7 +356,9 @@ func (s *Server) dispatchWebsocket(ws *websocket.Conn, wp *bytes.Pool, wb *bytes
 				if white {
 					whitelist.Printf("key: %s start write client proto%v\n", ch.Key, p)
 				}
-				if p.Op == protocol.OpHeartbeatReply {
+				if p.Op == protocol.OpProtoReady {
+					// the reply is pushed later, like an async upstream message
+				} else if p.Op == protocol.OpHeartbeatReply {
 					if ch.Room != nil {
 						online = ch.Room.OnlineNum()
 					}
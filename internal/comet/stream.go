@@ -0,0 +1,199 @@
+package comet
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/internal/comet/conf"
+	"github.com/ningchengzeng/goim/pkg/room"
+)
+
+const (
+	_streamRetry = time.Second
+	// _streamQueue is the events waiting for the writer of the stream.
+	_streamQueue = 1024
+)
+
+var errStreamClosed = errors.New("logic event stream closed")
+
+// logicStream multiplexes the connection events to logic on a bidirectional stream,
+// the events are written by one writer goroutine, and the room counts are sent as deltas.
+// The events not sent on the stream, like when it's reconnecting, fall back to the unary rpcs.
+type logicStream struct {
+	c      *conf.RPCClient
+	client logic.LogicClient
+	mutex  sync.Mutex
+	events chan *logic.ConnEvent // the writer queue, nil if not connected
+	seq    int64
+	waits  map[int64]chan *logic.ConnEventReply
+	// rooms is the room counts sent, nil after the stream reconnected to send them full.
+	rooms map[string]int32
+	// allRooms is all the room counts applied by the deltas from logic, ack is the seq of the last applied.
+	allRooms map[string]int32
+	ack      int64
+}
+
+func newLogicStream(c *conf.RPCClient, client logic.LogicClient) *logicStream {
+	ls := &logicStream{
+		c:      c,
+		client: client,
+		waits:  make(map[int64]chan *logic.ConnEventReply),
+	}
+	go ls.streamproc()
+	return ls
+}
+
+// streamproc keep the stream connected and dispatch the replies.
+func (ls *logicStream) streamproc() {
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := ls.client.Events(ctx)
+		if err != nil {
+			log.Error("logic Events() error(%v)", err)
+			cancel()
+			time.Sleep(_streamRetry)
+			continue
+		}
+		events := make(chan *logic.ConnEvent, _streamQueue)
+		ls.mutex.Lock()
+		ls.events = events
+		ls.rooms = nil
+		ls.ack = 0
+		ls.mutex.Unlock()
+		go ls.writeproc(ctx, cancel, stream, events)
+		for {
+			reply, err := stream.Recv()
+			if err != nil {
+				log.Error("logic stream.Recv() error(%v)", err)
+				break
+			}
+			ls.mutex.Lock()
+			wait, ok := ls.waits[reply.Seq]
+			delete(ls.waits, reply.Seq)
+			ls.mutex.Unlock()
+			if ok {
+				wait <- reply
+			}
+		}
+		cancel()
+		ls.mutex.Lock()
+		ls.events = nil
+		for seq, wait := range ls.waits {
+			close(wait)
+			delete(ls.waits, seq)
+		}
+		ls.mutex.Unlock()
+		time.Sleep(_streamRetry)
+	}
+}
+
+// writeproc write the queued events on the stream until it's broken.
+func (ls *logicStream) writeproc(ctx context.Context, cancel context.CancelFunc, stream logic.Logic_EventsClient, events chan *logic.ConnEvent) {
+	for {
+		select {
+		case ev := <-events:
+			if err := stream.Send(ev); err != nil {
+				log.Error("logic stream.Send(%v) error(%v)", ev.Type, err)
+				// the reader gets the error and reconnects
+				cancel()
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// send queue the event to the writer, ok is false if the stream is not connected or the queue is full.
+func (ls *logicStream) send(ev *logic.ConnEvent, wait chan *logic.ConnEventReply) (ok bool) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	if ls.events == nil {
+		return false
+	}
+	if wait != nil {
+		ls.seq++
+		ev.Seq = ls.seq
+		ls.waits[ev.Seq] = wait
+	}
+	select {
+	case ls.events <- ev:
+		return true
+	default:
+		log.Warn("logic stream queue is full, drop event:%v", ev.Type)
+		delete(ls.waits, ev.Seq)
+		return false
+	}
+}
+
+// call send the event and wait for the reply, ok is false if the event is not sent,
+// then the caller falls back to the unary rpc.
+func (ls *logicStream) call(ctx context.Context, ev *logic.ConnEvent) (reply *logic.ConnEventReply, ok bool, err error) {
+	if ls == nil {
+		return
+	}
+	wait := make(chan *logic.ConnEventReply, 1)
+	if ok = ls.send(ev, wait); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(ls.c.Timeout))
+	defer cancel()
+	select {
+	case reply = <-wait:
+		if reply == nil {
+			err = errStreamClosed
+		} else if reply.Error != "" {
+			err = errors.New(reply.Error)
+		}
+	case <-ctx.Done():
+		ls.mutex.Lock()
+		delete(ls.waits, ev.Seq)
+		ls.mutex.Unlock()
+		err = ctx.Err()
+	}
+	return
+}
+
+// heartbeats send a batch of heartbeats and wait for the reply, ok is false if the stream is not connected.
+func (ls *logicStream) heartbeats(ctx context.Context, batch []*logic.HeartbeatReq) (ok bool, err error) {
+	_, ok, err = ls.call(ctx, &logic.ConnEvent{Type: logic.ConnEvent_HEARTBEAT, Heartbeats: batch})
+	return
+}
+
+// online send the room count delta, then apply the reply delta of all the room counts.
+func (ls *logicStream) online(ctx context.Context, server string, roomCount map[string]int32) (allRooms map[string]int32, ok bool, err error) {
+	if ls == nil {
+		return
+	}
+	ls.mutex.Lock()
+	delta := &logic.OnlineDelta{Server: server, Full: ls.rooms == nil, Ack: ls.ack}
+	if delta.Full {
+		delta.RoomCount = roomCount
+	} else {
+		delta.RoomCount = room.CountDelta(ls.rooms, roomCount)
+	}
+	ls.mutex.Unlock()
+	reply, ok, err := ls.call(ctx, &logic.ConnEvent{Type: logic.ConnEvent_ONLINE, Online: delta})
+	if !ok || err != nil {
+		return
+	}
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	ls.rooms = roomCount
+	if reply.Online != nil {
+		if reply.Online.Full || ls.allRooms == nil {
+			ls.allRooms = make(map[string]int32, len(reply.Online.RoomCount))
+		}
+		room.ApplyCountDelta(ls.allRooms, reply.Online.RoomCount)
+		ls.ack = reply.Seq
+	}
+	allRooms = make(map[string]int32, len(ls.allRooms))
+	for room, count := range ls.allRooms {
+		allRooms[room] = count
+	}
+	return
+}
@@ -0,0 +1,151 @@
+package comet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/internal/comet/conf"
+	xtime "github.com/ningchengzeng/goim/pkg/time"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// testEventsClient is the client side of an event stream, the events sent
+// are read from sent and the replies are written to replies.
+type testEventsClient struct {
+	grpc.ClientStream
+	ctx     context.Context
+	sent    chan *logic.ConnEvent
+	replies chan *logic.ConnEventReply
+}
+
+func (c *testEventsClient) Send(ev *logic.ConnEvent) error {
+	select {
+	case c.sent <- ev:
+		return nil
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+}
+
+func (c *testEventsClient) Recv() (*logic.ConnEventReply, error) {
+	select {
+	case reply := <-c.replies:
+		return reply, nil
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	}
+}
+
+func newTestStream(t *testing.T) (ls *logicStream, ec *testEventsClient) {
+	ec = &testEventsClient{
+		sent:    make(chan *logic.ConnEvent),
+		replies: make(chan *logic.ConnEventReply),
+	}
+	cli := &testLogicClient{events: func(ctx context.Context) (logic.Logic_EventsClient, error) {
+		ec.ctx = ctx
+		return ec, nil
+	}}
+	ls = newLogicStream(&conf.RPCClient{Timeout: xtime.Duration(time.Second)}, cli)
+	for i := 0; ; i++ {
+		ls.mutex.Lock()
+		connected := ls.events != nil
+		ls.mutex.Unlock()
+		if connected {
+			return
+		}
+		if i > 100 {
+			t.Fatal("logic stream is not connected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLogicStreamNotConnected(t *testing.T) {
+	var ls *logicStream
+	_, ok, err := ls.call(context.Background(), &logic.ConnEvent{Type: logic.ConnEvent_HEARTBEAT})
+	assert.False(t, ok)
+	assert.Nil(t, err)
+	// the events fall back to the unary rpcs before the stream connected
+	ls = &logicStream{c: &conf.RPCClient{Timeout: xtime.Duration(time.Second)}, waits: make(map[int64]chan *logic.ConnEventReply)}
+	_, ok, err = ls.call(context.Background(), &logic.ConnEvent{Type: logic.ConnEvent_HEARTBEAT, Heartbeats: []*logic.HeartbeatReq{{Mid: 1}}})
+	assert.False(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestLogicStreamCall(t *testing.T) {
+	ls, ec := newTestStream(t)
+	go func() {
+		ev := <-ec.sent
+		ec.replies <- &logic.ConnEventReply{Type: ev.Type, Seq: ev.Seq}
+		ev = <-ec.sent
+		ec.replies <- &logic.ConnEventReply{Type: ev.Type, Seq: ev.Seq, Error: "logic error"}
+	}()
+	_, ok, err := ls.call(context.Background(), &logic.ConnEvent{Type: logic.ConnEvent_HEARTBEAT, Heartbeats: []*logic.HeartbeatReq{{Mid: 1, Key: "key_1"}}})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	_, ok, err = ls.call(context.Background(), &logic.ConnEvent{Type: logic.ConnEvent_RECEIVE})
+	assert.True(t, ok)
+	assert.EqualError(t, err, "logic error")
+	// no reply within the timeout
+	go func() { <-ec.sent }()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, ok, err = ls.call(ctx, &logic.ConnEvent{Type: logic.ConnEvent_RECEIVE})
+	assert.True(t, ok)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	ls.mutex.Lock()
+	assert.Empty(t, ls.waits)
+	ls.mutex.Unlock()
+}
+
+func TestLogicStreamOnline(t *testing.T) {
+	ls, ec := newTestStream(t)
+	c := context.Background()
+	// the first room counts are sent full
+	done := make(chan *logic.ConnEvent, 1)
+	go func() {
+		ev := <-ec.sent
+		done <- ev
+		ec.replies <- &logic.ConnEventReply{Type: ev.Type, Seq: ev.Seq, Online: &logic.OnlineDelta{
+			Full:      true,
+			RoomCount: map[string]int32{"test://room_01": 10, "test://room_02": 5},
+		}}
+	}()
+	all, ok, err := ls.online(c, "test_server", map[string]int32{"test://room_01": 1, "test://room_02": 1})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int32{"test://room_01": 10, "test://room_02": 5}, all)
+	first := <-done
+	assert.True(t, first.Online.Full)
+	assert.Equal(t, int64(0), first.Online.Ack)
+	// then the changes, acking the reply applied
+	go func() {
+		ev := <-ec.sent
+		done <- ev
+		ec.replies <- &logic.ConnEventReply{Type: ev.Type, Seq: ev.Seq, Online: &logic.OnlineDelta{
+			RoomCount: map[string]int32{"test://room_02": 0},
+		}}
+	}()
+	all, ok, err = ls.online(c, "test_server", map[string]int32{"test://room_01": 2})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int32{"test://room_01": 10}, all)
+	second := <-done
+	assert.False(t, second.Online.Full)
+	assert.Equal(t, first.Seq, second.Online.Ack)
+	assert.Equal(t, map[string]int32{"test://room_01": 2, "test://room_02": 0}, second.Online.RoomCount)
+}
+
+func TestDisconnectTimeout(t *testing.T) {
+	s := &Server{
+		c:         &conf.Config{RPCClient: &conf.RPCClient{Timeout: xtime.Duration(50 * time.Millisecond)}},
+		rpcClient: &testLogicClient{},
+	}
+	start := time.Now()
+	err := s.Disconnect(context.Background(), 1, "test_key")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
@@ -0,0 +1,140 @@
+package comet
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/protocol"
+	"google.golang.org/grpc/status"
+)
+
+// upstreamMsg is an upstream message waiting for the logic receive.
+type upstreamMsg struct {
+	ch *Channel
+	p  *protocol.Proto
+}
+
+// upstream sends the upstream messages to logic, so that a slow logic doesn't
+// block the reader of the connection. The messages of a connection are queued
+// on the channel and sent in order by one goroutine, at most workers messages
+// of all the connections are sent at the same time.
+type upstream struct {
+	s       *Server
+	size    int
+	workers chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.RWMutex
+	closed  bool
+}
+
+func newUpstream(s *Server, workers, size int) *upstream {
+	return &upstream{s: s, size: size, workers: make(chan struct{}, workers)}
+}
+
+// put queue the message on the channel, return false if the queue is full or closed.
+func (u *upstream) put(ch *Channel, p *protocol.Proto) bool {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	if u.closed {
+		return false
+	}
+	ch.mutex.Lock()
+	if len(ch.pending) >= u.size {
+		ch.mutex.Unlock()
+		return false
+	}
+	ch.pending = append(ch.pending, p)
+	start := !ch.sending
+	ch.sending = true
+	ch.mutex.Unlock()
+	if start {
+		u.wg.Add(1)
+		go u.sendproc(ch)
+	}
+	return true
+}
+
+// next get the next queued message of the channel, ok is false if none is left.
+func (u *upstream) next(ch *Channel) (p *protocol.Proto, ok bool) {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+	if len(ch.pending) == 0 {
+		ch.sending = false
+		return nil, false
+	}
+	p = ch.pending[0]
+	ch.pending[0] = nil
+	ch.pending = ch.pending[1:]
+	return p, true
+}
+
+// sendproc send the queued messages of the channel until none is left,
+// a channel too slow to take the reply is closed.
+func (u *upstream) sendproc(ch *Channel) {
+	defer u.wg.Done()
+	for {
+		p, ok := u.next(ch)
+		if !ok {
+			return
+		}
+		u.workers <- struct{}{}
+		reply := u.s.upstreamReply(&upstreamMsg{ch: ch, p: p})
+		<-u.workers
+		if reply == nil {
+			continue
+		}
+		if err := ch.Reply(reply); err != nil {
+			log.Error("ch.Reply(%s) op:%d error(%v), close it", ch.Key, reply.Op, err)
+			ch.Close()
+		}
+	}
+}
+
+// close stop queueing and wait for the queued messages sent.
+func (u *upstream) close() {
+	u.mutex.Lock()
+	u.closed = true
+	u.mutex.Unlock()
+	u.wg.Wait()
+}
+
+// ReceiveAsync queue the upstream message, the client proto is copied
+// because the buffer of the reader is reused.
+func (s *Server) ReceiveAsync(ch *Channel, p *protocol.Proto) bool {
+	body := make([]byte, len(p.Body))
+	copy(body, p.Body)
+	return s.upstream.put(ch, &protocol.Proto{Ver: p.Ver, Op: p.Op, Seq: p.Seq, Body: body})
+}
+
+// upstreamReply send the upstream message to logic and make the reply,
+// the body of a failed reply is the error message. A signal has no reply.
+func (s *Server) upstreamReply(msg *upstreamMsg) (reply *protocol.Proto) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.c.Upstream.Timeout))
+	defer cancel()
+	var err error
+	if s.c.Ephemeral.IsEphemeral(msg.p.Op) {
+		if err = s.Signal(ctx, msg.ch, msg.p); err != nil {
+			log.Error("s.Signal(%s) op:%d error(%v)", msg.ch.Key, msg.p.Op, err)
+		}
+		return nil
+	}
+	reply = &protocol.Proto{Ver: msg.p.Ver, Seq: msg.p.Seq}
+	switch msg.p.Op {
+	case protocol.OpHistory:
+		reply.Op = protocol.OpHistoryReply
+		reply.Body, err = s.History(ctx, msg.ch, msg.p)
+	case protocol.OpRead:
+		reply.Op = protocol.OpReadReply
+		err = s.Receive(ctx, msg.ch, msg.p)
+	default:
+		reply.Op = protocol.OpSendMsgReply
+		err = s.Receive(ctx, msg.ch, msg.p)
+	}
+	if err != nil {
+		log.Error("upstream(%d) op:%d error(%v)", msg.ch.Mid, msg.p.Op, err)
+		reply.Body = []byte(status.Convert(err).Message())
+	}
+	return
+}
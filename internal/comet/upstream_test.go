@@ -0,0 +1,140 @@
+package comet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/comet/conf"
+	xtime "github.com/ningchengzeng/goim/pkg/time"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// testLogicClient is a logic client, the rpcs not overridden panic.
+type testLogicClient struct {
+	logic.LogicClient
+	receive chan *logic.ReceiveReq
+	signal  chan *logic.SignalReq
+	release chan struct{} // Receive returns after released if not nil
+	events  func(ctx context.Context) (logic.Logic_EventsClient, error)
+}
+
+func (c *testLogicClient) Receive(ctx context.Context, req *logic.ReceiveReq, opts ...grpc.CallOption) (*logic.ReceiveReply, error) {
+	c.receive <- req
+	if c.release != nil {
+		<-c.release
+	}
+	if string(req.Proto.Body) == "bad" {
+		return nil, errors.New("bad message")
+	}
+	return &logic.ReceiveReply{}, nil
+}
+
+func (c *testLogicClient) Signal(ctx context.Context, req *logic.SignalReq, opts ...grpc.CallOption) (*logic.SignalReply, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, errors.New("no deadline")
+	}
+	c.signal <- req
+	return &logic.SignalReply{}, nil
+}
+
+func (c *testLogicClient) Disconnect(ctx context.Context, req *logic.DisconnectReq, opts ...grpc.CallOption) (*logic.DisconnectReply, error) {
+	// a logic never replies
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (c *testLogicClient) Events(ctx context.Context, opts ...grpc.CallOption) (logic.Logic_EventsClient, error) {
+	return c.events(ctx)
+}
+
+func TestUpstream(t *testing.T) {
+	var (
+		cli = &testLogicClient{receive: make(chan *logic.ReceiveReq), release: make(chan struct{})}
+		s   = &Server{
+			c:         &conf.Config{Upstream: &conf.Upstream{Timeout: xtime.Duration(time.Second)}, Ephemeral: &conf.Ephemeral{}},
+			rpcClient: cli,
+		}
+		u  = newUpstream(s, 1, 2)
+		ch = NewChannel(1, 10)
+	)
+	ch.Mid = 1
+	ch.Key = "test_key"
+	// the first is sending, two are queued
+	assert.True(t, u.put(ch, &protocol.Proto{Op: protocol.OpSendMsg, Seq: 1, Body: []byte("1")}))
+	req := <-cli.receive
+	assert.Equal(t, int32(1), req.Proto.Seq)
+	assert.True(t, u.put(ch, &protocol.Proto{Op: protocol.OpSendMsg, Seq: 2, Body: []byte("bad")}))
+	assert.True(t, u.put(ch, &protocol.Proto{Op: protocol.OpRead, Seq: 3, Body: []byte("3")}))
+	assert.False(t, u.put(ch, &protocol.Proto{Op: protocol.OpSendMsg, Seq: 4}))
+	// the messages of a channel are sent in order
+	cli.release <- struct{}{}
+	for seq := int32(2); seq <= 3; seq++ {
+		req = <-cli.receive
+		assert.Equal(t, seq, req.Proto.Seq)
+		cli.release <- struct{}{}
+	}
+	for _, want := range []*protocol.Proto{
+		{Op: protocol.OpSendMsgReply, Seq: 1},
+		{Op: protocol.OpSendMsgReply, Seq: 2, Body: []byte("bad message")},
+		{Op: protocol.OpReadReply, Seq: 3},
+	} {
+		reply := <-ch.signal
+		assert.Equal(t, want.Op, reply.Op)
+		assert.Equal(t, want.Seq, reply.Seq)
+		assert.Equal(t, want.Body, reply.Body)
+	}
+	u.close()
+	assert.False(t, u.put(ch, &protocol.Proto{Op: protocol.OpSendMsg, Seq: 5}))
+}
+
+func TestReceiveAsyncCopy(t *testing.T) {
+	var (
+		cli = &testLogicClient{receive: make(chan *logic.ReceiveReq, 1)}
+		s   = &Server{
+			c:         &conf.Config{Upstream: &conf.Upstream{Timeout: xtime.Duration(time.Second)}, Ephemeral: &conf.Ephemeral{}},
+			rpcClient: cli,
+		}
+		ch  = NewChannel(1, 10)
+		buf = []byte("hello")
+	)
+	s.upstream = newUpstream(s, 1, 1)
+	assert.True(t, s.ReceiveAsync(ch, &protocol.Proto{Op: protocol.OpSendMsg, Body: buf}))
+	// the reader reuses its buffer
+	copy(buf, "world")
+	req := <-cli.receive
+	assert.Equal(t, []byte("hello"), req.Proto.Body)
+	s.upstream.close()
+}
+
+func TestUpstreamSignal(t *testing.T) {
+	var (
+		cli = &testLogicClient{receive: make(chan *logic.ReceiveReq, 1), signal: make(chan *logic.SignalReq, 1)}
+		s   = &Server{
+			c: &conf.Config{
+				Upstream:  &conf.Upstream{Timeout: xtime.Duration(time.Second)},
+				Ephemeral: &conf.Ephemeral{Ops: []int32{2000}},
+			},
+			rpcClient: cli,
+		}
+		ch = NewChannel(1, 10)
+	)
+	ch.Mid = 1
+	ch.Key = "test_key"
+	s.upstream = newUpstream(s, 1, 2)
+	assert.True(t, s.ReceiveAsync(ch, &protocol.Proto{Op: 2000, Seq: 1, Body: []byte("typing")}))
+	assert.True(t, s.ReceiveAsync(ch, &protocol.Proto{Op: protocol.OpSendMsg, Seq: 2}))
+	req := <-cli.signal
+	assert.Equal(t, int64(1), req.Mid)
+	assert.Equal(t, []byte("typing"), req.Proto.Body)
+	<-cli.receive
+	// a signal has no reply
+	reply := <-ch.signal
+	assert.Equal(t, protocol.OpSendMsgReply, reply.Op)
+	assert.Equal(t, int32(2), reply.Seq)
+	s.upstream.close()
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/bilibili/discovery/naming"
 	"github.com/ningchengzeng/goim/api/comet"
+	"github.com/ningchengzeng/goim/api/logic"
 	"github.com/ningchengzeng/goim/internal/job/conf"
 
 	log "github.com/go-kratos/kratos/pkg/log"
@@ -66,8 +68,18 @@ type Comet struct {
 	roomChanNum   uint64
 	routineSize   uint64
 
-	ctx    context.Context
-	cancel context.CancelFunc
+	// priority lane, served by dedicated goroutines
+	priorityPushChan      chan *comet.PushMsgReq
+	priorityRoomChan      chan *comet.BroadcastRoomReq
+	priorityBroadcastChan chan *comet.BroadcastReq
+
+	// stream pipelines the pushes, and priorityStream the high priority ones, nil if disabled
+	stream         *pushStream
+	priorityStream *pushStream
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	routines sync.WaitGroup
 }
 
 // NewComet new a comet.
@@ -78,6 +90,10 @@ func NewComet(in *naming.Instance, c *conf.Comet) (*Comet, error) {
 		roomChan:      make([]chan *comet.BroadcastRoomReq, c.RoutineSize),
 		broadcastChan: make(chan *comet.BroadcastReq, c.RoutineSize),
 		routineSize:   uint64(c.RoutineSize),
+
+		priorityPushChan:      make(chan *comet.PushMsgReq, c.RoutineChan),
+		priorityRoomChan:      make(chan *comet.BroadcastRoomReq, c.RoutineChan),
+		priorityBroadcastChan: make(chan *comet.BroadcastReq, c.PriorityRoutineSize),
 	}
 	var grpcAddr string
 	for _, addrs := range in.Addrs {
@@ -94,17 +110,30 @@ func NewComet(in *naming.Instance, c *conf.Comet) (*Comet, error) {
 		return nil, err
 	}
 	cmt.ctx, cmt.cancel = context.WithCancel(context.Background())
+	if c.Stream {
+		cmt.stream = newPushStream(cmt.ctx, cmt.serverID, cmt.client, c.Window, cmt.unary)
+		cmt.priorityStream = newPushStream(cmt.ctx, cmt.serverID, cmt.client, c.PriorityWindow, cmt.unary)
+	}
 
 	for i := 0; i < c.RoutineSize; i++ {
 		cmt.pushChan[i] = make(chan *comet.PushMsgReq, c.RoutineChan)
 		cmt.roomChan[i] = make(chan *comet.BroadcastRoomReq, c.RoutineChan)
-		go cmt.process(cmt.pushChan[i], cmt.roomChan[i], cmt.broadcastChan)
+		cmt.routines.Add(1)
+		go cmt.process(cmt.stream, cmt.pushChan[i], cmt.roomChan[i], cmt.broadcastChan)
+	}
+	for i := 0; i < c.PriorityRoutineSize; i++ {
+		cmt.routines.Add(1)
+		go cmt.process(cmt.priorityStream, cmt.priorityPushChan, cmt.priorityRoomChan, cmt.priorityBroadcastChan)
 	}
 	return cmt, nil
 }
 
 // Push push a user message.
 func (c *Comet) Push(arg *comet.PushMsgReq) (err error) {
+	if logic.PushMsg_Priority(arg.Priority).IsHigh() {
+		c.priorityPushChan <- arg
+		return
+	}
 	idx := atomic.AddUint64(&c.pushChanNum, 1) % c.routineSize
 	c.pushChan[idx] <- arg
 	return
@@ -112,6 +141,10 @@ func (c *Comet) Push(arg *comet.PushMsgReq) (err error) {
 
 // BroadcastRoom broadcast a room message.
 func (c *Comet) BroadcastRoom(arg *comet.BroadcastRoomReq) (err error) {
+	if logic.PushMsg_Priority(arg.Priority).IsHigh() {
+		c.priorityRoomChan <- arg
+		return
+	}
 	idx := atomic.AddUint64(&c.roomChanNum, 1) % c.routineSize
 	c.roomChan[idx] <- arg
 	return
@@ -119,70 +152,111 @@ func (c *Comet) BroadcastRoom(arg *comet.BroadcastRoomReq) (err error) {
 
 // Broadcast broadcast a message.
 func (c *Comet) Broadcast(arg *comet.BroadcastReq) (err error) {
+	if logic.PushMsg_Priority(arg.Priority).IsHigh() {
+		c.priorityBroadcastChan <- arg
+		return
+	}
 	c.broadcastChan <- arg
 	return
 }
 
-func (c *Comet) process(pushChan chan *comet.PushMsgReq, roomChan chan *comet.BroadcastRoomReq, broadcastChan chan *comet.BroadcastReq) {
+func (c *Comet) process(stream *pushStream, pushChan chan *comet.PushMsgReq, roomChan chan *comet.BroadcastRoomReq, broadcastChan chan *comet.BroadcastReq) {
+	defer c.routines.Done()
 	for {
 		select {
 		case broadcastArg := <-broadcastChan:
-			_, err := c.client.Broadcast(context.Background(), &comet.BroadcastReq{
-				Proto:   broadcastArg.Proto,
-				ProtoOp: broadcastArg.ProtoOp,
-				Speed:   broadcastArg.Speed,
-			})
-			if err != nil {
-				log.Error("c.client.Broadcast(%s, reply) serverId:%s error(%v)", broadcastArg, c.serverID, err)
-			}
+			c.send(stream, &comet.PushStreamReq{Broadcast: &comet.BroadcastReq{
+				Proto:    broadcastArg.Proto,
+				ProtoOp:  broadcastArg.ProtoOp,
+				Speed:    broadcastArg.Speed,
+				Priority: broadcastArg.Priority,
+				MsgID:    broadcastArg.MsgID,
+			}})
 		case roomArg := <-roomChan:
-			_, err := c.client.BroadcastRoom(context.Background(), &comet.BroadcastRoomReq{
-				RoomID: roomArg.RoomID,
-				Proto:  roomArg.Proto,
-			})
-			if err != nil {
-				log.Error("c.client.BroadcastRoom(%s, reply) serverId:%s error(%v)", roomArg, c.serverID, err)
-			}
+			c.send(stream, &comet.PushStreamReq{BroadcastRoom: &comet.BroadcastRoomReq{
+				RoomID:   roomArg.RoomID,
+				Proto:    roomArg.Proto,
+				Priority: roomArg.Priority,
+				MsgID:    roomArg.MsgID,
+			}})
 		case pushArg := <-pushChan:
-			_, err := c.client.PushMsg(context.Background(), &comet.PushMsgReq{
-				Keys:    pushArg.Keys,
-				Proto:   pushArg.Proto,
-				ProtoOp: pushArg.ProtoOp,
-			})
-			if err != nil {
-				log.Error("c.client.PushMsg(%s, reply) serverId:%s error(%v)", pushArg, c.serverID, err)
-			}
+			c.send(stream, &comet.PushStreamReq{PushMsg: &comet.PushMsgReq{
+				Keys:     pushArg.Keys,
+				Proto:    pushArg.Proto,
+				ProtoOp:  pushArg.ProtoOp,
+				Priority: pushArg.Priority,
+				MsgID:    pushArg.MsgID,
+			}})
 		case <-c.ctx.Done():
 			return
 		}
 	}
 }
 
-// Close close the resouces.
-func (c *Comet) Close() (err error) {
-	finish := make(chan bool)
-	go func() {
-		for {
-			n := len(c.broadcastChan)
-			for _, ch := range c.pushChan {
-				n += len(ch)
-			}
-			for _, ch := range c.roomChan {
-				n += len(ch)
-			}
-			if n == 0 {
-				finish <- true
-				return
-			}
-			time.Sleep(time.Second)
+// send send a push on the stream, or by the unary rpc if the stream is not available.
+func (c *Comet) send(stream *pushStream, req *comet.PushStreamReq) {
+	if stream != nil && stream.send(c.ctx, req) {
+		return
+	}
+	c.unary(req)
+}
+
+// unary send a push by the unary rpc.
+func (c *Comet) unary(req *comet.PushStreamReq) {
+	switch {
+	case req.Broadcast != nil:
+		if _, err := c.client.Broadcast(context.Background(), req.Broadcast); err != nil {
+			log.Error("c.client.Broadcast(%s, reply) serverId:%s error(%v)", req.Broadcast, c.serverID, err)
 		}
+	case req.BroadcastRoom != nil:
+		if _, err := c.client.BroadcastRoom(context.Background(), req.BroadcastRoom); err != nil {
+			log.Error("c.client.BroadcastRoom(%s, reply) serverId:%s error(%v)", req.BroadcastRoom, c.serverID, err)
+		}
+	case req.PushMsg != nil:
+		if _, err := c.client.PushMsg(context.Background(), req.PushMsg); err != nil {
+			log.Error("c.client.PushMsg(%s, reply) serverId:%s error(%v)", req.PushMsg, c.serverID, err)
+		}
+	}
+}
+
+// Close wait all the pending messages sent to the comet, then stop the process goroutines.
+func (c *Comet) Close(ctx context.Context) (err error) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for c.pending() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			c.cancel()
+			return fmt.Errorf("close comet(server:%s pending:%d) error(%v)", c.serverID, c.pending(), ctx.Err())
+		}
+	}
+	c.cancel()
+	// wait the in-flight rpc finished
+	finish := make(chan struct{})
+	go func() {
+		c.routines.Wait()
+		close(finish)
 	}()
 	select {
 	case <-finish:
-		log.Info("close comet finish")
-	case <-time.After(5 * time.Second):
-		err = fmt.Errorf("close comet(server:%s push:%d room:%d broadcast:%d) timeout", c.serverID, len(c.pushChan), len(c.roomChan), len(c.broadcastChan))
+		log.Info("close comet(server:%s) finish", c.serverID)
+	case <-ctx.Done():
+		err = fmt.Errorf("close comet(server:%s) error(%v)", c.serverID, ctx.Err())
+	}
+	return
+}
+
+func (c *Comet) pending() (n int) {
+	n = len(c.broadcastChan) + len(c.priorityPushChan) + len(c.priorityRoomChan) + len(c.priorityBroadcastChan)
+	for _, ch := range c.pushChan {
+		n += len(ch)
+	}
+	for _, ch := range c.roomChan {
+		n += len(ch)
+	}
+	if c.stream != nil {
+		n += c.stream.pending() + c.priorityStream.pending()
 	}
-	c.cancel()
 	return
 }
@@ -1,6 +1,7 @@
 package conf
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -15,6 +16,9 @@ var (
 	configKey = "job.toml"
 	// Conf config
 	Conf = &Config{}
+	// room is the snapshot of Conf.Room on every load, the pushes read it
+	// instead of Conf which is overwritten on reload.
+	room atomic.Value
 )
 
 // Init init config.
@@ -32,6 +36,7 @@ type Config struct {
 	Nsq       *Nsq
 	Comet     *Comet
 	Room      *Room
+	Shutdown  *Shutdown
 	Log       *log.Config
 }
 
@@ -45,19 +50,64 @@ type Room struct {
 	Batch  int
 	Signal xtime.Duration
 	Idle   xtime.Duration
+	// Priority operations skip the batch and are pushed at once.
+	Priority []int32
+	// Types override the batch policy by room type, eg: "live" for "live://1000".
+	Types map[string]*Room
+}
+
+// Policy get the batch policy of the room type.
+func (r *Room) Policy(typ string) *Room {
+	if t, ok := r.Types[typ]; ok {
+		return t
+	}
+	return r
+}
+
+// RoomPolicy get the batch policy of the room type from the last loaded config.
+func RoomPolicy(typ string) *Room {
+	if r, ok := room.Load().(*Room); ok {
+		return r.Policy(typ)
+	}
+	return Conf.Room.Policy(typ)
+}
+
+// IsPriority verify if the operation skips the batch.
+func (r *Room) IsPriority(op int32) bool {
+	for _, p := range r.Priority {
+		if p == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Shutdown is graceful shutdown config.
+type Shutdown struct {
+	Timeout xtime.Duration
 }
 
 // Comet is comet config.
 type Comet struct {
 	RoutineChan int
 	RoutineSize int
+	// PriorityRoutineSize goroutines only serve the high priority messages.
+	PriorityRoutineSize int
+	// Stream pipelines the pushes on a stream, the comets without it fall back to the unary rpcs.
+	Stream bool
+	// Window is the max pushes on the stream waiting for the acks,
+	// the high priority pushes have their own stream of PriorityWindow.
+	Window         int
+	PriorityWindow int
 }
 
 // Nsq is kafka config.
 type Nsq struct {
-	Topic   string
-	Channel string
-	Address []string
+	Topic string
+	// PriorityTopic carries the high priority messages, consumed separately.
+	PriorityTopic string
+	Channel       string
+	Address       []string
 }
 
 // Env is env config.
@@ -103,6 +153,15 @@ func (c *Comet) fix() (err error) {
 	if c.RoutineSize == 0 {
 		c.RoutineSize = 32
 	}
+	if c.PriorityRoutineSize == 0 {
+		c.PriorityRoutineSize = 4
+	}
+	if c.Window == 0 {
+		c.Window = 1024
+	}
+	if c.PriorityWindow == 0 {
+		c.PriorityWindow = 256
+	}
 	return
 }
 
@@ -116,6 +175,28 @@ func (r *Room) fix() (err error) {
 	if r.Idle == 0 {
 		r.Idle = xtime.Duration(time.Minute * 15)
 	}
+	// room type inherits the unset fields
+	for _, t := range r.Types {
+		if t.Batch == 0 {
+			t.Batch = r.Batch
+		}
+		if t.Signal == 0 {
+			t.Signal = r.Signal
+		}
+		if t.Idle == 0 {
+			t.Idle = r.Idle
+		}
+		if t.Priority == nil {
+			t.Priority = r.Priority
+		}
+	}
+	return
+}
+
+func (s *Shutdown) fix() (err error) {
+	if s.Timeout == 0 {
+		s.Timeout = xtime.Duration(time.Second * 20)
+	}
 	return
 }
 
@@ -128,7 +209,7 @@ func (c *Config) fix() (err error) {
 	}
 
 	if c.Comet == nil {
-		c.Comet = &Comet{RoutineChan: 1024, RoutineSize: 32}
+		c.Comet = &Comet{RoutineChan: 1024, RoutineSize: 32, PriorityRoutineSize: 4}
 	}
 	if err = c.Comet.fix(); err != nil {
 		return
@@ -145,6 +226,13 @@ func (c *Config) fix() (err error) {
 		return
 	}
 
+	if c.Shutdown == nil {
+		c.Shutdown = &Shutdown{Timeout: xtime.Duration(time.Second * 20)}
+	}
+	if err = c.Shutdown.fix(); err != nil {
+		return
+	}
+
 	return
 }
 
@@ -158,6 +246,7 @@ func (c *Config) Set(content string) (err error) {
 	if err = tmpConf.fix(); err != nil {
 		return
 	}
+	room.Store(tmpConf.Room)
 	*Conf = *tmpConf
 	return nil
 }
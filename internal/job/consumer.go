@@ -21,6 +21,11 @@ type NsqHandler struct {
 
 // HandleMessage 处理消息
 func (nh *NsqHandler) HandleMessage(msg *nsq.Message) error {
+	if nh.j.Closed() {
+		// job is closing, give the message back to nsq instead of losing it
+		msg.RequeueWithoutBackoff(0)
+		return nil
+	}
 	pushMsg := new(pb.PushMsg)
 	if err := proto.Unmarshal(msg.Body, pushMsg); err != nil {
 		log.Error("proto.Unmarshal(%v) error(%v)", msg, err)
@@ -30,34 +35,40 @@ func (nh *NsqHandler) HandleMessage(msg *nsq.Message) error {
 		log.Error("j.push(%v) error(%v)", pushMsg, err)
 		return err
 	}
-	log.Info("consume: %s/%d/%d\t%s\t%+v", nh.topic, nh.channel, msg.NSQDAddress, msg.ID, pushMsg)
+	log.Info("consume: %s/%s/%s\t%s\t%+v", nh.topic, nh.channel, msg.NSQDAddress, msg.ID, pushMsg)
 	msg.Finish()
 	return nil
 }
 
-// Close 关闭接受
-func (nh *NsqHandler) Close() error {
-	if nh.consumer != nil {
-		nh.consumer.Stop()
+// Close stop the consumer and wait for the in-flight messages handled.
+func (nh *NsqHandler) Close(ctx context.Context) error {
+	if nh.consumer == nil {
+		return nil
+	}
+	nh.consumer.Stop()
+	select {
+	case <-nh.consumer.StopChan:
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 	return nil
 }
 
 // NewNsqConsumer 创建 nsq consumer
-func NewNsqConsumer(cnsq *conf.Nsq, job *Job) (*NsqHandler, error) {
+func NewNsqConsumer(cnsq *conf.Nsq, topic string, job *Job) (*NsqHandler, error) {
 	cfg := nsq.NewConfig()
 	cfg.LookupdPollInterval = 3 * time.Second
-	c, err := nsq.NewConsumer(cnsq.Topic, cnsq.Channel, cfg)
+	c, err := nsq.NewConsumer(topic, cnsq.Channel, cfg)
 	if err != nil {
-		log.Error("init Consumer NewConsumer error:", err)
+		log.Error("init Consumer NewConsumer error(%v)", err)
 		return nil, err
 	}
 
-	handler := &NsqHandler{consumer: c, j: job}
+	handler := &NsqHandler{consumer: c, topic: topic, channel: cnsq.Channel, j: job}
 	c.AddHandler(handler)
 	err = c.ConnectToNSQLookupds(cnsq.Address)
 	if err != nil {
-		log.Error("init Consumer ConnectToNSQLookupd error:", err)
+		log.Error("init Consumer ConnectToNSQLookupd error(%v)", err)
 		return nil, err
 	}
 	return handler, nil
@@ -1,8 +1,10 @@
 package job
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bilibili/discovery/naming"
@@ -14,10 +16,14 @@ import (
 // Job is push job.
 type Job struct {
 	c            *conf.Config
+	consumers    []*NsqHandler
 	cometServers map[string]*Comet
+	cometsMutex  sync.RWMutex
 
 	rooms      map[string]*Room
 	roomsMutex sync.RWMutex
+
+	closed int32
 }
 
 // New new a push job.
@@ -27,12 +33,48 @@ func New(c *conf.Config) *Job {
 		rooms: make(map[string]*Room),
 	}
 	j.watchComet(c.DiscoveryConfig())
+	topics := []string{c.Nsq.Topic}
+	if c.Nsq.PriorityTopic != "" {
+		// high priority messages never wait behind the normal ones
+		topics = append(topics, c.Nsq.PriorityTopic)
+	}
+	for _, topic := range topics {
+		consumer, err := NewNsqConsumer(c.Nsq, topic, j)
+		if err != nil {
+			panic(err)
+		}
+		j.consumers = append(j.consumers, consumer)
+	}
 	return j
 }
 
 // Close close resounces.
-func (j *Job) Close() error {
-	return nil
+// The nsq consumer is stopped first, then the merged room buffers are flushed
+// and every comet is drained, the whole process is bounded by Shutdown.Timeout.
+func (j *Job) Close() (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(j.c.Shutdown.Timeout))
+	defer cancel()
+	// messages handled while the consumers are stopping are requeued to nsq
+	atomic.StoreInt32(&j.closed, 1)
+	for _, consumer := range j.consumers {
+		if err = consumer.Close(ctx); err != nil {
+			log.Error("consumer.Close(%s) error(%v)", consumer.topic, err)
+		}
+	}
+	if err = j.closeRooms(ctx); err != nil {
+		log.Error("closeRooms() error(%v)", err)
+	}
+	for serverID, c := range j.comets() {
+		if err = c.Close(ctx); err != nil {
+			log.Error("comet.Close() serverID:%s error(%v)", serverID, err)
+		}
+	}
+	return
+}
+
+// Closed return true if the job is closing.
+func (j *Job) Closed() bool {
+	return atomic.LoadInt32(&j.closed) == 1
 }
 
 func (j *Job) watchComet(c *naming.Config) {
@@ -76,9 +118,10 @@ func (j *Job) newAddress(insMap map[string][]*naming.Instance) error {
 	if len(ins) == 0 {
 		return fmt.Errorf("watchComet instance is empty")
 	}
+	olds := j.comets()
 	comets := map[string]*Comet{}
 	for _, in := range ins {
-		if old, ok := j.cometServers[in.Hostname]; ok {
+		if old, ok := olds[in.Hostname]; ok {
 			comets[in.Hostname] = old
 			continue
 		}
@@ -90,12 +133,22 @@ func (j *Job) newAddress(insMap map[string][]*naming.Instance) error {
 		comets[in.Hostname] = c
 		log.Info("watchComet AddComet grpc:%+v", in)
 	}
-	for key, old := range j.cometServers {
+	for key, old := range olds {
 		if _, ok := comets[key]; !ok {
 			old.cancel()
 			log.Info("watchComet DelComet:%s", key)
 		}
 	}
+	j.cometsMutex.Lock()
 	j.cometServers = comets
+	j.cometsMutex.Unlock()
 	return nil
 }
+
+// comets get the comets, the map is replaced on change and never modified.
+func (j *Job) comets() (comets map[string]*Comet) {
+	j.cometsMutex.RLock()
+	comets = j.cometServers
+	j.cometsMutex.RUnlock()
+	return
+}
@@ -14,11 +14,17 @@ import (
 func (j *Job) push(ctx context.Context, pushMsg *pb.PushMsg) (err error) {
 	switch pushMsg.Type {
 	case pb.PushMsg_PUSH:
-		err = j.pushKeys(pushMsg.Operation, pushMsg.Server, pushMsg.Keys, pushMsg.Msg)
+		err = j.pushKeys(pushMsg.Operation, pushMsg.Priority, pushMsg.MsgID, pushMsg.Server, pushMsg.Keys, pushMsg.Msg)
 	case pb.PushMsg_ROOM:
-		err = j.getRoom(pushMsg.Room).Push(pushMsg.Operation, pushMsg.Msg)
+		err = j.pushRoom(pushMsg.Room, pushMsg.Operation, pushMsg.Priority, pushMsg.MsgID, pushMsg.Msg)
 	case pb.PushMsg_BROADCAST:
-		err = j.broadcast(pushMsg.Operation, pushMsg.Msg, pushMsg.Speed)
+		err = j.broadcast(pushMsg.Operation, pushMsg.Priority, pushMsg.MsgID, pushMsg.Msg, pushMsg.Speed)
+	case pb.PushMsg_BATCH:
+		for _, item := range pushMsg.Items {
+			if err = j.pushKeys(item.Operation, pushMsg.Priority, item.MsgID, pushMsg.Server, item.Keys, item.Msg); err != nil {
+				return
+			}
+		}
 	default:
 		err = fmt.Errorf("no match push type: %s", pushMsg.Type)
 	}
@@ -26,7 +32,7 @@ func (j *Job) push(ctx context.Context, pushMsg *pb.PushMsg) (err error) {
 }
 
 // pushKeys push a message to a batch of subkeys.
-func (j *Job) pushKeys(operation int32, serverID string, subKeys []string, body []byte) (err error) {
+func (j *Job) pushKeys(operation int32, priority pb.PushMsg_Priority, msgID, serverID string, subKeys []string, body []byte) (err error) {
 	buf := bytes.NewWriterSize(len(body) + 64)
 	p := &protocol.Proto{
 		Ver:  1,
@@ -37,21 +43,24 @@ func (j *Job) pushKeys(operation int32, serverID string, subKeys []string, body
 	p.Body = buf.Buffer()
 	p.Op = protocol.OpRaw
 	var args = comet.PushMsgReq{
-		Keys:    subKeys,
-		ProtoOp: operation,
-		Proto:   p,
+		Keys:     subKeys,
+		ProtoOp:  operation,
+		Proto:    p,
+		Priority: int32(priority),
+		MsgID:    msgID,
 	}
-	if c, ok := j.cometServers[serverID]; ok {
+	comets := j.comets()
+	if c, ok := comets[serverID]; ok {
 		if err = c.Push(&args); err != nil {
 			log.Error("c.Push(%v) serverID:%s error(%v)", args, serverID, err)
 		}
-		log.Info("pushKey:%s comets:%d", serverID, len(j.cometServers))
+		log.Info("pushKey:%s comets:%d", serverID, len(comets))
 	}
 	return
 }
 
 // broadcast broadcast a message to all.
-func (j *Job) broadcast(operation int32, body []byte, speed int32) (err error) {
+func (j *Job) broadcast(operation int32, priority pb.PushMsg_Priority, msgID string, body []byte, speed int32) (err error) {
 	buf := bytes.NewWriterSize(len(body) + 64)
 	p := &protocol.Proto{
 		Ver:  1,
@@ -61,12 +70,14 @@ func (j *Job) broadcast(operation int32, body []byte, speed int32) (err error) {
 	p.WriteTo(buf)
 	p.Body = buf.Buffer()
 	p.Op = protocol.OpRaw
-	comets := j.cometServers
+	comets := j.comets()
 	speed /= int32(len(comets))
 	var args = comet.BroadcastReq{
-		ProtoOp: operation,
-		Proto:   p,
-		Speed:   speed,
+		ProtoOp:  operation,
+		Proto:    p,
+		Speed:    speed,
+		Priority: int32(priority),
+		MsgID:    msgID,
 	}
 	for serverID, c := range comets {
 		if err = c.Broadcast(&args); err != nil {
@@ -77,17 +88,32 @@ func (j *Job) broadcast(operation int32, body []byte, speed int32) (err error) {
 	return
 }
 
-// broadcastRoomRawBytes broadcast aggregation messages to room.
-func (j *Job) broadcastRoomRawBytes(roomID string, body []byte) (err error) {
+// broadcastRoom broadcast a single message to room without aggregation.
+func (j *Job) broadcastRoom(roomID string, operation int32, priority pb.PushMsg_Priority, msgID string, body []byte) (err error) {
+	buf := bytes.NewWriterSize(len(body) + 64)
+	p := &protocol.Proto{
+		Ver:  1,
+		Op:   operation,
+		Body: body,
+	}
+	p.WriteTo(buf)
+	return j.broadcastRoomRawBytes(roomID, priority, msgID, buf.Buffer())
+}
+
+// broadcastRoomRawBytes broadcast aggregation messages to room, msgID is
+// the batch id for the aggregated ones.
+func (j *Job) broadcastRoomRawBytes(roomID string, priority pb.PushMsg_Priority, msgID string, body []byte) (err error) {
 	args := comet.BroadcastRoomReq{
-		RoomID: roomID,
+		RoomID:   roomID,
+		Priority: int32(priority),
+		MsgID:    msgID,
 		Proto: &protocol.Proto{
 			Ver:  1,
 			Op:   protocol.OpRaw,
 			Body: body,
 		},
 	}
-	comets := j.cometServers
+	comets := j.comets()
 	for serverID, c := range comets {
 		if err = c.BroadcastRoom(&args); err != nil {
 			log.Error("c.BroadcastRoom(%v) roomID:%s serverID:%s error(%v)", args, roomID, serverID, err)
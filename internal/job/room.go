@@ -1,13 +1,18 @@
 package job
 
 import (
+	"context"
 	"errors"
+	"strconv"
+	"sync"
 	"time"
 
 	log "github.com/go-kratos/kratos/pkg/log"
+	pb "github.com/ningchengzeng/goim/api/logic"
 	"github.com/ningchengzeng/goim/api/protocol"
 	"github.com/ningchengzeng/goim/internal/job/conf"
 	"github.com/ningchengzeng/goim/pkg/bytes"
+	"github.com/ningchengzeng/goim/pkg/room"
 )
 
 var (
@@ -19,28 +24,40 @@ var (
 	ErrRoomFull = errors.New("room proto chan full")
 
 	roomReadyProto = new(protocol.Proto)
+	// batchNonce makes the batch ids of the job differ from the ones of the others and the restarted.
+	batchNonce = strconv.FormatInt(time.Now().UnixNano(), 36)
 )
 
 // Room room.
 type Room struct {
-	c     *conf.Room
 	job   *Job
 	id    string
+	typ   string
 	proto chan *protocol.Proto
+	done  chan struct{}
+	batch int64 // the sequence of the batches, only used by pushproc
 }
 
 // NewRoom new a room struct, store channel room info.
-func NewRoom(job *Job, id string, c *conf.Room) (r *Room) {
+func NewRoom(job *Job, id string) (r *Room) {
+	typ, _, _ := room.DecodeKey(id)
 	r = &Room{
-		c:     c,
 		id:    id,
+		typ:   typ,
 		job:   job,
-		proto: make(chan *protocol.Proto, c.Batch*2),
+		proto: make(chan *protocol.Proto, conf.RoomPolicy(typ).Batch*2),
+		done:  make(chan struct{}),
 	}
-	go r.pushproc(c.Batch, time.Duration(c.Signal))
+	go r.pushproc()
 	return
 }
 
+// policy get the batch policy of the room, always read from the config
+// snapshot so that it follows the hot reloaded config.
+func (r *Room) policy() *conf.Room {
+	return conf.RoomPolicy(r.typ)
+}
+
 // Push push msg to the room, if chan full discard it.
 func (r *Room) Push(op int32, msg []byte) (err error) {
 	var p = &protocol.Proto{
@@ -56,13 +73,39 @@ func (r *Room) Push(op int32, msg []byte) (err error) {
 	return
 }
 
+// Close flush the merged buffer and wait the room goroutine exit.
+func (r *Room) Close(ctx context.Context) error {
+	select {
+	case r.proto <- nil:
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// batchID get the message id of the next batch, so that the comet drops a batch sent again.
+func (r *Room) batchID() string {
+	r.batch++
+	return "batch-" + batchNonce + "-" + strconv.FormatInt(r.batch, 10)
+}
+
 // pushproc merge proto and push msgs in batch.
-func (r *Room) pushproc(batch int, sigTime time.Duration) {
+func (r *Room) pushproc() {
 	var (
-		n    int
-		last time.Time
-		p    *protocol.Proto
-		buf  = bytes.NewWriterSize(int(protocol.MaxBodySize))
+		n       int
+		last    time.Time
+		p       *protocol.Proto
+		c       = r.policy()
+		batch   = c.Batch
+		sigTime = time.Duration(c.Signal)
+		buf     = bytes.NewWriterSize(int(protocol.MaxBodySize))
 	)
 	log.Info("start room:%s goroutine", r.id)
 	td := time.AfterFunc(sigTime, func() {
@@ -74,14 +117,23 @@ func (r *Room) pushproc(batch int, sigTime time.Duration) {
 	defer td.Stop()
 	for {
 		if p = <-r.proto; p == nil {
+			// closing, flush the pending merged buffer
+			if n > 0 {
+				_ = r.job.broadcastRoomRawBytes(r.id, pb.PushMsg_NORMAL, r.batchID(), buf.Buffer())
+			}
 			break // exit
 		} else if p != roomReadyProto {
 			// merge buffer ignore error, always nil
 			p.WriteTo(buf)
 			if n++; n == 1 {
-				last = time.Now()
-				td.Reset(sigTime)
-				continue
+				// a new batch, take the latest policy
+				c = r.policy()
+				batch, sigTime = c.Batch, time.Duration(c.Signal)
+				if n < batch {
+					last = time.Now()
+					td.Reset(sigTime)
+					continue
+				}
 			} else if n < batch {
 				if sigTime > time.Since(last) {
 					continue
@@ -92,21 +144,58 @@ func (r *Room) pushproc(batch int, sigTime time.Duration) {
 				break
 			}
 		}
-		_ = r.job.broadcastRoomRawBytes(r.id, buf.Buffer())
+		_ = r.job.broadcastRoomRawBytes(r.id, pb.PushMsg_NORMAL, r.batchID(), buf.Buffer())
 		// TODO use reset buffer
 		// after push to room channel, renew a buffer, let old buffer gc
 		buf = bytes.NewWriterSize(buf.Size())
 		n = 0
-		if r.c.Idle != 0 {
-			td.Reset(time.Duration(r.c.Idle))
+		if c.Idle != 0 {
+			td.Reset(time.Duration(c.Idle))
 		} else {
 			td.Reset(time.Minute)
 		}
 	}
 	r.job.delRoom(r.id)
+	close(r.done)
 	log.Info("room:%s goroutine exit", r.id)
 }
 
+// closeRooms flush all the active rooms.
+func (j *Job) closeRooms(ctx context.Context) (err error) {
+	j.roomsMutex.RLock()
+	rooms := make([]*Room, 0, len(j.rooms))
+	for _, room := range j.rooms {
+		rooms = append(rooms, room)
+	}
+	j.roomsMutex.RUnlock()
+	var wg sync.WaitGroup
+	for _, room := range rooms {
+		wg.Add(1)
+		go func(room *Room) {
+			defer wg.Done()
+			if err := room.Close(ctx); err != nil {
+				log.Error("room.Close() roomID:%s error(%v)", room.id, err)
+			}
+		}(room)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// pushRoom push a message to the room, the message skips the batch if it is
+// high priority, or the policy of the room type disables the batch or marks
+// its operation as priority.
+func (j *Job) pushRoom(roomID string, op int32, priority pb.PushMsg_Priority, msgID string, msg []byte) (err error) {
+	if priority.IsHigh() {
+		return j.broadcastRoom(roomID, op, priority, msgID, msg)
+	}
+	typ, _, _ := room.DecodeKey(roomID)
+	if c := conf.RoomPolicy(typ); c.Batch <= 1 || c.IsPriority(op) {
+		return j.broadcastRoom(roomID, op, priority, msgID, msg)
+	}
+	return j.getRoom(roomID).Push(op, msg)
+}
+
 func (j *Job) delRoom(roomID string) {
 	j.roomsMutex.Lock()
 	delete(j.rooms, roomID)
@@ -120,7 +209,7 @@ func (j *Job) getRoom(roomID string) *Room {
 	if !ok {
 		j.roomsMutex.Lock()
 		if room, ok = j.rooms[roomID]; !ok {
-			room = NewRoom(j, roomID, j.c.Room)
+			room = NewRoom(j, roomID)
 			j.rooms[roomID] = room
 		}
 		j.roomsMutex.Unlock()
@@ -0,0 +1,215 @@
+package job
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/comet"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	_streamRetry = time.Second
+)
+
+// pushStream pipelines the pushes to a comet on a stream, at most window pushes wait for the acks,
+// and they are written by one writer goroutine.
+// The pushes not acked when the stream broken or acked with an error are sent again by the unary rpcs
+// in order, only the ones with a message id are, so that the comet drops them if already delivered.
+// A comet without the stream always gets the unary rpcs.
+type pushStream struct {
+	serverID string
+	client   comet.CometClient
+	unary    func(req *comet.PushStreamReq)
+	window   chan struct{}
+	retries  chan *comet.PushStreamReq // the pushes to send again, full drops them
+
+	mutex    sync.Mutex
+	reqs     chan *comet.PushStreamReq // the writer queue, nil if not connected
+	seq      int64
+	inflight map[int64]*comet.PushStreamReq
+}
+
+func newPushStream(ctx context.Context, serverID string, client comet.CometClient, window int, unary func(req *comet.PushStreamReq)) *pushStream {
+	ps := &pushStream{
+		serverID: serverID,
+		client:   client,
+		unary:    unary,
+		window:   make(chan struct{}, window),
+		retries:  make(chan *comet.PushStreamReq, window),
+		inflight: make(map[int64]*comet.PushStreamReq),
+	}
+	go ps.streamproc(ctx)
+	go ps.retryproc(ctx)
+	return ps
+}
+
+// streamproc keep the stream connected and handle the acks.
+func (ps *pushStream) streamproc(ctx context.Context) {
+	for {
+		sctx, cancel := context.WithCancel(ctx)
+		stream, err := ps.client.PushStream(sctx)
+		if err == nil {
+			// the queue never blocks, as the pushes in it are within the window
+			reqs := make(chan *comet.PushStreamReq, cap(ps.window))
+			ps.mutex.Lock()
+			ps.reqs = reqs
+			ps.mutex.Unlock()
+			go ps.writeproc(sctx, cancel, stream, reqs)
+			for {
+				var ack *comet.PushStreamAck
+				if ack, err = stream.Recv(); err != nil {
+					break
+				}
+				ps.ack(ack)
+			}
+		}
+		cancel()
+		ps.reset(ctx)
+		if status.Code(err) == codes.Unimplemented {
+			log.Warn("comet(server:%s) doesn't support the push stream, use the unary rpcs", ps.serverID)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error("comet(server:%s) push stream error(%v)", ps.serverID, err)
+		time.Sleep(_streamRetry)
+	}
+}
+
+// writeproc write the queued pushes on the stream until it's broken,
+// then the pushes not acked are sent by reset.
+func (ps *pushStream) writeproc(ctx context.Context, cancel context.CancelFunc, stream comet.Comet_PushStreamClient, reqs chan *comet.PushStreamReq) {
+	for {
+		select {
+		case req := <-reqs:
+			if err := stream.Send(req); err != nil {
+				log.Error("comet(server:%s) push stream.Send(%d) error(%v)", ps.serverID, req.Seq, err)
+				// the reader gets the error and resets
+				cancel()
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// send queue the push to the writer, it blocks if the window is full,
+// ok is false if the stream is not connected, then the caller uses the unary rpc.
+func (ps *pushStream) send(ctx context.Context, req *comet.PushStreamReq) (ok bool) {
+	ps.mutex.Lock()
+	ok = ps.reqs != nil
+	ps.mutex.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case ps.window <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	if ps.reqs == nil {
+		<-ps.window
+		return false
+	}
+	ps.seq++
+	req.Seq = ps.seq
+	ps.inflight[req.Seq] = req
+	ps.reqs <- req
+	return true
+}
+
+// ack release the window of the acked push, a push acked with an error is retried by the unary rpc.
+func (ps *pushStream) ack(ack *comet.PushStreamAck) {
+	ps.mutex.Lock()
+	req, ok := ps.inflight[ack.Seq]
+	delete(ps.inflight, ack.Seq)
+	ps.mutex.Unlock()
+	if !ok {
+		return
+	}
+	<-ps.window
+	if ack.Error != "" {
+		log.Error("comet(server:%s) push stream ack(%d) error(%s), retry it", ps.serverID, ack.Seq, ack.Error)
+		ps.retry(nil, req)
+	}
+}
+
+// retry queue the push to send again, it blocks until queued if ctx is not nil, or drops it if the queue is full.
+// A push without the message id is dropped, as the comet can't tell whether it was delivered.
+func (ps *pushStream) retry(ctx context.Context, req *comet.PushStreamReq) {
+	if pushMsgID(req) == "" {
+		log.Error("comet(server:%s) push(%d) without msgID, drop it", ps.serverID, req.Seq)
+		return
+	}
+	if ctx == nil {
+		select {
+		case ps.retries <- req:
+		default:
+			log.Error("comet(server:%s) push retry queue is full, drop push(%d)", ps.serverID, req.Seq)
+		}
+		return
+	}
+	select {
+	case ps.retries <- req:
+	case <-ctx.Done():
+	}
+}
+
+// retryproc send the queued pushes by the unary rpcs in order.
+func (ps *pushStream) retryproc(ctx context.Context) {
+	for {
+		select {
+		case req := <-ps.retries:
+			ps.unary(req)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reset close the stream, the pushes not acked are queued to send again in order.
+func (ps *pushStream) reset(ctx context.Context) {
+	ps.mutex.Lock()
+	ps.reqs = nil
+	reqs := make([]*comet.PushStreamReq, 0, len(ps.inflight))
+	for _, req := range ps.inflight {
+		reqs = append(reqs, req)
+	}
+	ps.inflight = make(map[int64]*comet.PushStreamReq)
+	ps.mutex.Unlock()
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Seq < reqs[j].Seq })
+	for _, req := range reqs {
+		<-ps.window
+		ps.retry(ctx, req)
+	}
+}
+
+// pending get the number of the pushes waiting for the acks or the retries.
+func (ps *pushStream) pending() int {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	return len(ps.inflight) + len(ps.retries)
+}
+
+// pushMsgID get the message id of the push.
+func pushMsgID(req *comet.PushStreamReq) string {
+	switch {
+	case req.PushMsg != nil:
+		return req.PushMsg.MsgID
+	case req.BroadcastRoom != nil:
+		return req.BroadcastRoom.MsgID
+	case req.Broadcast != nil:
+		return req.Broadcast.MsgID
+	}
+	return ""
+}
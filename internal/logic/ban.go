@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/comet"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+// ErrBanArg ban arg error.
+var ErrBanArg = errors.New("ban ips and mids are empty")
+
+// Ban ban the ips and mids on all the comets for ttl, zero ttl is forever.
+// The bans are stored, so that the comets started later get them too.
+func (l *Logic) Ban(c context.Context, ips []string, mids []int64, ttl time.Duration) (err error) {
+	if len(ips) == 0 && len(mids) == 0 {
+		return ErrBanArg
+	}
+	var expire int64
+	if ttl > 0 {
+		expire = time.Now().Add(ttl).Unix()
+	}
+	var bans []*model.Ban
+	for _, ip := range ips {
+		bans = append(bans, &model.Ban{IP: ip, Expire: expire})
+	}
+	for _, mid := range mids {
+		bans = append(bans, &model.Ban{Mid: mid, Expire: expire})
+	}
+	if err = l.dao.AddBans(c, bans); err != nil {
+		return
+	}
+	req := &comet.BanReq{Bans: cometBans(bans)}
+	l.eachComet(c, nil, func(ctx context.Context, server string, cc *cometClient) (err error) {
+		if _, err = cc.admin.Ban(ctx, req); err != nil {
+			log.Error("comet Ban(%d bans) server:%s error(%v)", len(bans), server, err)
+		}
+		return
+	})
+	return
+}
+
+// Unban remove the bans of the ips and mids on all the comets.
+func (l *Logic) Unban(c context.Context, ips []string, mids []int64) (err error) {
+	if err = l.dao.DelBans(c, ips, mids); err != nil {
+		return
+	}
+	req := &comet.UnbanReq{Ips: ips, Mids: mids}
+	l.eachComet(c, nil, func(ctx context.Context, server string, cc *cometClient) (err error) {
+		if _, err = cc.admin.Unban(ctx, req); err != nil {
+			log.Error("comet Unban(%v,%v) server:%s error(%v)", ips, mids, server, err)
+		}
+		return
+	})
+	return
+}
+
+// Bans get all the bans.
+func (l *Logic) Bans(c context.Context) ([]*model.Ban, error) {
+	return l.dao.Bans(c)
+}
+
+// syncBans push the stored bans to a new comet.
+func (l *Logic) syncBans(server string, admin comet.CometAdminClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(l.c.RPCClient.Timeout))
+	defer cancel()
+	bans, err := l.dao.Bans(ctx)
+	if err != nil || len(bans) == 0 {
+		return
+	}
+	if _, err = admin.Ban(ctx, &comet.BanReq{Bans: cometBans(bans)}); err != nil {
+		log.Error("comet Ban(%d bans) server:%s error(%v)", len(bans), server, err)
+	}
+}
+
+func cometBans(bans []*model.Ban) (res []*comet.Ban) {
+	for _, b := range bans {
+		res = append(res, &comet.Ban{Ip: b.IP, Mid: b.Mid, Expire: b.Expire})
+	}
+	return
+}
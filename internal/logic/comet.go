@@ -0,0 +1,132 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/bilibili/discovery/naming"
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/comet"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// cometClient is a direct grpc client of a comet, used by the paths bypass the job.
+type cometClient struct {
+	conn   *grpc.ClientConn
+	client comet.CometClient
+	admin  comet.CometAdminClient
+}
+
+func newCometClient(in *naming.Instance) (*cometClient, error) {
+	var addr string
+	for _, addrs := range in.Addrs {
+		u, err := url.Parse(addrs)
+		if err == nil && u.Scheme == "grpc" {
+			addr = u.Host
+		}
+	}
+	if addr == "" {
+		return nil, fmt.Errorf("invalid grpc address:%v", in.Addrs)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithInsecure(),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                10 * time.Second,
+			Timeout:             3 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &cometClient{conn: conn, client: comet.NewCometClient(conn), admin: comet.NewCometAdminClient(conn)}, nil
+}
+
+// newComets update the comet clients by the instances, the removed ones are closed.
+func (l *Logic) newComets(ins []*naming.Instance) {
+	l.cometsMutex.RLock()
+	olds := l.comets
+	l.cometsMutex.RUnlock()
+	comets := make(map[string]*cometClient, len(ins))
+	for _, in := range ins {
+		if old, ok := olds[in.Hostname]; ok {
+			comets[in.Hostname] = old
+			continue
+		}
+		c, err := newCometClient(in)
+		if err != nil {
+			log.Error("newCometClient(%+v) error(%v)", in, err)
+			continue
+		}
+		comets[in.Hostname] = c
+		go l.syncBans(in.Hostname, c.admin)
+	}
+	for hostname, old := range olds {
+		if _, ok := comets[hostname]; !ok {
+			old.conn.Close()
+		}
+	}
+	l.cometsMutex.Lock()
+	l.comets = comets
+	l.cometsMutex.Unlock()
+}
+
+// allComets get all the comet clients.
+func (l *Logic) allComets() (res map[string]comet.CometClient) {
+	l.cometsMutex.RLock()
+	res = make(map[string]comet.CometClient, len(l.comets))
+	for server, cc := range l.comets {
+		res[server] = cc.client
+	}
+	l.cometsMutex.RUnlock()
+	return
+}
+
+// eachComet call fn on the comets of servers in parallel, or all the comets if
+// servers is nil, every call has its own timeout under c. failed are the
+// servers not found or fn returned an error.
+func (l *Logic) eachComet(c context.Context, servers []string, fn func(ctx context.Context, server string, cc *cometClient) error) (failed []string) {
+	l.cometsMutex.RLock()
+	comets := make(map[string]*cometClient, len(l.comets))
+	if servers == nil {
+		for server, cc := range l.comets {
+			comets[server] = cc
+		}
+	} else {
+		for _, server := range servers {
+			if cc, ok := l.comets[server]; ok {
+				comets[server] = cc
+			} else {
+				log.Warn("comet server:%s not found", server)
+				failed = append(failed, server)
+			}
+		}
+	}
+	l.cometsMutex.RUnlock()
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+	for server, cc := range comets {
+		wg.Add(1)
+		go func(server string, cc *cometClient) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(c, time.Duration(l.c.RPCClient.Timeout))
+			defer cancel()
+			if err := fn(ctx, server, cc); err != nil {
+				mutex.Lock()
+				failed = append(failed, server)
+				mutex.Unlock()
+			}
+		}(server, cc)
+	}
+	wg.Wait()
+	return
+}
@@ -1,6 +1,10 @@
 package conf
 
 import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -15,8 +19,18 @@ var (
 	configKey = "logic.toml"
 	// Conf config
 	Conf = &Config{}
+
+	reloadsMutex sync.Mutex
+	reloads      []func()
 )
 
+// OnReload add a func called after the config reloaded.
+func OnReload(fn func()) {
+	reloadsMutex.Lock()
+	reloads = append(reloads, fn)
+	reloadsMutex.Unlock()
+}
+
 // Init init config.
 func Init() (err error) {
 	if err = paladin.Init(); err != nil {
@@ -36,6 +50,14 @@ type Config struct {
 	Redis      *Redis
 	Node       *Node
 	Backoff    *Backoff
+	Schedule   *Schedule
+	Dedup      *Dedup
+	Notify     *Notify
+	Presence   *Presence
+	Mongo      *Mongo
+	Moderation *Moderation
+	Filter     *Filter
+	Orphan     *Orphan
 	Regions    map[string][]string
 }
 
@@ -73,6 +95,106 @@ type Backoff struct {
 	Jitter    float32
 }
 
+// Schedule is the delayed push config.
+type Schedule struct {
+	// Store is "redis" or "memory", memory store only for single instance.
+	Store string
+	Tick  xtime.Duration
+	Batch int
+	// Lease is how long a claimed schedule is kept from the others, a schedule
+	// failed to fire is claimed again after it.
+	Lease xtime.Duration
+}
+
+// Dedup is the message id deduplication config.
+type Dedup struct {
+	Window xtime.Duration
+}
+
+// Notify is the offline mobile notification config.
+type Notify struct {
+	Enable bool
+	// Provider is "http" or "mock", http posts to a push gateway of apns and fcm.
+	Provider string
+	URL      string
+	Timeout  xtime.Duration
+	Chan     int
+	// Templates are the payload templates by operation, Default is used if the operation has no template.
+	// The json func writes a value as json, e.g. {{json .Msg}}.
+	Default   string
+	Templates map[string]string
+	// tpls are the parsed templates by operation, the default one by "".
+	tpls map[string]*template.Template
+}
+
+// notifyFuncs are the funcs of the payload templates.
+var notifyFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Template get the parsed payload template of the operation.
+func (n *Notify) Template(op int32) *template.Template {
+	if tpl, ok := n.tpls[strconv.FormatInt(int64(op), 10)]; ok {
+		return tpl
+	}
+	return n.tpls[""]
+}
+
+// Presence is the user presence config.
+type Presence struct {
+	Enable bool
+	// Topic is the nsq topic of the presence change events, empty means not published.
+	Topic string
+	// Friends push the presence changes to the online friends.
+	Friends bool
+	Tick    xtime.Duration
+	Batch   int
+}
+
+// Mongo is the mongodb config of the user data.
+type Mongo struct {
+	URI      string
+	Database string
+	Timeout  xtime.Duration
+}
+
+// Moderation is the message recall and moderation config.
+type Moderation struct {
+	// RecallWindow is how long the sender can recall a message after sent.
+	RecallWindow xtime.Duration
+}
+
+// Orphan is the cleanup config of the sessions left by the dead comets.
+// A comet gone from discovery is stale at once, the pushes skip it, then its
+// sessions are deleted by Batch after Delay if it's still gone.
+type Orphan struct {
+	Enable bool
+	Delay  xtime.Duration
+	Batch  int
+}
+
+// Filter is the content filter config of the upstream and pushed messages,
+// the filter chain is rebuilt after the config reloaded, an invalid rule is skipped.
+type Filter struct {
+	Enable bool
+	Rules  []*FilterRule
+	// Moderator is the url of an external moderation service, empty means not used.
+	Moderator string
+	Timeout   xtime.Duration
+}
+
+// FilterRule is a keyword or regexp rule of the content filter.
+type FilterRule struct {
+	Name string
+	// Action is "block", "mask" or "flag" when the rule matched.
+	Action   string
+	Words    []string
+	Patterns []string
+}
+
 // Redis .
 type Redis struct {
 	Network      string
@@ -89,8 +211,12 @@ type Redis struct {
 
 // Nsq .
 type Nsq struct {
-	Topic   string
-	Address string
+	Topic string
+	// PriorityTopic carries the high priority messages, empty means Topic.
+	PriorityTopic string
+	Address       string
+	// MaxMsgSize is the max size of a message published, the batches are split under it.
+	MaxMsgSize int
 }
 
 // RPCClient is RPC client config.
@@ -215,6 +341,108 @@ func (b *Backoff) fix() (err error) {
 	return
 }
 
+func (s *Schedule) fix() (err error) {
+	if s.Store == "" {
+		s.Store = "redis"
+	}
+	if s.Tick == 0 {
+		s.Tick = xtime.Duration(time.Second)
+	}
+	if s.Batch == 0 {
+		s.Batch = 100
+	}
+	if s.Lease == 0 {
+		s.Lease = xtime.Duration(time.Minute)
+	}
+	return
+}
+
+func (n *Nsq) fix() (err error) {
+	if n.MaxMsgSize == 0 {
+		// the default max-msg-size of nsqd
+		n.MaxMsgSize = 1024 * 1024
+	}
+	return
+}
+
+func (d *Dedup) fix() (err error) {
+	if d.Window == 0 {
+		d.Window = xtime.Duration(10 * time.Minute)
+	}
+	return
+}
+
+func (n *Notify) fix() (err error) {
+	if n.Provider == "" {
+		n.Provider = "mock"
+	}
+	if n.Timeout == 0 {
+		n.Timeout = xtime.Duration(time.Second)
+	}
+	if n.Chan == 0 {
+		n.Chan = 1024
+	}
+	if n.Default == "" {
+		n.Default = `{"op":{{.Op}},"msg":{{json .Msg}}}`
+	}
+	n.tpls = make(map[string]*template.Template, len(n.Templates)+1)
+	if n.tpls[""], err = template.New("default").Funcs(notifyFuncs).Parse(n.Default); err != nil {
+		log.Error("notify default template(%s) error(%v)", n.Default, err)
+		return
+	}
+	for op, text := range n.Templates {
+		if n.tpls[op], err = template.New(op).Funcs(notifyFuncs).Parse(text); err != nil {
+			log.Error("notify template(%s,%s) error(%v)", op, text, err)
+			return
+		}
+	}
+	return
+}
+
+func (p *Presence) fix() (err error) {
+	if p.Tick == 0 {
+		p.Tick = xtime.Duration(10 * time.Second)
+	}
+	if p.Batch == 0 {
+		p.Batch = 100
+	}
+	return
+}
+
+func (m *Mongo) fix() (err error) {
+	if m.Database == "" {
+		m.Database = "goim"
+	}
+	if m.Timeout == 0 {
+		m.Timeout = xtime.Duration(time.Second)
+	}
+	return
+}
+
+func (m *Moderation) fix() (err error) {
+	if m.RecallWindow == 0 {
+		m.RecallWindow = xtime.Duration(2 * time.Minute)
+	}
+	return
+}
+
+func (f *Filter) fix() (err error) {
+	if f.Timeout == 0 {
+		f.Timeout = xtime.Duration(time.Second)
+	}
+	return
+}
+
+func (o *Orphan) fix() (err error) {
+	if o.Delay == 0 {
+		o.Delay = xtime.Duration(5 * time.Second)
+	}
+	if o.Batch == 0 {
+		o.Batch = 500
+	}
+	return
+}
+
 func (c *Config) fix() (err error) {
 	if c.Env == nil {
 		c.Env = new(Env)
@@ -267,6 +495,69 @@ func (c *Config) fix() (err error) {
 	if err = c.HTTPServer.fix(); err != nil {
 		return
 	}
+
+	if c.Nsq == nil {
+		c.Nsq = &Nsq{}
+	}
+	if err = c.Nsq.fix(); err != nil {
+		return
+	}
+
+	if c.Schedule == nil {
+		c.Schedule = &Schedule{Store: "redis", Tick: xtime.Duration(time.Second), Batch: 100, Lease: xtime.Duration(time.Minute)}
+	}
+	if err = c.Schedule.fix(); err != nil {
+		return
+	}
+
+	if c.Dedup == nil {
+		c.Dedup = &Dedup{Window: xtime.Duration(10 * time.Minute)}
+	}
+	if err = c.Dedup.fix(); err != nil {
+		return
+	}
+
+	if c.Notify == nil {
+		c.Notify = &Notify{}
+	}
+	if err = c.Notify.fix(); err != nil {
+		return
+	}
+
+	if c.Presence == nil {
+		c.Presence = &Presence{}
+	}
+	if err = c.Presence.fix(); err != nil {
+		return
+	}
+
+	if c.Mongo == nil {
+		c.Mongo = &Mongo{}
+	}
+	if err = c.Mongo.fix(); err != nil {
+		return
+	}
+
+	if c.Moderation == nil {
+		c.Moderation = &Moderation{}
+	}
+	if err = c.Moderation.fix(); err != nil {
+		return
+	}
+
+	if c.Filter == nil {
+		c.Filter = &Filter{}
+	}
+	if err = c.Filter.fix(); err != nil {
+		return
+	}
+
+	if c.Orphan == nil {
+		c.Orphan = &Orphan{}
+	}
+	if err = c.Orphan.fix(); err != nil {
+		return
+	}
 	return
 }
 
@@ -281,5 +572,11 @@ func (c *Config) Set(content string) (err error) {
 		return
 	}
 	*Conf = *tmpConf
+	reloadsMutex.Lock()
+	fns := reloads
+	reloadsMutex.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
 	return nil
 }
@@ -8,6 +8,8 @@ import (
 	log "github.com/go-kratos/kratos/pkg/log"
 	"github.com/google/uuid"
 	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/dao"
+	"github.com/ningchengzeng/goim/internal/logic/filter"
 	"github.com/ningchengzeng/goim/internal/logic/model"
 )
 
@@ -18,6 +20,7 @@ func (l *Logic) Connect(c context.Context, server, cookie string, token []byte)
 		Key      string  `json:"key"`
 		RoomID   string  `json:"room_id"`
 		Platform string  `json:"platform"`
+		Device   string  `json:"device"`
 		Accepts  []int32 `json:"accepts"`
 	}
 	if err = json.Unmarshal(token, &params); err != nil {
@@ -31,9 +34,15 @@ func (l *Logic) Connect(c context.Context, server, cookie string, token []byte)
 	if key = params.Key; key == "" {
 		key = uuid.New().String()
 	}
-	if err = l.dao.AddMapping(c, mid, key, server); err != nil {
+	var wasOnline bool
+	if l.c.Presence.Enable && mid > 0 {
+		wasOnline = l.isOnline(c, mid)
+	}
+	s := &model.Session{Key: key, Server: server, Platform: params.Platform, Device: params.Device, Connected: time.Now().Unix()}
+	if err = l.dao.AddMapping(c, mid, key, server, s); err != nil {
 		log.Error("l.dao.AddMapping(%d,%s,%s) error(%v)", mid, key, server, err)
 	}
+	l.presenceOnline(c, mid, key, server, wasOnline)
 	log.Info("conn connected key:%s server:%s mid:%d token:%s", key, server, mid, token)
 	return
 }
@@ -44,42 +53,86 @@ func (l *Logic) Disconnect(c context.Context, mid int64, key, server string) (ha
 		log.Error("l.dao.DelMapping(%d,%s) error(%v)", mid, key, server)
 		return
 	}
+	l.presenceOffline(c, mid, key, server)
 	log.Info("conn disconnected key:%s server:%s mid:%d", key, server, mid)
 	return
 }
 
 // Heartbeat heartbeat a conn.
 func (l *Logic) Heartbeat(c context.Context, mid int64, key, server string) (err error) {
-	has, err := l.dao.ExpireMapping(c, mid, key)
+	has, err := l.dao.ExpireMapping(c, mid, key, server)
 	if err != nil {
 		log.Error("l.dao.ExpireMapping(%d,%s,%s) error(%v)", mid, key, server, err)
 		return
 	}
+	wasOnline := true
 	if !has {
-		if err = l.dao.AddMapping(c, mid, key, server); err != nil {
+		// a heartbeat after the disconnect doesn't add the connection again
+		var closed bool
+		if closed, err = l.dao.KeyClosed(c, key); err != nil || closed {
+			return
+		}
+		if l.c.Presence.Enable && mid > 0 {
+			wasOnline = l.isOnline(c, mid)
+		}
+		// the session expired too, add it again without the platform and device
+		s := &model.Session{Key: key, Server: server, Connected: time.Now().Unix()}
+		if err = l.dao.AddMapping(c, mid, key, server, s); err != nil {
 			log.Error("l.dao.AddMapping(%d,%s,%s) error(%v)", mid, key, server, err)
 			return
 		}
 	}
+	l.presenceOnline(c, mid, key, server, wasOnline)
 	log.Info("conn heartbeat key:%s server:%s mid:%d", key, server, mid)
 	return
 }
 
-// RenewOnline renew a server online.
-func (l *Logic) RenewOnline(c context.Context, server string, roomCount map[string]int32) (map[string]int32, error) {
-	online := &model.Online{
-		Server:    server,
-		RoomCount: roomCount,
-		Updated:   time.Now().Unix(),
+// Heartbeats renew the heartbeats of a server by one redis call, the
+// mappings of a dead server are not renewed and expire with redis.
+func (l *Logic) Heartbeats(c context.Context, server string, hbs []*model.Heartbeat) (err error) {
+	res, err := l.dao.RenewMappings(c, server, hbs)
+	if err != nil {
+		log.Error("l.dao.RenewMappings(%s,%d) error(%v)", server, len(hbs), err)
+		return
+	}
+	if !l.c.Presence.Enable {
+		return
 	}
-	if err := l.dao.AddServerOnline(context.Background(), server, online); err != nil {
+	mids := make([]int64, 0, len(hbs))
+	for i, hb := range hbs {
+		if hb.Mid <= 0 || res[i] == dao.RenewClosed {
+			continue
+		}
+		mids = append(mids, hb.Mid)
+		if res[i] == dao.RenewOffline {
+			l.emitPresence(&model.PresenceEvent{Mid: hb.Mid, Online: true, Reason: model.PresenceConnect, Key: hb.Key, Server: server})
+		}
+	}
+	if err = l.dao.TouchPresences(c, mids, l.presenceDeadline()); err != nil {
+		log.Error("l.dao.TouchPresences(%d) error(%v)", len(mids), err)
+	}
+	return
+}
+
+// RenewOnline renew the room counts of a server, roomCount only has the changed rooms if not full.
+// It returns the room counts of all the servers last loaded, the reply must not be modified.
+func (l *Logic) RenewOnline(c context.Context, server string, roomCount map[string]int32, full bool) (map[string]int32, error) {
+	if err := l.dao.RenewServerOnline(c, server, roomCount, full, time.Now().Unix()); err != nil {
 		return nil, err
 	}
-	return l.roomCount, nil
+	return l.onlineRooms(), nil
 }
 
-// Receive receive a message.
-func (l *Logic) Receive(c context.Context, mid int64, proto *protocol.Proto) (err error) {
-	log.Info("receive mid:%d message:%+v", mid, proto)
+// Receive receive a message, the content filter only checks it is not blocked,
+// the message is not delivered by logic so the masked body is not used.
+// roomID is the room joined by the connection.
+func (l *Logic) Receive(c context.Context, mid int64, roomID string, proto *protocol.Proto) (err error) {
+	log.Info("receive mid:%d room:%s message:%+v", mid, roomID, proto)
+	switch proto.Op {
+	case protocol.OpRead:
+		err = l.receiveRead(c, mid, roomID, proto)
+	default:
+		_, err = l.filterMessage(c, model.HistoryUser, &filter.Message{Mid: mid, Op: proto.Op, Body: proto.Body})
+	}
 	return
 }
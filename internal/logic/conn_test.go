@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,15 +29,28 @@ func TestConnect(t *testing.T) {
 	// heartbeat
 	err = lg.Heartbeat(c, mid, key, server)
 	assert.Nil(t, err)
+	// sessions
+	sessions, err := lg.Sessions(c, mid)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions))
+	assert.Equal(t, "web", sessions[0].Platform)
 	// disconnect
 	has, err := lg.Disconnect(c, mid, key, server)
 	assert.Nil(t, err)
 	assert.Equal(t, true, has)
+	// a heartbeat after the disconnect doesn't add the session again
+	err = lg.Heartbeat(c, mid, key, server)
+	assert.Nil(t, err)
+	err = lg.Heartbeats(c, server, []*model.Heartbeat{{Mid: mid, Key: key}})
+	assert.Nil(t, err)
+	sessions, err = lg.Sessions(c, mid)
+	assert.Nil(t, err)
+	assert.Empty(t, sessions)
 	// renew
-	online, err := lg.RenewOnline(c, server, ol)
+	online, err := lg.RenewOnline(c, server, ol, true)
 	assert.Nil(t, err)
 	assert.NotNil(t, online)
 	// message
-	err = lg.Receive(c, mid, &protocol.Proto{})
+	err = lg.Receive(c, mid, roomID, &protocol.Proto{})
 	assert.Nil(t, err)
 }
@@ -0,0 +1,104 @@
+package dao
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	_keyBans      = "bans" // ip:ip|mid:mid -> expire
+	_banIPPrefix  = "ip:"
+	_banMidPrefix = "mid:"
+)
+
+func banIPField(ip string) string {
+	return _banIPPrefix + ip
+}
+
+func banMidField(mid int64) string {
+	return _banMidPrefix + strconv.FormatInt(mid, 10)
+}
+
+// AddBans add the bans of ips and mids.
+func (d *Dao) AddBans(c context.Context, bans []*model.Ban) (err error) {
+	args := redis.Args{}.Add(_keyBans)
+	for _, b := range bans {
+		if b.IP != "" {
+			args = args.Add(banIPField(b.IP), b.Expire)
+		}
+		if b.Mid != 0 {
+			args = args.Add(banMidField(b.Mid), b.Expire)
+		}
+	}
+	if len(args) == 1 {
+		return
+	}
+	conn := d.redis.Get()
+	defer conn.Close()
+	if _, err = conn.Do("HMSET", args...); err != nil {
+		log.Error("conn.Do(HMSET %s) error(%v)", _keyBans, err)
+	}
+	return
+}
+
+// DelBans delete the bans of ips and mids.
+func (d *Dao) DelBans(c context.Context, ips []string, mids []int64) (err error) {
+	args := redis.Args{}.Add(_keyBans)
+	for _, ip := range ips {
+		args = args.Add(banIPField(ip))
+	}
+	for _, mid := range mids {
+		args = args.Add(banMidField(mid))
+	}
+	if len(args) == 1 {
+		return
+	}
+	conn := d.redis.Get()
+	defer conn.Close()
+	if _, err = conn.Do("HDEL", args...); err != nil {
+		log.Error("conn.Do(HDEL %s) error(%v)", _keyBans, err)
+	}
+	return
+}
+
+// Bans get all the bans, the expired ones are deleted.
+func (d *Dao) Bans(c context.Context) (res []*model.Ban, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	m, err := redis.Int64Map(conn.Do("HGETALL", _keyBans))
+	if err != nil {
+		log.Error("conn.Do(HGETALL %s) error(%v)", _keyBans, err)
+		return
+	}
+	var (
+		now     = time.Now().Unix()
+		expired = redis.Args{}.Add(_keyBans)
+	)
+	for field, expire := range m {
+		if expire != 0 && expire <= now {
+			expired = expired.Add(field)
+			continue
+		}
+		b := &model.Ban{Expire: expire}
+		if strings.HasPrefix(field, _banIPPrefix) {
+			b.IP = strings.TrimPrefix(field, _banIPPrefix)
+		} else if b.Mid, err = strconv.ParseInt(strings.TrimPrefix(field, _banMidPrefix), 10, 64); err != nil {
+			log.Error("strconv.ParseInt(%s) error(%v)", field, err)
+			err = nil
+			continue
+		}
+		res = append(res, b)
+	}
+	if len(expired) > 1 {
+		if _, err = conn.Do("HDEL", expired...); err != nil {
+			log.Error("conn.Do(HDEL %s) error(%v)", _keyBans, err)
+		}
+	}
+	return
+}
@@ -0,0 +1,31 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaoBans(t *testing.T) {
+	var (
+		c    = context.Background()
+		bans = []*model.Ban{
+			{IP: "10.0.0.1"},
+			{Mid: 1000, Expire: time.Now().Add(time.Hour).Unix()},
+			{Mid: 1001, Expire: time.Now().Add(-time.Hour).Unix()},
+		}
+	)
+	err := d.AddBans(c, bans)
+	assert.Nil(t, err)
+	res, err := d.Bans(c)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res))
+	err = d.DelBans(c, []string{"10.0.0.1"}, []int64{1000})
+	assert.Nil(t, err)
+	res, err = d.Bans(c)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(res))
+}
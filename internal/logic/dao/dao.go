@@ -8,6 +8,7 @@ import (
 	"github.com/ningchengzeng/goim/internal/logic/conf"
 
 	"github.com/nsqio/go-nsq"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // Dao dao.
@@ -16,6 +17,7 @@ type Dao struct {
 	nsqPub      *nsq.Producer
 	redis       *redis.Pool
 	redisExpire int32
+	mongo       *mongo.Database
 }
 
 // New new a dao and return.
@@ -25,6 +27,7 @@ func New(c *conf.Config) *Dao {
 		nsqPub:      newNsqPub(c.Nsq),
 		redis:       newRedis(c.Redis),
 		redisExpire: int32(time.Duration(c.Redis.Expire) / time.Second),
+		mongo:       newMongo(c.Mongo),
 	}
 	return d
 }
@@ -61,6 +64,9 @@ func newRedis(c *conf.Redis) *redis.Pool {
 
 // Close close the resource.
 func (d *Dao) Close() error {
+	if d.mongo != nil {
+		_ = d.mongo.Client().Disconnect(context.Background())
+	}
 	return d.redis.Close()
 }
 
@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	_prefixMidDevice = "dev_%d" // mid -> token:device
+)
+
+func keyMidDevice(mid int64) string {
+	return fmt.Sprintf(_prefixMidDevice, mid)
+}
+
+// AddDevice register a device token of mid.
+func (d *Dao) AddDevice(c context.Context, device *model.Device) (err error) {
+	b, err := json.Marshal(device)
+	if err != nil {
+		return
+	}
+	conn := d.redis.Get()
+	defer conn.Close()
+	key := keyMidDevice(device.Mid)
+	if _, err = conn.Do("HSET", key, device.Token, b); err != nil {
+		log.Error("conn.Do(HSET %s,%s) error(%v)", key, device.Token, err)
+	}
+	return
+}
+
+// DelDevice unregister a device token of mid.
+func (d *Dao) DelDevice(c context.Context, mid int64, token string) (has bool, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	key := keyMidDevice(mid)
+	if has, err = redis.Bool(conn.Do("HDEL", key, token)); err != nil {
+		log.Error("conn.Do(HDEL %s,%s) error(%v)", key, token, err)
+	}
+	return
+}
+
+// DevicesByMids get the devices of mids.
+func (d *Dao) DevicesByMids(c context.Context, mids []int64) (res []*model.Device, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	for _, mid := range mids {
+		if err = conn.Send("HVALS", keyMidDevice(mid)); err != nil {
+			log.Error("conn.Send(HVALS %d) error(%v)", mid, err)
+			return
+		}
+	}
+	if err = conn.Flush(); err != nil {
+		log.Error("conn.Flush() error(%v)", err)
+		return
+	}
+	for range mids {
+		var bs [][]byte
+		if bs, err = redis.ByteSlices(conn.Receive()); err != nil {
+			log.Error("conn.Receive() error(%v)", err)
+			return
+		}
+		for _, b := range bs {
+			device := new(model.Device)
+			if e := json.Unmarshal(b, device); e != nil {
+				log.Error("json.Unmarshal(%s) error(%v)", b, e)
+				continue
+			}
+			res = append(res, device)
+		}
+	}
+	return
+}
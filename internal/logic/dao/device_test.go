@@ -0,0 +1,25 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaoDevice(t *testing.T) {
+	var (
+		c      = context.Background()
+		device = &model.Device{Mid: 1000, Platform: model.PlatformAndroid, Token: "test_token"}
+	)
+	err := d.AddDevice(c, device)
+	assert.Nil(t, err)
+	res, err := d.DevicesByMids(c, []int64{device.Mid, 1001})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, device.Token, res[0].Token)
+	has, err := d.DelDevice(c, device.Mid, device.Token)
+	assert.Nil(t, err)
+	assert.True(t, has)
+}
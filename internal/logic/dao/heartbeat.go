@@ -0,0 +1,120 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	// RenewRenewed the mapping was renewed.
+	RenewRenewed = 1
+	// RenewAdded the mapping was expired and added again, the mid has other connections.
+	RenewAdded = 0
+	// RenewOffline the mid had no connection, its mapping was added again.
+	RenewOffline = -1
+	// RenewClosed the connection was disconnected, its mapping is not renewed.
+	RenewClosed = -2
+)
+
+// _renewMappings renews the mappings of many heartbeats at once, the mappings
+// already expired are added again, like ExpireMapping then AddMapping, and so
+// is the session of the connection. A heartbeat sent before the disconnect
+// but renewed after it is skipped, the disconnect leaves a closed key.
+// The connections in the server keys index are scored by the last heartbeat,
+// the ones not renewed within the expire are removed.
+// KEYS are the server keys index, then the key_server and closed keys of every
+// heartbeat, followed by its mid_server and session keys if it has a mid.
+// ARGV are the server, the expire, the now unix seconds, then the connection
+// key, the server keys member and the session of every heartbeat, the session
+// is empty if no mid.
+var _renewMappings = redis.NewScript(-1, `
+local res = {}
+local k = 2
+for i = 1, (#ARGV - 3) / 3 do
+	local key, member, session = ARGV[3*i+1], ARGV[3*i+2], ARGV[3*i+3]
+	local keyServer, closed = KEYS[k], KEYS[k+1]
+	k = k + 2
+	local midServer, sessions
+	if session ~= '' then
+		midServer, sessions = KEYS[k], KEYS[k+1]
+		k = k + 2
+	end
+	local r = -2
+	if redis.call('EXISTS', closed) == 0 then
+		r = redis.call('EXPIRE', keyServer, ARGV[2])
+		if r == 0 then
+			redis.call('SET', keyServer, ARGV[1], 'EX', ARGV[2])
+		end
+		if session ~= '' then
+			if redis.call('EXISTS', midServer) == 0 then
+				r = -1
+				redis.call('HSET', midServer, key, ARGV[1])
+			elseif r == 0 then
+				redis.call('HSET', midServer, key, ARGV[1])
+			end
+			redis.call('EXPIRE', midServer, ARGV[2])
+			redis.call('HSETNX', sessions, key, session)
+			redis.call('EXPIRE', sessions, ARGV[2])
+		end
+		redis.call('ZADD', KEYS[1], ARGV[3], member)
+	end
+	res[i] = r
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', tonumber(ARGV[3]) - tonumber(ARGV[2]))
+redis.call('EXPIRE', KEYS[1], ARGV[2])
+return res
+`)
+
+// RenewMappings renew the mappings of the heartbeats by one script,
+// res are RenewRenewed, RenewAdded, RenewOffline or RenewClosed of every heartbeat.
+// A session expired is added again without the platform and device.
+func (d *Dao) RenewMappings(c context.Context, server string, hbs []*model.Heartbeat) (res []int, err error) {
+	if len(hbs) == 0 {
+		return
+	}
+	keys := make([]interface{}, 0, len(hbs)*4+1)
+	argv := make([]interface{}, 0, len(hbs)*3+3)
+	now := time.Now().Unix()
+	keys = append(keys, keyServerKeys(server))
+	argv = append(argv, server, d.redisExpire, now)
+	for _, hb := range hbs {
+		keys = append(keys, keyKeyServer(hb.Key), keyKeyClosed(hb.Key))
+		var session []byte
+		if hb.Mid > 0 {
+			keys = append(keys, keyMidServer(hb.Mid), keyMidSession(hb.Mid))
+			if session, err = json.Marshal(&model.Session{Key: hb.Key, Server: server, Connected: now}); err != nil {
+				return
+			}
+		}
+		argv = append(argv, hb.Key, serverKey(hb.Mid, hb.Key), session)
+	}
+	args := append(append([]interface{}{len(keys)}, keys...), argv...)
+	conn := d.redis.Get()
+	defer conn.Close()
+	if res, err = redis.Ints(_renewMappings.Do(conn, args...)); err != nil {
+		log.Error("renewMappings(%s,%d) error(%v)", server, len(hbs), err)
+	}
+	return
+}
+
+// TouchPresences set the heartbeat deadline of mids.
+func (d *Dao) TouchPresences(c context.Context, mids []int64, deadline int64) (err error) {
+	if len(mids) == 0 {
+		return
+	}
+	args := redis.Args{}.Add(_keyPresenceDue)
+	for _, mid := range mids {
+		args = args.Add(deadline, mid)
+	}
+	conn := d.redis.Get()
+	defer conn.Close()
+	if _, err = conn.Do("ZADD", args...); err != nil {
+		log.Error("conn.Do(ZADD %s,%d) error(%v)", _keyPresenceDue, len(mids), err)
+	}
+	return
+}
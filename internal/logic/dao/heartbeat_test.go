@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaoRenewMappings(t *testing.T) {
+	var (
+		c      = context.Background()
+		mid    = int64(3000)
+		key    = "test_renew_key"
+		guest  = "test_renew_guest"
+		server = "test_server"
+	)
+	hbs := []*model.Heartbeat{{Mid: mid, Key: key}, {Key: guest}}
+	// the mappings expired
+	conn := d.redis.Get()
+	_, err := conn.Do("DEL", keyKeyServer(key), keyKeyServer(guest), keyMidServer(mid), keyMidSession(mid), keyKeyClosed(key), keyKeyClosed(guest))
+	conn.Close()
+	assert.Nil(t, err)
+	res, err := d.RenewMappings(c, server, hbs)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{RenewOffline, RenewAdded}, res)
+	res, err = d.RenewMappings(c, server, hbs)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{RenewRenewed, RenewRenewed}, res)
+	servers, err := d.ServersByKeys(c, []string{key, guest})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{server, server}, servers)
+	// the expired session is added again
+	sessions, err := d.SessionsByMids(c, []int64{mid})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions[mid]))
+	assert.Equal(t, key, sessions[mid][0].Key)
+	err = d.TouchPresences(c, []int64{mid}, 100)
+	assert.Nil(t, err)
+}
+
+func TestDaoRenewMappingsClosed(t *testing.T) {
+	var (
+		c      = context.Background()
+		mid    = int64(3001)
+		key    = "test_renew_closed_key"
+		server = "test_server"
+	)
+	err := d.AddMapping(c, mid, key, server, &model.Session{Key: key, Server: server})
+	assert.Nil(t, err)
+	has, err := d.DelMapping(c, mid, key, server)
+	assert.Nil(t, err)
+	assert.True(t, has)
+	// the heartbeat queued before the disconnect doesn't add it again
+	res, err := d.RenewMappings(c, server, []*model.Heartbeat{{Mid: mid, Key: key}})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{RenewClosed}, res)
+	servers, err := d.ServersByKeys(c, []string{key})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{""}, servers)
+	sessions, err := d.SessionsByMids(c, []int64{mid})
+	assert.Nil(t, err)
+	assert.Empty(t, sessions[mid])
+	closed, err := d.KeyClosed(c, key)
+	assert.Nil(t, err)
+	assert.True(t, closed)
+	// connected again
+	err = d.AddMapping(c, mid, key, server, nil)
+	assert.Nil(t, err)
+	res, err = d.RenewMappings(c, server, []*model.Heartbeat{{Mid: mid, Key: key}})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{RenewRenewed}, res)
+	_, err = d.DelMapping(c, mid, key, server)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/ningchengzeng/goim/modal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// History get a page of the history messages from mongo.
+func (d *Dao) History(c context.Context, q *model.HistoryQuery) (res []*model.HistoryMessage, err error) {
+	if d.mongo == nil {
+		return
+	}
+	var (
+		before     primitive.ObjectID
+		start, end time.Time
+	)
+	if q.Cursor != "" {
+		if before, err = primitive.ObjectIDFromHex(q.Cursor); err != nil {
+			return
+		}
+	}
+	if q.Start > 0 {
+		start = time.Unix(q.Start, 0)
+	}
+	if q.End > 0 {
+		end = time.Unix(q.End, 0)
+	}
+	msg := new(modal.Message)
+	switch q.Type {
+	case model.HistoryUser:
+		msg.Type = modal.UserMessage
+		msg.UserId = strconv.FormatInt(q.Mid, 10)
+		msg.ObjectUserId = strconv.FormatInt(q.Peer, 10)
+	case model.HistoryRoom:
+		msg.Type = modal.RoomMessage
+		msg.RoomId = q.Room
+	default:
+		msg.Type = modal.BroadcastMessage
+	}
+	for _, m := range msg.FindHistory(d.mongo, before, start, end, int64(q.Limit)) {
+		res = append(res, &model.HistoryMessage{
+			ID:       m.Id,
+			Type:     q.Type,
+			From:     m.UserId,
+			To:       m.ObjectUserId,
+			Room:     m.RoomId,
+			Body:     m.Body,
+			SendTime: m.SendTime.Unix(),
+		})
+	}
+	return
+}
+
+// Message get a message by id, nil if not found.
+func (d *Dao) Message(c context.Context, id string) (res *model.HistoryMessage, err error) {
+	if d.mongo == nil {
+		return
+	}
+	m := (&modal.Message{Id: id}).FindById(d.mongo)
+	if m == nil {
+		return
+	}
+	res = &model.HistoryMessage{
+		ID:       m.Id,
+		From:     m.UserId,
+		To:       m.ObjectUserId,
+		Room:     m.RoomId,
+		Keys:     m.Keys,
+		Body:     m.Body,
+		SendTime: m.SendTime.Unix(),
+	}
+	switch m.Type {
+	case modal.UserMessage:
+		res.Type = model.HistoryUser
+	case modal.RoomMessage:
+		res.Type = model.HistoryRoom
+	case modal.KeyMessage:
+		res.Type = model.HistoryKeys
+	default:
+		res.Type = model.HistoryBroadcast
+	}
+	return
+}
+
+// DelMessage mark a message deleted.
+func (d *Dao) DelMessage(c context.Context, id string) (err error) {
+	if d.mongo != nil {
+		err = (&modal.Message{Id: id}).SetDel(d.mongo)
+	}
+	return
+}
+
+// SetMessageViolation mark a message as a violation.
+func (d *Dao) SetMessageViolation(c context.Context, id string) (err error) {
+	if d.mongo != nil {
+		err = (&modal.Message{Id: id}).SetViolation(d.mongo)
+	}
+	return
+}
+
+// AddViolation store a flagged message and mark it as a violation.
+func (d *Dao) AddViolation(c context.Context, m *model.HistoryMessage) (id string, err error) {
+	if d.mongo == nil {
+		return
+	}
+	msg := &modal.Message{
+		UserId:       m.From,
+		ObjectUserId: m.To,
+		RoomId:       m.Room,
+		Body:         m.Body,
+		Send:         true,
+		SendTime:     time.Unix(m.SendTime, 0),
+	}
+	switch m.Type {
+	case model.HistoryUser:
+		msg.Type = modal.UserMessage
+	case model.HistoryRoom:
+		msg.Type = modal.RoomMessage
+	default:
+		msg.Type = modal.BroadcastMessage
+	}
+	objID := msg.Insert(d.mongo)
+	if objID.IsZero() {
+		return "", errors.New("insert violation message failed")
+	}
+	msg.Id = objID.Hex()
+	if err = msg.SetViolation(d.mongo); err != nil {
+		return
+	}
+	return msg.Id, nil
+}
+
+// AddAudit add an audit record of a message.
+func (d *Dao) AddAudit(c context.Context, a *model.Audit) (err error) {
+	if d.mongo == nil {
+		return
+	}
+	return (&modal.Audit{Operator: a.Operator, Action: a.Action, MessageId: a.MsgID, Reason: a.Reason}).Insert(d.mongo)
+}
+
+// Audits get the audit records of a message.
+func (d *Dao) Audits(c context.Context, msgID string) (res []*model.Audit, err error) {
+	if d.mongo == nil {
+		return
+	}
+	for _, a := range (&modal.Audit{MessageId: msgID}).FindByMessageId(d.mongo) {
+		res = append(res, &model.Audit{
+			Operator: a.Operator,
+			Action:   a.Action,
+			MsgID:    a.MessageId,
+			Reason:   a.Reason,
+			Created:  a.CreateTime.Unix(),
+		})
+	}
+	return
+}
@@ -0,0 +1,54 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/internal/logic/conf"
+	"github.com/ningchengzeng/goim/modal"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func newMongo(c *conf.Mongo) *mongo.Database {
+	if c.URI == "" {
+		return nil
+	}
+	client, err := mongo.NewClient(options.Client().ApplyURI(c.URI).SetConnectTimeout(time.Duration(c.Timeout)))
+	if err != nil {
+		log.Error("mongo.NewClient(%s) error(%v)", c.URI, err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout))
+	defer cancel()
+	if err = client.Connect(ctx); err != nil {
+		log.Error("mongo.Connect(%s) error(%v)", c.URI, err)
+		return nil
+	}
+	return client.Database(c.Database)
+}
+
+// Friends get the friend mids of mid, the friends are kept by user code and
+// resolved to their mids.
+func (d *Dao) Friends(c context.Context, mid int64) (mids []int64, err error) {
+	if d.mongo == nil {
+		return
+	}
+	user := (&modal.User{Mid: mid}).FindByMid(d.mongo)
+	if user == nil {
+		return
+	}
+	codes := user.FriendIds()
+	if len(codes) == 0 {
+		return
+	}
+	for _, friend := range modal.FindUsersByCodes(d.mongo, codes) {
+		if friend.Mid <= 0 {
+			log.Warn("friend code:%s of mid:%d has no mid", friend.Code, mid)
+			continue
+		}
+		mids = append(mids, friend.Mid)
+	}
+	return
+}
@@ -8,11 +8,21 @@ import (
 	pb "github.com/ningchengzeng/goim/api/logic"
 )
 
+// topic get the nsq topic by priority.
+func (d *Dao) topic(priority pb.PushMsg_Priority) string {
+	if priority.IsHigh() && d.c.Nsq.PriorityTopic != "" {
+		return d.c.Nsq.PriorityTopic
+	}
+	return d.c.Nsq.Topic
+}
+
 // PushMsg push a message to databus.
-func (d *Dao) PushMsg(c context.Context, op int32, server string, keys []string, msg []byte) (err error) {
+func (d *Dao) PushMsg(c context.Context, op int32, priority pb.PushMsg_Priority, msgID, server string, keys []string, msg []byte) (err error) {
 	pushMsg := &pb.PushMsg{
 		Type:      pb.PushMsg_PUSH,
 		Operation: op,
+		Priority:  priority,
+		MsgID:     msgID,
 		Server:    server,
 		Keys:      keys,
 		Msg:       msg,
@@ -22,17 +32,19 @@ func (d *Dao) PushMsg(c context.Context, op int32, server string, keys []string,
 		return
 	}
 
-	if err = d.nsqPub.Publish(d.c.Nsq.Topic, b); err != nil {
+	if err = d.nsqPub.Publish(d.topic(priority), b); err != nil {
 		log.Error("PushMsg.send(push pushMsg:%v) error(%v)", pushMsg, err)
 	}
 	return
 }
 
 // BroadcastRoomMsg push a message to databus.
-func (d *Dao) BroadcastRoomMsg(c context.Context, op int32, room string, msg []byte) (err error) {
+func (d *Dao) BroadcastRoomMsg(c context.Context, op int32, priority pb.PushMsg_Priority, msgID, room string, msg []byte) (err error) {
 	pushMsg := &pb.PushMsg{
 		Type:      pb.PushMsg_ROOM,
 		Operation: op,
+		Priority:  priority,
+		MsgID:     msgID,
 		Room:      room,
 		Msg:       msg,
 	}
@@ -40,17 +52,19 @@ func (d *Dao) BroadcastRoomMsg(c context.Context, op int32, room string, msg []b
 	if err != nil {
 		return
 	}
-	if err = d.nsqPub.Publish(d.c.Nsq.Topic, b); err != nil {
+	if err = d.nsqPub.Publish(d.topic(priority), b); err != nil {
 		log.Error("PushMsg.send(broadcast_room pushMsg:%v) error(%v)", pushMsg, err)
 	}
 	return
 }
 
 // BroadcastMsg push a message to databus.
-func (d *Dao) BroadcastMsg(c context.Context, op, speed int32, msg []byte) (err error) {
+func (d *Dao) BroadcastMsg(c context.Context, op, speed int32, priority pb.PushMsg_Priority, msgID string, msg []byte) (err error) {
 	pushMsg := &pb.PushMsg{
 		Type:      pb.PushMsg_BROADCAST,
 		Operation: op,
+		Priority:  priority,
+		MsgID:     msgID,
 		Speed:     speed,
 		Msg:       msg,
 	}
@@ -58,8 +72,58 @@ func (d *Dao) BroadcastMsg(c context.Context, op, speed int32, msg []byte) (err
 	if err != nil {
 		return
 	}
-	if err = d.nsqPub.Publish(d.c.Nsq.Topic, b); err != nil {
+	if err = d.nsqPub.Publish(d.topic(priority), b); err != nil {
 		log.Error("PushMsg.send(broadcast pushMsg:%v) error(%v)", pushMsg, err)
 	}
 	return
 }
+
+// PushBatchMsg push a batch of messages to the keys of a server, the batch is
+// split into the messages not larger than the max message size of nsq.
+// failed are the items not published.
+func (d *Dao) PushBatchMsg(c context.Context, priority pb.PushMsg_Priority, server string, items []*pb.PushItem) (failed []*pb.PushItem, err error) {
+	for _, chunk := range splitBatch(items, d.c.Nsq.MaxMsgSize) {
+		pushMsg := &pb.PushMsg{
+			Type:     pb.PushMsg_BATCH,
+			Priority: priority,
+			Server:   server,
+			Items:    chunk,
+		}
+		b, e := proto.Marshal(pushMsg)
+		if e == nil {
+			e = d.nsqPub.Publish(d.topic(priority), b)
+		}
+		if e != nil {
+			log.Error("PushMsg.send(batch server:%s items:%d) error(%v)", server, len(chunk), e)
+			failed = append(failed, chunk...)
+			err = e
+		}
+	}
+	return
+}
+
+// _batchOverhead is the room kept for the fields of a batch message besides the items.
+const _batchOverhead = 1024
+
+// splitBatch split the items into the chunks of at most size bytes encoded,
+// an item larger than size is a chunk alone.
+func splitBatch(items []*pb.PushItem, size int) (chunks [][]*pb.PushItem) {
+	var (
+		chunk []*pb.PushItem
+		n     = _batchOverhead
+	)
+	for _, item := range items {
+		// the item is a length delimited field of the batch message
+		m := proto.Size(item) + 8
+		if len(chunk) > 0 && n+m > size {
+			chunks = append(chunks, chunk)
+			chunk, n = nil, _batchOverhead
+		}
+		chunk = append(chunk, item)
+		n += m
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return
+}
@@ -0,0 +1,25 @@
+package dao
+
+import (
+	"bytes"
+	"testing"
+
+	pb "github.com/ningchengzeng/goim/api/logic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitBatch(t *testing.T) {
+	var items []*pb.PushItem
+	for i := 0; i < 10; i++ {
+		items = append(items, &pb.PushItem{Operation: 1000, Keys: []string{"test_key"}, Msg: bytes.Repeat([]byte("a"), 1000)})
+	}
+	chunks := splitBatch(items, 4*1024)
+	assert.Equal(t, 4, len(chunks))
+	var n int
+	for _, chunk := range chunks {
+		n += len(chunk)
+	}
+	assert.Equal(t, len(items), n)
+	chunks = splitBatch(items[:1], 512)
+	assert.Equal(t, 1, len(chunks))
+}
@@ -0,0 +1,285 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	_prefixServerRooms = "svr_rooms_%s"   // server -> room:count
+	_prefixTopRooms    = "top_rooms_%s"   // type -> zset room id by count
+	_keyRoomsOnline    = "rooms_online"   // room -> count of all the servers
+	_keyRoomsChanged   = "rooms_changed"  // zset room by changed milliseconds
+	_keyServersOnline  = "servers_online" // zset server by updated
+	_keyOnlineExpire   = "online_expire"  // the expire of servers online is locked
+
+	_maxTopRooms = 1000
+)
+
+func keyServerRooms(server string) string {
+	return fmt.Sprintf(_prefixServerRooms, server)
+}
+
+func keyTopRooms(typ string) string {
+	return fmt.Sprintf(_prefixTopRooms, typ)
+}
+
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// _renewServerOnline applies the changed room counts of a server to the room
+// counts of all the servers, a zero count means the room is gone. The rooms
+// their counts changed are logged, so that only them are loaded again.
+// KEYS are the server rooms, the rooms online, the servers online, the rooms
+// changed, then the top rooms key of every room, empty if the room key is invalid.
+// ARGV are the server, the updated time, the changed milliseconds, then the
+// room key, the room id and the count of every room.
+var _renewServerOnline = redis.NewScript(-1, `
+for i = 1, #KEYS - 4 do
+	local room, id, count = ARGV[3*i+1], ARGV[3*i+2], tonumber(ARGV[3*i+3])
+	local old = tonumber(redis.call('HGET', KEYS[1], room) or '0')
+	if count > 0 then
+		redis.call('HSET', KEYS[1], room, count)
+	else
+		redis.call('HDEL', KEYS[1], room)
+	end
+	local diff = count - old
+	if diff ~= 0 then
+		if redis.call('HINCRBY', KEYS[2], room, diff) <= 0 then
+			redis.call('HDEL', KEYS[2], room)
+		end
+		redis.call('ZADD', KEYS[4], ARGV[3], room)
+		local top = KEYS[4+i]
+		if top ~= '' and tonumber(redis.call('ZINCRBY', top, diff, id)) <= 0 then
+			redis.call('ZREM', top, id)
+		end
+	end
+end
+redis.call('ZADD', KEYS[3], ARGV[2], ARGV[1])
+return 0
+`)
+
+// RenewServerOnline renew the room counts of a server, roomCount only has the
+// changed rooms if not full.
+func (d *Dao) RenewServerOnline(c context.Context, server string, roomCount map[string]int32, full bool, updated int64) (err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if full {
+		var rooms []string
+		if rooms, err = redis.Strings(conn.Do("HKEYS", keyServerRooms(server))); err != nil {
+			log.Error("conn.Do(HKEYS %s) error(%v)", keyServerRooms(server), err)
+			return
+		}
+		delta := make(map[string]int32, len(roomCount)+len(rooms))
+		for _, room := range rooms {
+			delta[room] = 0
+		}
+		for room, count := range roomCount {
+			delta[room] = count
+		}
+		roomCount = delta
+	}
+	keys := make([]interface{}, 0, len(roomCount)+4)
+	argv := make([]interface{}, 0, len(roomCount)*3+3)
+	keys = append(keys, keyServerRooms(server), _keyRoomsOnline, _keyServersOnline, _keyRoomsChanged)
+	argv = append(argv, server, updated, unixMilli(time.Now()))
+	for room, count := range roomCount {
+		typ, id, e := model.DecodeRoomKey(room)
+		if e != nil {
+			keys = append(keys, "")
+		} else {
+			keys = append(keys, keyTopRooms(typ))
+		}
+		argv = append(argv, room, id, count)
+	}
+	args := append(append([]interface{}{len(keys)}, keys...), argv...)
+	if _, err = _renewServerOnline.Do(conn, args...); err != nil {
+		log.Error("renewServerOnline(%s,%d) error(%v)", server, len(roomCount), err)
+	}
+	return
+}
+
+// AllRoomsOnline get the counts of all the rooms.
+func (d *Dao) AllRoomsOnline(c context.Context) (roomCount map[string]int32, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	counts, err := redis.IntMap(conn.Do("HGETALL", _keyRoomsOnline))
+	if err != nil {
+		log.Error("conn.Do(HGETALL %s) error(%v)", _keyRoomsOnline, err)
+		return
+	}
+	roomCount = make(map[string]int32, len(counts))
+	for room, count := range counts {
+		roomCount[room] = int32(count)
+	}
+	return
+}
+
+// ChangedRoomsOnline get the counts of the rooms changed after since milliseconds,
+// a zero count means the room is gone, last is the latest change loaded.
+func (d *Dao) ChangedRoomsOnline(c context.Context, since int64) (roomCount map[string]int32, last int64, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	values, err := redis.Strings(conn.Do("ZRANGEBYSCORE", _keyRoomsChanged, fmt.Sprintf("(%d", since), "+inf", "WITHSCORES"))
+	if err != nil {
+		log.Error("conn.Do(ZRANGEBYSCORE %s,%d) error(%v)", _keyRoomsChanged, since, err)
+		return
+	}
+	last = since
+	rooms := make([]string, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		rooms = append(rooms, values[i])
+		if score, e := strconv.ParseInt(values[i+1], 10, 64); e == nil && score > last {
+			last = score
+		}
+	}
+	roomCount = make(map[string]int32, len(rooms))
+	if len(rooms) == 0 {
+		return
+	}
+	counts, err := redis.Ints(conn.Do("HMGET", redis.Args{}.Add(_keyRoomsOnline).AddFlat(rooms)...))
+	if err != nil {
+		log.Error("conn.Do(HMGET %s,%d) error(%v)", _keyRoomsOnline, len(rooms), err)
+		return
+	}
+	for i, room := range rooms {
+		roomCount[room] = int32(counts[i])
+	}
+	return
+}
+
+// DelRoomsChanged del the room changes logged before milliseconds.
+func (d *Dao) DelRoomsChanged(c context.Context, before int64) (err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if _, err = conn.Do("ZREMRANGEBYSCORE", _keyRoomsChanged, "-inf", before); err != nil {
+		log.Error("conn.Do(ZREMRANGEBYSCORE %s,%d) error(%v)", _keyRoomsChanged, before, err)
+	}
+	return
+}
+
+// LockOnlineExpire lock the expire of servers online for expire seconds, ok is
+// false if it's locked by another logic.
+func (d *Dao) LockOnlineExpire(c context.Context, expire int32) (ok bool, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	reply, err := redis.String(conn.Do("SET", _keyOnlineExpire, 1, "EX", expire, "NX"))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		log.Error("conn.Do(SET %s) error(%v)", _keyOnlineExpire, err)
+		return
+	}
+	return reply == "OK", nil
+}
+
+// DelServerOnline del the room counts of a server from all the servers.
+func (d *Dao) DelServerOnline(c context.Context, server string) (err error) {
+	if err = d.RenewServerOnline(c, server, map[string]int32{}, true, 0); err != nil {
+		return
+	}
+	conn := d.redis.Get()
+	defer conn.Close()
+	if err = conn.Send("DEL", keyServerRooms(server)); err != nil {
+		log.Error("conn.Send(DEL %s) error(%v)", keyServerRooms(server), err)
+		return
+	}
+	if err = conn.Send("ZREM", _keyServersOnline, server); err != nil {
+		log.Error("conn.Send(ZREM %s) error(%v)", server, err)
+		return
+	}
+	if err = conn.Flush(); err != nil {
+		log.Error("conn.Flush() error(%v)", err)
+		return
+	}
+	for i := 0; i < 2; i++ {
+		if _, err = conn.Receive(); err != nil {
+			log.Error("conn.Receive() error(%v)", err)
+			return
+		}
+	}
+	return
+}
+
+// ExpiredServersOnline get the servers not updated since before.
+func (d *Dao) ExpiredServersOnline(c context.Context, before int64) (servers []string, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if servers, err = redis.Strings(conn.Do("ZRANGEBYSCORE", _keyServersOnline, "-inf", before)); err != nil {
+		log.Error("conn.Do(ZRANGEBYSCORE %s,%d) error(%v)", _keyServersOnline, before, err)
+	}
+	return
+}
+
+// RoomsOnline get the counts of rooms.
+func (d *Dao) RoomsOnline(c context.Context, rooms []string) (counts []int, err error) {
+	if len(rooms) == 0 {
+		return
+	}
+	conn := d.redis.Get()
+	defer conn.Close()
+	if counts, err = redis.Ints(conn.Do("HMGET", redis.Args{}.Add(_keyRoomsOnline).AddFlat(rooms)...)); err != nil {
+		log.Error("conn.Do(HMGET %s,%d) error(%v)", _keyRoomsOnline, len(rooms), err)
+	}
+	return
+}
+
+// TopRooms get the top n rooms of a type by count, n is at most _maxTopRooms.
+func (d *Dao) TopRooms(c context.Context, typ string, n int) (tops []*model.Top, err error) {
+	if n <= 0 {
+		return
+	}
+	if n > _maxTopRooms {
+		n = _maxTopRooms
+	}
+	conn := d.redis.Get()
+	defer conn.Close()
+	values, err := redis.Strings(conn.Do("ZREVRANGE", keyTopRooms(typ), 0, n-1, "WITHSCORES"))
+	if err != nil {
+		log.Error("conn.Do(ZREVRANGE %s,%d) error(%v)", keyTopRooms(typ), n, err)
+		return
+	}
+	for i := 0; i+1 < len(values); i += 2 {
+		count, e := strconv.ParseInt(values[i+1], 10, 32)
+		if e != nil {
+			continue
+		}
+		tops = append(tops, &model.Top{RoomID: values[i], Count: int32(count)})
+	}
+	return
+}
+
+// RoomServers get the servers hosting the room.
+func (d *Dao) RoomServers(c context.Context, room string, servers []string) (res []string, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	for _, server := range servers {
+		if err = conn.Send("HEXISTS", keyServerRooms(server), room); err != nil {
+			log.Error("conn.Send(HEXISTS %s,%s) error(%v)", server, room, err)
+			return
+		}
+	}
+	if err = conn.Flush(); err != nil {
+		log.Error("conn.Flush() error(%v)", err)
+		return
+	}
+	for _, server := range servers {
+		var ok bool
+		if ok, err = redis.Bool(conn.Receive()); err != nil {
+			log.Error("conn.Receive() error(%v)", err)
+			return
+		}
+		if ok {
+			res = append(res, server)
+		}
+	}
+	return
+}
@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaoRenewServerOnline(t *testing.T) {
+	var (
+		c       = context.Background()
+		server1 = "test_online_server_01"
+		server2 = "test_online_server_02"
+		room    = "test://online_room"
+	)
+	_ = d.DelServerOnline(c, server1)
+	_ = d.DelServerOnline(c, server2)
+	since := unixMilli(time.Now()) - 1
+	err := d.RenewServerOnline(c, server1, map[string]int32{room: 10}, true, 100)
+	assert.Nil(t, err)
+	err = d.RenewServerOnline(c, server2, map[string]int32{room: 5}, true, 100)
+	assert.Nil(t, err)
+	all, err := d.AllRoomsOnline(c)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(15), all[room])
+	// delta
+	err = d.RenewServerOnline(c, server1, map[string]int32{room: 20}, false, 101)
+	assert.Nil(t, err)
+	changed, last, err := d.ChangedRoomsOnline(c, since)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(25), changed[room])
+	assert.True(t, last > since)
+	ok, err := d.LockOnlineExpire(c, 1)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	counts, err := d.RoomsOnline(c, []string{room, "test://none"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{25, 0}, counts)
+	tops, err := d.TopRooms(c, "test", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "online_room", tops[0].RoomID)
+	assert.Equal(t, int32(25), tops[0].Count)
+	tops, err = d.TopRooms(c, "test", 0)
+	assert.Nil(t, err)
+	assert.Empty(t, tops)
+	servers, err := d.RoomServers(c, room, []string{server1, "test_online_none", server2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{server1, server2}, servers)
+	servers, err = d.ExpiredServersOnline(c, 100)
+	assert.Nil(t, err)
+	assert.Contains(t, servers, server2)
+	assert.NotContains(t, servers, server1)
+	// full without the room
+	err = d.RenewServerOnline(c, server2, map[string]int32{}, true, 102)
+	assert.Nil(t, err)
+	err = d.DelServerOnline(c, server1)
+	assert.Nil(t, err)
+	counts, err = d.RoomsOnline(c, []string{room})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, counts)
+	err = d.DelServerOnline(c, server2)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,157 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	_prefixServerKeys = "srv_%s"  // server -> mid:key scored by the last heartbeat
+	_prefixDeadServer = "dead_%s" // server -> cleaning
+)
+
+func keyServerKeys(server string) string {
+	return fmt.Sprintf(_prefixServerKeys, server)
+}
+
+func keyDeadServer(server string) string {
+	return fmt.Sprintf(_prefixDeadServer, server)
+}
+
+// serverKey is the member of a connection in the server keys index.
+func serverKey(mid int64, key string) string {
+	return strconv.FormatInt(mid, 10) + ":" + key
+}
+
+func parseServerKey(member string) (mid int64, key string, ok bool) {
+	i := strings.IndexByte(member, ':')
+	if i < 0 {
+		return
+	}
+	mid, err := strconv.ParseInt(member[:i], 10, 64)
+	if err != nil {
+		return
+	}
+	return mid, member[i+1:], true
+}
+
+// _delServerMappings deletes the mappings still on the server, the ones moved
+// to another server or heartbeated after the fence are kept.
+// KEYS are the server keys index, then the key_server key of every connection,
+// followed by its mid_server and session keys if it has a mid.
+// ARGV are the server, the fence, then the connection key, the server keys
+// member and whether it has a mid of every connection.
+// It returns the mids have no connection left.
+var _delServerMappings = redis.NewScript(-1, `
+local mids = {}
+local k = 2
+for i = 1, (#ARGV - 2) / 3 do
+	local key, member, hasMid = ARGV[3*i], ARGV[3*i+1], ARGV[3*i+2] == '1'
+	local keyServer, midServer, session = KEYS[k], KEYS[k+1], KEYS[k+2]
+	k = k + 1
+	if hasMid then
+		k = k + 2
+	end
+	local score = redis.call('ZSCORE', KEYS[1], member)
+	if score and tonumber(score) <= tonumber(ARGV[2]) then
+		if redis.call('GET', keyServer) == ARGV[1] then
+			redis.call('DEL', keyServer)
+		end
+		if hasMid and redis.call('HGET', midServer, key) == ARGV[1] then
+			redis.call('HDEL', midServer, key)
+			redis.call('HDEL', session, key)
+			if redis.call('EXISTS', midServer) == 0 then
+				mids[#mids+1] = string.sub(member, 1, string.find(member, ':', 1, true) - 1)
+			end
+		end
+		redis.call('ZREM', KEYS[1], member)
+	end
+end
+return mids
+`)
+
+// LockDeadServer lock the cleanup of a dead server, ok is false if it's locked by another logic.
+func (d *Dao) LockDeadServer(c context.Context, server string, expire int32) (ok bool, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	reply, err := redis.String(conn.Do("SET", keyDeadServer(server), 1, "EX", expire, "NX"))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		log.Error("conn.Do(SET %s) error(%v)", keyDeadServer(server), err)
+		return
+	}
+	return reply == "OK", nil
+}
+
+// ServerKeys scan the connections indexed on a server, next is 0 if the scan is done.
+func (d *Dao) ServerKeys(c context.Context, server string, cursor int64, count int) (next int64, mids []int64, keys []string, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	values, err := redis.Values(conn.Do("ZSCAN", keyServerKeys(server), cursor, "COUNT", count))
+	if err != nil {
+		log.Error("conn.Do(ZSCAN %s,%d) error(%v)", server, cursor, err)
+		return
+	}
+	var pairs []string
+	if _, err = redis.Scan(values, &next, &pairs); err != nil {
+		log.Error("redis.Scan(%s,%d) error(%v)", server, cursor, err)
+		return
+	}
+	// the member and score pairs
+	for i := 0; i < len(pairs); i += 2 {
+		member := pairs[i]
+		mid, key, ok := parseServerKey(member)
+		if !ok {
+			log.Warn("invalid server key member:%s", member)
+			continue
+		}
+		mids = append(mids, mid)
+		keys = append(keys, key)
+	}
+	return
+}
+
+// DelServerMappings del the mappings of the connections still on server and
+// not heartbeated after fence, offMids are the mids have no connection left.
+func (d *Dao) DelServerMappings(c context.Context, server string, fence int64, mids []int64, keys []string) (offMids []int64, err error) {
+	if len(keys) == 0 {
+		return
+	}
+	skeys := make([]interface{}, 0, len(keys)*3+1)
+	argv := make([]interface{}, 0, len(keys)*3+2)
+	skeys = append(skeys, keyServerKeys(server))
+	argv = append(argv, server, fence)
+	for i, key := range keys {
+		skeys = append(skeys, keyKeyServer(key))
+		hasMid := 0
+		if mids[i] > 0 {
+			skeys = append(skeys, keyMidServer(mids[i]), keyMidSession(mids[i]))
+			hasMid = 1
+		}
+		argv = append(argv, key, serverKey(mids[i], key), hasMid)
+	}
+	args := append(append([]interface{}{len(skeys)}, skeys...), argv...)
+	conn := d.redis.Get()
+	defer conn.Close()
+	if offMids, err = redis.Int64s(_delServerMappings.Do(conn, args...)); err != nil {
+		log.Error("delServerMappings(%s,%d) error(%v)", server, len(keys), err)
+	}
+	return
+}
+
+// DelServerKeys del the connections of a server not heartbeated after fence from the index.
+func (d *Dao) DelServerKeys(c context.Context, server string, fence int64) (err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if _, err = conn.Do("ZREMRANGEBYSCORE", keyServerKeys(server), "-inf", fence); err != nil {
+		log.Error("conn.Do(ZREMRANGEBYSCORE %s,%d) error(%v)", keyServerKeys(server), fence, err)
+	}
+	return
+}
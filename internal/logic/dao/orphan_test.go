@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaoServerKeys(t *testing.T) {
+	var (
+		c      = context.Background()
+		mid    = int64(4000)
+		key    = "test_orphan_key"
+		moved  = "test_orphan_moved"
+		server = "test_orphan_server"
+		fence  = time.Now().Unix()
+	)
+	err := d.AddMapping(c, mid, key, server, nil)
+	assert.Nil(t, err)
+	err = d.AddMapping(c, mid, moved, server, nil)
+	assert.Nil(t, err)
+	err = d.AddMapping(c, mid, moved, "test_other_server", nil)
+	assert.Nil(t, err)
+	ok, err := d.LockDeadServer(c, server, 10)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = d.LockDeadServer(c, server, 10)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	next, mids, keys, err := d.ServerKeys(c, server, 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), next)
+	assert.ElementsMatch(t, []string{key, moved}, keys)
+	offMids, err := d.DelServerMappings(c, server, fence, mids, keys)
+	assert.Nil(t, err)
+	assert.Empty(t, offMids)
+	servers, err := d.ServersByKeys(c, []string{key, moved})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"", "test_other_server"}, servers)
+	err = d.DelServerKeys(c, server, fence)
+	assert.Nil(t, err)
+}
+
+func TestParseServerKey(t *testing.T) {
+	mid, key, ok := parseServerKey(serverKey(1, "a:b"))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), mid)
+	assert.Equal(t, "a:b", key)
+	_, _, ok = parseServerKey("invalid")
+	assert.False(t, ok)
+}
@@ -0,0 +1,69 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	_keyPresenceDue = "presence_due" // zset mid by heartbeat deadline
+)
+
+// _claimPresences pops the mids whose heartbeat deadline passed atomically,
+// so that an expire is handled by only one logic instance.
+var _claimPresences = redis.NewScript(1, `
+local mids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+local res = {}
+for _, mid in ipairs(mids) do
+	if redis.call('ZREM', KEYS[1], mid) == 1 then
+		table.insert(res, mid)
+	end
+end
+return res
+`)
+
+// TouchPresence set the heartbeat deadline of mid.
+func (d *Dao) TouchPresence(c context.Context, mid int64, deadline int64) (err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if _, err = conn.Do("ZADD", _keyPresenceDue, deadline, mid); err != nil {
+		log.Error("conn.Do(ZADD %s,%d,%d) error(%v)", _keyPresenceDue, deadline, mid, err)
+	}
+	return
+}
+
+// DelPresence del the heartbeat deadline of mid.
+func (d *Dao) DelPresence(c context.Context, mid int64) (err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if _, err = conn.Do("ZREM", _keyPresenceDue, mid); err != nil {
+		log.Error("conn.Do(ZREM %s,%d) error(%v)", _keyPresenceDue, mid, err)
+	}
+	return
+}
+
+// ClaimPresences claim the mids whose heartbeat deadline is before now.
+func (d *Dao) ClaimPresences(c context.Context, now int64, limit int) (mids []int64, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if mids, err = redis.Int64s(_claimPresences.Do(conn, _keyPresenceDue, now, limit)); err != nil {
+		log.Error("claimPresences(%d,%d) error(%v)", now, limit, err)
+	}
+	return
+}
+
+// PublishPresence publish a presence change event.
+func (d *Dao) PublishPresence(c context.Context, ev *model.PresenceEvent) (err error) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	if err = d.nsqPub.Publish(d.c.Presence.Topic, b); err != nil {
+		log.Error("PublishPresence(%+v) error(%v)", ev, err)
+	}
+	return
+}
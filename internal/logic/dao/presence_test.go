@@ -0,0 +1,22 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaoPresence(t *testing.T) {
+	var (
+		c   = context.Background()
+		mid = int64(2000)
+	)
+	err := d.TouchPresence(c, mid, 100)
+	assert.Nil(t, err)
+	mids, err := d.ClaimPresences(c, 101, 10)
+	assert.Nil(t, err)
+	assert.Contains(t, mids, mid)
+	err = d.DelPresence(c, mid)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+	"github.com/ningchengzeng/goim/modal"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	_prefixMidRead = "rd_%d" // mid -> conversation:msgID
+)
+
+func keyMidRead(mid int64) string {
+	return fmt.Sprintf(_prefixMidRead, mid)
+}
+
+// _setReadCursor only moves the cursor forward, the msgIDs are lower hex
+// object ids of the same width, so the string order is the time order.
+// A cursor not of the same width is overwritten.
+var _setReadCursor = redis.NewScript(1, `
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if cur and #cur == #ARGV[2] and cur >= ARGV[2] then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return 1
+`)
+
+// SetReadCursor set the read cursor of a conversation, ok is false if the cursor was not moved.
+func (d *Dao) SetReadCursor(c context.Context, mid int64, conv, msgID string) (ok bool, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if ok, err = redis.Bool(_setReadCursor.Do(conn, keyMidRead(mid), conv, msgID)); err != nil {
+		log.Error("setReadCursor(%d,%s,%s) error(%v)", mid, conv, msgID, err)
+	}
+	return
+}
+
+// ReadCursors get the read cursors of the conversations.
+func (d *Dao) ReadCursors(c context.Context, mid int64, convs []string) (res map[string]string, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	args := []interface{}{keyMidRead(mid)}
+	for _, conv := range convs {
+		args = append(args, conv)
+	}
+	ids, err := redis.Strings(conn.Do("HMGET", args...))
+	if err != nil {
+		log.Error("conn.Do(HMGET %d,%v) error(%v)", mid, convs, err)
+		return
+	}
+	res = make(map[string]string, len(convs))
+	for i, conv := range convs {
+		if ids[i] != "" {
+			res[conv] = ids[i]
+		}
+	}
+	return
+}
+
+// SetMessagesRead mark the messages from peer to mid read up to msgID.
+func (d *Dao) SetMessagesRead(c context.Context, mid, peer int64, msgID string) (err error) {
+	if d.mongo == nil {
+		return
+	}
+	cursor, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return
+	}
+	msg := &modal.Message{UserId: strconv.FormatInt(peer, 10), ObjectUserId: strconv.FormatInt(mid, 10)}
+	msg.SetReadBefore(d.mongo, cursor)
+	return
+}
+
+// UnreadCount count the unread messages of mid from peer or in room after the cursor msgID.
+func (d *Dao) UnreadCount(c context.Context, mid, peer int64, room, msgID string) (count int64, err error) {
+	if d.mongo == nil {
+		return
+	}
+	var cursor primitive.ObjectID
+	if msgID != "" {
+		if cursor, err = primitive.ObjectIDFromHex(msgID); err != nil {
+			return
+		}
+	}
+	msg := &modal.Message{Type: modal.UserMessage, UserId: strconv.FormatInt(peer, 10), ObjectUserId: strconv.FormatInt(mid, 10)}
+	if room != "" {
+		msg = &modal.Message{Type: modal.RoomMessage, RoomId: room, ObjectUserId: strconv.FormatInt(mid, 10)}
+	}
+	count = msg.CountUnread(d.mongo, cursor)
+	return
+}
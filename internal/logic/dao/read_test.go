@@ -0,0 +1,29 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDaoReadCursor(t *testing.T) {
+	var (
+		c     = context.Background()
+		mid   = int64(3000)
+		conv  = "user:3001"
+		older = primitive.NewObjectID().Hex()
+		newer = primitive.NewObjectID().Hex()
+	)
+	ok, err := d.SetReadCursor(c, mid, conv, newer)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = d.SetReadCursor(c, mid, conv, older)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	res, err := d.ReadCursors(c, mid, []string{conv, "user:3002"})
+	assert.Nil(t, err)
+	assert.Equal(t, newer, res[conv])
+	assert.Equal(t, 1, len(res))
+}
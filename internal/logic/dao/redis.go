@@ -4,19 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"time"
 
 	log "github.com/go-kratos/kratos/pkg/log"
 	"github.com/gomodule/redigo/redis"
 	"github.com/ningchengzeng/goim/internal/logic/model"
-
-	"github.com/zhenjl/cityhash"
 )
 
 const (
-	_prefixMidServer    = "mid_%d" // mid -> key:server
-	_prefixKeyServer    = "key_%s" // key -> server
-	_prefixServerOnline = "ol_%s"  // server -> online
+	_prefixMidServer = "mid_%d"       // mid -> key:server
+	_prefixKeyServer = "key_%s"       // key -> server
+	_prefixKeyClosed = "closed_%s"    // key -> disconnected
+	_prefixMsgID     = "msg_%d_%s_%s" // op, target, msgID -> pushed
 )
 
 func keyMidServer(mid int64) string {
@@ -27,8 +26,12 @@ func keyKeyServer(key string) string {
 	return fmt.Sprintf(_prefixKeyServer, key)
 }
 
-func keyServerOnline(key string) string {
-	return fmt.Sprintf(_prefixServerOnline, key)
+func keyKeyClosed(key string) string {
+	return fmt.Sprintf(_prefixKeyClosed, key)
+}
+
+func keyMsgID(t *model.MsgTarget) string {
+	return fmt.Sprintf(_prefixMsgID, t.Op, t.Target, t.MsgID)
 }
 
 // pingRedis check redis connection.
@@ -39,14 +42,28 @@ func (d *Dao) pingRedis(c context.Context) (err error) {
 	return
 }
 
-// AddMapping add a mapping.
+// AddMapping add a mapping, s is the session metadata of the key, nil keeps the old one.
 // Mapping:
+//
 //	mid -> key_server
+//	mid -> key:session
 //	key -> server
-func (d *Dao) AddMapping(c context.Context, mid int64, key, server string) (err error) {
+//	server -> mid:key scored by the last heartbeat
+func (d *Dao) AddMapping(c context.Context, mid int64, key, server string, s *model.Session) (err error) {
+	var b []byte
+	if mid > 0 && s != nil {
+		if b, err = json.Marshal(s); err != nil {
+			return
+		}
+	}
 	conn := d.redis.Get()
 	defer conn.Close()
-	var n = 2
+	var n = 5
+	// the key connected again
+	if err = conn.Send("DEL", keyKeyClosed(key)); err != nil {
+		log.Error("conn.Send(DEL %s) error(%v)", keyKeyClosed(key), err)
+		return
+	}
 	if mid > 0 {
 		if err = conn.Send("HSET", keyMidServer(mid), key, server); err != nil {
 			log.Error("conn.Send(HSET %d,%s,%s) error(%v)", mid, server, key, err)
@@ -57,6 +74,18 @@ func (d *Dao) AddMapping(c context.Context, mid int64, key, server string) (err
 			return
 		}
 		n += 2
+		if b != nil {
+			if err = conn.Send("HSET", keyMidSession(mid), key, b); err != nil {
+				log.Error("conn.Send(HSET %s,%s) error(%v)", keyMidSession(mid), key, err)
+				return
+			}
+			n++
+		}
+		if err = conn.Send("EXPIRE", keyMidSession(mid), d.redisExpire); err != nil {
+			log.Error("conn.Send(EXPIRE %s) error(%v)", keyMidSession(mid), err)
+			return
+		}
+		n++
 	}
 	if err = conn.Send("SET", keyKeyServer(key), server); err != nil {
 		log.Error("conn.Send(HSET %d,%s,%s) error(%v)", mid, server, key, err)
@@ -66,6 +95,14 @@ func (d *Dao) AddMapping(c context.Context, mid int64, key, server string) (err
 		log.Error("conn.Send(EXPIRE %d,%s,%s) error(%v)", mid, key, server, err)
 		return
 	}
+	if err = conn.Send("ZADD", keyServerKeys(server), time.Now().Unix(), serverKey(mid, key)); err != nil {
+		log.Error("conn.Send(ZADD %d,%s,%s) error(%v)", mid, key, server, err)
+		return
+	}
+	if err = conn.Send("EXPIRE", keyServerKeys(server), d.redisExpire); err != nil {
+		log.Error("conn.Send(EXPIRE %d,%s,%s) error(%v)", mid, key, server, err)
+		return
+	}
 	if err = conn.Flush(); err != nil {
 		log.Error("conn.Flush() error(%v)", err)
 		return
@@ -79,17 +116,38 @@ func (d *Dao) AddMapping(c context.Context, mid int64, key, server string) (err
 	return
 }
 
-// ExpireMapping expire a mapping.
-func (d *Dao) ExpireMapping(c context.Context, mid int64, key string) (has bool, err error) {
+// ExpireMapping expire a mapping and the sessions of mid.
+func (d *Dao) ExpireMapping(c context.Context, mid int64, key, server string) (has bool, err error) {
 	conn := d.redis.Get()
 	defer conn.Close()
-	var n = 1
+	var (
+		n   = 4
+		now = time.Now().Unix()
+	)
+	// the score of the connection in the server keys index is the last heartbeat,
+	// the ones not renewed within the expire are removed
+	if err = conn.Send("ZADD", keyServerKeys(server), "XX", now, serverKey(mid, key)); err != nil {
+		log.Error("conn.Send(ZADD %d,%s,%s) error(%v)", mid, key, server, err)
+		return
+	}
+	if err = conn.Send("ZREMRANGEBYSCORE", keyServerKeys(server), "-inf", now-int64(d.redisExpire)); err != nil {
+		log.Error("conn.Send(ZREMRANGEBYSCORE %s) error(%v)", keyServerKeys(server), err)
+		return
+	}
+	if err = conn.Send("EXPIRE", keyServerKeys(server), d.redisExpire); err != nil {
+		log.Error("conn.Send(EXPIRE %s) error(%v)", keyServerKeys(server), err)
+		return
+	}
 	if mid > 0 {
 		if err = conn.Send("EXPIRE", keyMidServer(mid), d.redisExpire); err != nil {
 			log.Error("conn.Send(EXPIRE %d,%s) error(%v)", mid, key, err)
 			return
 		}
-		n++
+		if err = conn.Send("EXPIRE", keyMidSession(mid), d.redisExpire); err != nil {
+			log.Error("conn.Send(EXPIRE %s) error(%v)", keyMidSession(mid), err)
+			return
+		}
+		n += 2
 	}
 	if err = conn.Send("EXPIRE", keyKeyServer(key), d.redisExpire); err != nil {
 		log.Error("conn.Send(EXPIRE %d,%s) error(%v)", mid, key, err)
@@ -108,17 +166,30 @@ func (d *Dao) ExpireMapping(c context.Context, mid int64, key string) (has bool,
 	return
 }
 
-// DelMapping del a mapping.
+// DelMapping del a mapping, the key is marked closed so that a heartbeat
+// renewed after the disconnect doesn't add it again.
 func (d *Dao) DelMapping(c context.Context, mid int64, key, server string) (has bool, err error) {
 	conn := d.redis.Get()
 	defer conn.Close()
-	n := 1
+	n := 2
+	if err = conn.Send("SET", keyKeyClosed(key), 1, "EX", d.redisExpire); err != nil {
+		log.Error("conn.Send(SET %s) error(%v)", keyKeyClosed(key), err)
+		return
+	}
+	if err = conn.Send("ZREM", keyServerKeys(server), serverKey(mid, key)); err != nil {
+		log.Error("conn.Send(ZREM %d,%s,%s) error(%v)", mid, key, server, err)
+		return
+	}
 	if mid > 0 {
 		if err = conn.Send("HDEL", keyMidServer(mid), key); err != nil {
 			log.Error("conn.Send(HDEL %d,%s,%s) error(%v)", mid, key, server, err)
 			return
 		}
-		n++
+		if err = conn.Send("HDEL", keyMidSession(mid), key); err != nil {
+			log.Error("conn.Send(HDEL %s,%s) error(%v)", keyMidSession(mid), key, err)
+			return
+		}
+		n += 2
 	}
 	if err = conn.Send("DEL", keyKeyServer(key)); err != nil {
 		log.Error("conn.Send(HDEL %d,%s,%s) error(%v)", mid, key, server, err)
@@ -128,6 +199,10 @@ func (d *Dao) DelMapping(c context.Context, mid int64, key, server string) (has
 		log.Error("conn.Flush() error(%v)", err)
 		return
 	}
+	if _, err = conn.Receive(); err != nil {
+		log.Error("conn.Receive() error(%v)", err)
+		return
+	}
 	for i := 0; i < n; i++ {
 		if has, err = redis.Bool(conn.Receive()); err != nil {
 			log.Error("conn.Receive() error(%v)", err)
@@ -184,96 +259,92 @@ func (d *Dao) KeysByMids(c context.Context, mids []int64) (ress map[string]strin
 	return
 }
 
-// AddServerOnline add a server online.
-func (d *Dao) AddServerOnline(c context.Context, server string, online *model.Online) (err error) {
-	roomsMap := map[uint32]map[string]int32{}
-	for room, count := range online.RoomCount {
-		rMap := roomsMap[cityhash.CityHash32([]byte(room), uint32(len(room)))%64]
-		if rMap == nil {
-			rMap = make(map[string]int32)
-			roomsMap[cityhash.CityHash32([]byte(room), uint32(len(room)))%64] = rMap
-		}
-		rMap[room] = count
-	}
-	key := keyServerOnline(server)
-	for hashKey, value := range roomsMap {
-		err = d.addServerOnline(c, key, strconv.FormatInt(int64(hashKey), 10), &model.Online{RoomCount: value, Server: online.Server, Updated: online.Updated})
-		if err != nil {
-			return
-		}
+// KeyClosed check the key was disconnected and not connected again.
+func (d *Dao) KeyClosed(c context.Context, key string) (closed bool, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if closed, err = redis.Bool(conn.Do("EXISTS", keyKeyClosed(key))); err != nil {
+		log.Error("conn.Do(EXISTS %s) error(%v)", keyKeyClosed(key), err)
 	}
 	return
 }
 
-func (d *Dao) addServerOnline(c context.Context, key string, hashKey string, online *model.Online) (err error) {
+// KeyServersByMids get the key servers of every mid in one pipeline.
+func (d *Dao) KeyServersByMids(c context.Context, mids []int64) (ress map[int64]map[string]string, err error) {
 	conn := d.redis.Get()
 	defer conn.Close()
-	b, _ := json.Marshal(online)
-	if err = conn.Send("HSET", key, hashKey, b); err != nil {
-		log.Error("conn.Send(SET %s,%s) error(%v)", key, hashKey, err)
-		return
-	}
-	if err = conn.Send("EXPIRE", key, d.redisExpire); err != nil {
-		log.Error("conn.Send(EXPIRE %s) error(%v)", key, err)
-		return
+	ress = make(map[int64]map[string]string, len(mids))
+	for _, mid := range mids {
+		if err = conn.Send("HGETALL", keyMidServer(mid)); err != nil {
+			log.Error("conn.Do(HGETALL %d) error(%v)", mid, err)
+			return
+		}
 	}
 	if err = conn.Flush(); err != nil {
 		log.Error("conn.Flush() error(%v)", err)
 		return
 	}
-	for i := 0; i < 2; i++ {
-		if _, err = conn.Receive(); err != nil {
+	for idx := 0; idx < len(mids); idx++ {
+		var (
+			res map[string]string
+		)
+		if res, err = redis.StringMap(conn.Receive()); err != nil {
 			log.Error("conn.Receive() error(%v)", err)
 			return
 		}
-	}
-	return
-}
-
-// ServerOnline get a server online.
-func (d *Dao) ServerOnline(c context.Context, server string) (online *model.Online, err error) {
-	online = &model.Online{RoomCount: map[string]int32{}}
-	key := keyServerOnline(server)
-	for i := 0; i < 64; i++ {
-		ol, err := d.serverOnline(c, key, strconv.FormatInt(int64(i), 10))
-		if err == nil && ol != nil {
-			online.Server = ol.Server
-			if ol.Updated > online.Updated {
-				online.Updated = ol.Updated
-			}
-			for room, count := range ol.RoomCount {
-				online.RoomCount[room] = count
-			}
+		if len(res) > 0 {
+			ress[mids[idx]] = res
 		}
 	}
 	return
 }
 
-func (d *Dao) serverOnline(c context.Context, key string, hashKey string) (online *model.Online, err error) {
+// AddMsgIDs mark the message ids pushed to the targets for expire seconds by
+// one round trip, oks[i] is false if ts[i] was already marked.
+func (d *Dao) AddMsgIDs(c context.Context, ts []*model.MsgTarget, expire int32) (oks []bool, err error) {
+	if len(ts) == 0 {
+		return
+	}
 	conn := d.redis.Get()
 	defer conn.Close()
-	b, err := redis.Bytes(conn.Do("HGET", key, hashKey))
-	if err != nil {
-		if err != redis.ErrNil {
-			log.Error("conn.Do(HGET %s %s) error(%v)", key, hashKey, err)
+	for _, t := range ts {
+		if err = conn.Send("SET", keyMsgID(t), "1", "EX", expire, "NX"); err != nil {
+			log.Error("conn.Send(SET %s NX) error(%v)", keyMsgID(t), err)
+			return
 		}
-		return
 	}
-	online = new(model.Online)
-	if err = json.Unmarshal(b, online); err != nil {
-		log.Error("serverOnline json.Unmarshal(%s) error(%v)", b, err)
+	if err = conn.Flush(); err != nil {
+		log.Error("conn.Flush() error(%v)", err)
 		return
 	}
+	oks = make([]bool, len(ts))
+	for i := range ts {
+		if _, err = redis.String(conn.Receive()); err != nil {
+			if err != redis.ErrNil {
+				log.Error("conn.Receive() error(%v)", err)
+				return
+			}
+			err = nil
+			continue
+		}
+		oks[i] = true
+	}
 	return
 }
 
-// DelServerOnline del a server online.
-func (d *Dao) DelServerOnline(c context.Context, server string) (err error) {
+// DelMsgIDs unmark the message ids pushed to the targets.
+func (d *Dao) DelMsgIDs(c context.Context, ts []*model.MsgTarget) (err error) {
+	if len(ts) == 0 {
+		return
+	}
 	conn := d.redis.Get()
 	defer conn.Close()
-	key := keyServerOnline(server)
-	if _, err = conn.Do("DEL", key); err != nil {
-		log.Error("conn.Do(DEL %s) error(%v)", key, err)
+	args := make([]interface{}, 0, len(ts))
+	for _, t := range ts {
+		args = append(args, keyMsgID(t))
+	}
+	if _, err = conn.Do("DEL", args...); err != nil {
+		log.Error("conn.Do(DEL %d) error(%v)", len(args), err)
 	}
 	return
 }
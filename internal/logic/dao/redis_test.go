@@ -20,15 +20,15 @@ func TestDaoAddMapping(t *testing.T) {
 		key    = "test_key"
 		server = "test_server"
 	)
-	err := d.AddMapping(c, 0, "test", server)
+	err := d.AddMapping(c, 0, "test", server, nil)
 	assert.Nil(t, err)
-	err = d.AddMapping(c, mid, key, server)
+	err = d.AddMapping(c, mid, key, server, nil)
 	assert.Nil(t, err)
 
-	has, err := d.ExpireMapping(c, 0, "test")
+	has, err := d.ExpireMapping(c, 0, "test", server)
 	assert.Nil(t, err)
 	assert.NotEqual(t, false, has)
-	has, err = d.ExpireMapping(c, mid, key)
+	has, err = d.ExpireMapping(c, mid, key, server)
 	assert.Nil(t, err)
 	assert.NotEqual(t, false, has)
 
@@ -49,21 +49,34 @@ func TestDaoAddMapping(t *testing.T) {
 	assert.NotEqual(t, false, has)
 }
 
-func TestDaoAddServerOnline(t *testing.T) {
+func TestDaoMsgID(t *testing.T) {
 	var (
-		c      = context.Background()
-		server = "test_server"
-		online = &model.Online{
-			RoomCount: map[string]int32{"room": 10},
+		c  = context.Background()
+		ts = []*model.MsgTarget{
+			{Op: 1000, Target: "test_server", MsgID: "test_msg_id"},
+			{Op: 1000, Target: model.TargetAll, MsgID: "test_msg_id"},
 		}
 	)
-	err := d.AddServerOnline(c, server, online)
+	oks, err := d.AddMsgIDs(c, ts[:1], 60)
 	assert.Nil(t, err)
-
-	r, err := d.ServerOnline(c, server)
+	assert.Equal(t, []bool{true}, oks)
+	oks, err = d.AddMsgIDs(c, ts, 60)
 	assert.Nil(t, err)
-	assert.Equal(t, online.RoomCount["room"], r.RoomCount["room"])
+	assert.Equal(t, []bool{false, true}, oks)
+	err = d.DelMsgIDs(c, ts)
+	assert.Nil(t, err)
+}
 
-	err = d.DelServerOnline(c, server)
+func TestDaoKeyServersByMids(t *testing.T) {
+	var (
+		c      = context.Background()
+		mid    = int64(1)
+		key    = "test_key"
+		server = "test_server"
+	)
+	err := d.AddMapping(c, mid, key, server, nil)
+	assert.Nil(t, err)
+	res, err := d.KeyServersByMids(c, []int64{mid, 2})
 	assert.Nil(t, err)
+	assert.Equal(t, server, res[mid][key])
 }
@@ -0,0 +1,130 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	_keySchedules    = "schedules"     // id -> schedule
+	_keySchedulesDue = "schedules_due" // zset id by fire time
+)
+
+// _claimSchedules leases the due schedules atomically, so that a schedule is
+// claimed by only one logic instance. A claimed schedule is rescored to the
+// lease deadline, it's claimed again after the lease unless it's done.
+var _claimSchedules = redis.NewScript(2, `
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+local res = {}
+for _, id in ipairs(ids) do
+	local v = redis.call('HGET', KEYS[2], id)
+	if v then
+		redis.call('ZADD', KEYS[1], ARGV[3], id)
+		table.insert(res, v)
+	else
+		redis.call('ZREM', KEYS[1], id)
+	end
+end
+return res
+`)
+
+// AddSchedule add a schedule.
+func (d *Dao) AddSchedule(c context.Context, s *model.Schedule) (err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	b, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	if err = conn.Send("HSET", _keySchedules, s.ID, b); err != nil {
+		log.Error("conn.Send(HSET %s,%s) error(%v)", _keySchedules, s.ID, err)
+		return
+	}
+	if err = conn.Send("ZADD", _keySchedulesDue, s.FireAt, s.ID); err != nil {
+		log.Error("conn.Send(ZADD %s,%d,%s) error(%v)", _keySchedulesDue, s.FireAt, s.ID, err)
+		return
+	}
+	if err = conn.Flush(); err != nil {
+		log.Error("conn.Flush() error(%v)", err)
+		return
+	}
+	for i := 0; i < 2; i++ {
+		if _, err = conn.Receive(); err != nil {
+			log.Error("conn.Receive() error(%v)", err)
+			return
+		}
+	}
+	return
+}
+
+// Schedules get all the pending schedules.
+func (d *Dao) Schedules(c context.Context) (res []*model.Schedule, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	values, err := redis.ByteSlices(conn.Do("HVALS", _keySchedules))
+	if err != nil {
+		log.Error("conn.Do(HVALS %s) error(%v)", _keySchedules, err)
+		return
+	}
+	return decodeSchedules(values), nil
+}
+
+// CancelSchedule delete a pending schedule.
+func (d *Dao) CancelSchedule(c context.Context, id string) (has bool, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	if err = conn.Send("ZREM", _keySchedulesDue, id); err != nil {
+		log.Error("conn.Send(ZREM %s,%s) error(%v)", _keySchedulesDue, id, err)
+		return
+	}
+	if err = conn.Send("HDEL", _keySchedules, id); err != nil {
+		log.Error("conn.Send(HDEL %s,%s) error(%v)", _keySchedules, id, err)
+		return
+	}
+	if err = conn.Flush(); err != nil {
+		log.Error("conn.Flush() error(%v)", err)
+		return
+	}
+	if has, err = redis.Bool(conn.Receive()); err != nil {
+		log.Error("conn.Receive() error(%v)", err)
+		return
+	}
+	if _, err = conn.Receive(); err != nil {
+		log.Error("conn.Receive() error(%v)", err)
+	}
+	return
+}
+
+// ClaimSchedules claim the schedules due before now until now+lease.
+func (d *Dao) ClaimSchedules(c context.Context, now, lease int64, limit int) (res []*model.Schedule, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	values, err := redis.ByteSlices(_claimSchedules.Do(conn, _keySchedulesDue, _keySchedules, now, limit, now+lease))
+	if err != nil {
+		log.Error("claimSchedules(%d,%d) error(%v)", now, limit, err)
+		return
+	}
+	return decodeSchedules(values), nil
+}
+
+// DoneSchedule delete a fired schedule.
+func (d *Dao) DoneSchedule(c context.Context, id string) (err error) {
+	_, err = d.CancelSchedule(c, id)
+	return
+}
+
+func decodeSchedules(values [][]byte) (res []*model.Schedule) {
+	for _, b := range values {
+		s := new(model.Schedule)
+		if err := json.Unmarshal(b, s); err != nil {
+			log.Error("schedule json.Unmarshal(%s) error(%v)", b, err)
+			continue
+		}
+		res = append(res, s)
+	}
+	return
+}
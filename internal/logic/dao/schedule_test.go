@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaoSchedule(t *testing.T) {
+	var (
+		c   = context.TODO()
+		now = time.Now().Unix()
+		s   = &model.Schedule{ID: "test_schedule", Type: model.ScheduleAll, Op: 100, Msg: []byte("hello"), FireAt: now - 1}
+	)
+	err := d.AddSchedule(c, s)
+	assert.Nil(t, err)
+	res, err := d.Schedules(c)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, res)
+	res, err = d.ClaimSchedules(c, now, 60, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, s.ID, res[0].ID)
+	res, err = d.ClaimSchedules(c, now, 60, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(res))
+	res, err = d.ClaimSchedules(c, now+61, 60, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	err = d.DoneSchedule(c, s.ID)
+	assert.Nil(t, err)
+	has, err := d.CancelSchedule(c, s.ID)
+	assert.Nil(t, err)
+	assert.False(t, has)
+}
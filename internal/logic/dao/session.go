@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/gomodule/redigo/redis"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	_prefixMidSession = "ses_%d" // mid -> key:session
+)
+
+func keyMidSession(mid int64) string {
+	return fmt.Sprintf(_prefixMidSession, mid)
+}
+
+// SessionsByMids get the sessions of mids.
+func (d *Dao) SessionsByMids(c context.Context, mids []int64) (res map[int64][]*model.Session, err error) {
+	conn := d.redis.Get()
+	defer conn.Close()
+	for _, mid := range mids {
+		if err = conn.Send("HVALS", keyMidSession(mid)); err != nil {
+			log.Error("conn.Send(HVALS %d) error(%v)", mid, err)
+			return
+		}
+	}
+	if err = conn.Flush(); err != nil {
+		log.Error("conn.Flush() error(%v)", err)
+		return
+	}
+	res = make(map[int64][]*model.Session, len(mids))
+	for _, mid := range mids {
+		var bs [][]byte
+		if bs, err = redis.ByteSlices(conn.Receive()); err != nil {
+			log.Error("conn.Receive() error(%v)", err)
+			return
+		}
+		for _, b := range bs {
+			s := new(model.Session)
+			if e := json.Unmarshal(b, s); e != nil {
+				log.Error("json.Unmarshal(%s) error(%v)", b, e)
+				continue
+			}
+			res[mid] = append(res[mid], s)
+		}
+	}
+	return
+}
@@ -0,0 +1,32 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaoSession(t *testing.T) {
+	var (
+		c   = context.Background()
+		mid = int64(1)
+		s   = &model.Session{Key: "test_session_key", Server: "test_server", Platform: "web", Connected: 1}
+	)
+	err := d.AddMapping(c, mid, s.Key, s.Server, s)
+	assert.Nil(t, err)
+	has, err := d.ExpireMapping(c, mid, s.Key, s.Server)
+	assert.Nil(t, err)
+	assert.True(t, has)
+	res, err := d.SessionsByMids(c, []int64{mid})
+	assert.Nil(t, err)
+	assert.Equal(t, s.Platform, res[mid][0].Platform)
+	_, err = d.DelMapping(c, mid, s.Key, s.Server)
+	assert.Nil(t, err)
+	res, err = d.SessionsByMids(c, []int64{mid})
+	assert.Nil(t, err)
+	for _, ses := range res[mid] {
+		assert.NotEqual(t, s.Key, ses.Key)
+	}
+}
@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/internal/logic/conf"
+	"github.com/ningchengzeng/goim/internal/logic/filter"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+// _filterOperator is the audit operator of the messages flagged by the content filter.
+const _filterOperator = "filter"
+
+// ErrMessageBlocked the message is blocked by the content filter.
+var ErrMessageBlocked = errors.New("message blocked")
+
+// newFilterChain build the keyword and regexp rules, then the moderation service,
+// an invalid rule is skipped.
+func newFilterChain(c *conf.Filter) (chain filter.Chain) {
+	for _, r := range c.Rules {
+		action, err := filter.ParseAction(r.Action)
+		if err != nil {
+			log.Error("filter rule:%s action:%s error(%v)", r.Name, r.Action, err)
+			continue
+		}
+		if len(r.Words) > 0 {
+			chain = append(chain, filter.NewKeyword(r.Name, action, r.Words))
+		}
+		if len(r.Patterns) > 0 {
+			re, err := filter.NewRegexp(r.Name, action, r.Patterns)
+			if err != nil {
+				log.Error("filter rule:%s patterns:%v error(%v)", r.Name, r.Patterns, err)
+				continue
+			}
+			chain = append(chain, re)
+		}
+	}
+	if c.Moderator != "" {
+		chain = append(chain, filter.NewHTTP(c.Moderator, time.Duration(c.Timeout)))
+	}
+	return
+}
+
+// ReloadFilter rebuild the filter chain from the filter config, it's called after the config reloaded.
+func (l *Logic) ReloadFilter() {
+	chain := newFilterChain(l.c.Filter)
+	l.filtersMutex.Lock()
+	l.filters = chain
+	l.filtersMutex.Unlock()
+}
+
+func (l *Logic) contentFilter() (chain filter.Chain) {
+	l.filtersMutex.RLock()
+	chain = l.filters
+	l.filtersMutex.RUnlock()
+	return
+}
+
+// filterMessage run the content filter on a message, the returned body is masked,
+// a blocked message returns ErrMessageBlocked and a flagged message is recorded as a violation.
+// A failed filter is skipped, so that an unavailable moderation service doesn't stop the messages.
+func (l *Logic) filterMessage(c context.Context, typ string, m *filter.Message) (body []byte, err error) {
+	if !l.c.Filter.Enable {
+		return m.Body, nil
+	}
+	res, e := l.contentFilter().Filter(c, m)
+	if e != nil {
+		log.Error("filter mid:%d room:%s op:%d error(%v)", m.Mid, m.Room, m.Op, e)
+	}
+	switch res.Action {
+	case filter.Block:
+		log.Info("filter block mid:%d room:%s op:%d reason:%s", m.Mid, m.Room, m.Op, res.Reason)
+		return nil, ErrMessageBlocked
+	case filter.Flag:
+		l.flagMessage(c, typ, m, res)
+	}
+	return res.Body, nil
+}
+
+// flagMessage store the flagged message as a violation with an audit record.
+func (l *Logic) flagMessage(c context.Context, typ string, m *filter.Message, res *filter.Result) {
+	msg := &model.HistoryMessage{
+		Type:     typ,
+		Room:     m.Room,
+		Body:     string(res.Body),
+		SendTime: time.Now().Unix(),
+	}
+	if m.Mid != 0 {
+		msg.From = strconv.FormatInt(m.Mid, 10)
+	}
+	id, err := l.messages.AddViolation(c, msg)
+	if err != nil {
+		log.Error("l.messages.AddViolation(%d,%s) error(%v)", m.Mid, m.Room, err)
+		return
+	}
+	if id == "" {
+		return
+	}
+	if err = l.messages.AddAudit(c, &model.Audit{Operator: _filterOperator, Action: model.ActionViolation, MsgID: id, Reason: res.Reason}); err != nil {
+		log.Error("l.messages.AddAudit(%s) error(%v)", id, err)
+	}
+}
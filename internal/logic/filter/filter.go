@@ -0,0 +1,175 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Action is what to do with a filtered message, the greater is the more severe.
+type Action int
+
+const (
+	// Allow the message as it is.
+	Allow Action = iota
+	// Mask the matched content and deliver the message.
+	Mask
+	// Flag the message as a violation and deliver it.
+	Flag
+	// Block the message, it is not delivered.
+	Block
+)
+
+var actionNames = map[Action]string{
+	Allow: "allow",
+	Mask:  "mask",
+	Flag:  "flag",
+	Block: "block",
+}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// ParseAction parse the action name.
+func ParseAction(name string) (Action, error) {
+	for a, n := range actionNames {
+		if n == name {
+			return a, nil
+		}
+	}
+	return Allow, fmt.Errorf("unknown filter action: %s", name)
+}
+
+// Message is a message to filter.
+type Message struct {
+	Mid  int64  `json:"mid,omitempty"`
+	Room string `json:"room,omitempty"`
+	Op   int32  `json:"op"`
+	Body []byte `json:"body"`
+}
+
+// Result is the result of a filter, Body is the masked body if the action is mask.
+type Result struct {
+	Action Action
+	Body   []byte
+	Reason string
+}
+
+// Filter checks the message content.
+type Filter interface {
+	Filter(c context.Context, m *Message) (*Result, error)
+}
+
+// Chain runs the filters in order, it stops at the first block,
+// the masked body is passed to the next filters, and the result is the most severe action.
+type Chain []Filter
+
+// Filter filter the message by the chain, a failed filter is skipped and the first error is returned with the result.
+func (ch Chain) Filter(c context.Context, m *Message) (res *Result, err error) {
+	res = &Result{Action: Allow, Body: m.Body}
+	msg := *m
+	for _, f := range ch {
+		r, e := f.Filter(c, &msg)
+		if e != nil {
+			if err == nil {
+				err = e
+			}
+			continue
+		}
+		switch r.Action {
+		case Block:
+			return r, err
+		case Mask:
+			msg.Body = r.Body
+			res.Body = r.Body
+		}
+		if r.Action > res.Action {
+			res.Action = r.Action
+			res.Reason = r.Reason
+		}
+	}
+	return
+}
+
+// Keyword matches the message by the keywords.
+type Keyword struct {
+	name   string
+	action Action
+	words  []string
+}
+
+// NewKeyword new a keyword filter, the empty words are ignored.
+func NewKeyword(name string, action Action, words []string) *Keyword {
+	k := &Keyword{name: name, action: action}
+	for _, w := range words {
+		if w != "" {
+			k.words = append(k.words, w)
+		}
+	}
+	return k
+}
+
+// Filter filter the message by the keywords.
+func (k *Keyword) Filter(c context.Context, m *Message) (*Result, error) {
+	body := string(m.Body)
+	matched := false
+	for _, w := range k.words {
+		if !strings.Contains(body, w) {
+			continue
+		}
+		matched = true
+		if k.action != Mask {
+			break
+		}
+		body = strings.Replace(body, w, strings.Repeat("*", utf8.RuneCountInString(w)), -1)
+	}
+	if !matched {
+		return &Result{Action: Allow, Body: m.Body}, nil
+	}
+	return &Result{Action: k.action, Body: []byte(body), Reason: k.name}, nil
+}
+
+// Regexp matches the message by the regular expressions.
+type Regexp struct {
+	name     string
+	action   Action
+	patterns []*regexp.Regexp
+}
+
+// NewRegexp new a regexp filter.
+func NewRegexp(name string, action Action, patterns []string) (*Regexp, error) {
+	r := &Regexp{name: name, action: action}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Filter filter the message by the regular expressions.
+func (r *Regexp) Filter(c context.Context, m *Message) (*Result, error) {
+	body := m.Body
+	matched := false
+	for _, re := range r.patterns {
+		if !re.Match(body) {
+			continue
+		}
+		matched = true
+		if r.action != Mask {
+			break
+		}
+		body = re.ReplaceAllFunc(body, func(s []byte) []byte {
+			return []byte(strings.Repeat("*", utf8.RuneCount(s)))
+		})
+	}
+	if !matched {
+		return &Result{Action: Allow, Body: m.Body}, nil
+	}
+	return &Result{Action: r.action, Body: body, Reason: r.name}, nil
+}
@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	c := context.Background()
+	phone, err := NewRegexp("phone", Mask, []string{`1[3-9]\d{9}`})
+	assert.Nil(t, err)
+	chain := Chain{
+		NewKeyword("mask", Mask, []string{"笨蛋"}),
+		phone,
+		NewKeyword("flag", Flag, []string{"spam"}),
+		NewKeyword("block", Block, []string{"forbidden"}),
+	}
+	res, err := chain.Filter(c, &Message{Body: []byte("hello")})
+	assert.Nil(t, err)
+	assert.Equal(t, Allow, res.Action)
+	assert.Equal(t, "hello", string(res.Body))
+
+	res, err = chain.Filter(c, &Message{Body: []byte("笨蛋 call 13800138000")})
+	assert.Nil(t, err)
+	assert.Equal(t, Mask, res.Action)
+	assert.Equal(t, "** call ***********", string(res.Body))
+
+	res, err = chain.Filter(c, &Message{Body: []byte("笨蛋 spam")})
+	assert.Nil(t, err)
+	assert.Equal(t, Flag, res.Action)
+	assert.Equal(t, "flag", res.Reason)
+	assert.Equal(t, "** spam", string(res.Body))
+
+	res, err = chain.Filter(c, &Message{Body: []byte("spam forbidden")})
+	assert.Nil(t, err)
+	assert.Equal(t, Block, res.Action)
+	assert.Equal(t, "block", res.Reason)
+}
+
+func TestHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m struct {
+			Body string `json:"body"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		if m.Body == "bad" {
+			w.Write([]byte(`{"action":"block","reason":"abuse"}`))
+			return
+		}
+		w.Write([]byte(`{"action":"allow"}`))
+	}))
+	defer srv.Close()
+	chain := Chain{NewHTTP(srv.URL, time.Second)}
+	res, err := chain.Filter(context.Background(), &Message{Body: []byte("bad")})
+	assert.Nil(t, err)
+	assert.Equal(t, Block, res.Action)
+	assert.Equal(t, "abuse", res.Reason)
+	res, err = chain.Filter(context.Background(), &Message{Body: []byte("good")})
+	assert.Nil(t, err)
+	assert.Equal(t, Allow, res.Action)
+	// an unavailable service is skipped
+	chain = Chain{NewHTTP("http://127.0.0.1:1", time.Second), NewKeyword("block", Block, []string{"bad"})}
+	res, err = chain.Filter(context.Background(), &Message{Body: []byte("bad")})
+	assert.NotNil(t, err)
+	assert.Equal(t, Block, res.Action)
+}
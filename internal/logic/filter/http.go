@@ -0,0 +1,70 @@
+package filter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTP asks an external moderation service to review the message.
+//
+// The service receives the message as json and replies
+// {"action":"allow|mask|flag|block","body":"the masked body","reason":"..."}.
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP new a http moderation filter.
+func NewHTTP(url string, timeout time.Duration) *HTTP {
+	return &HTTP{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Filter post the message to the moderation service.
+func (h *HTTP) Filter(c context.Context, m *Message) (res *Result, err error) {
+	body, err := json.Marshal(struct {
+		*Message
+		Body string `json:"body"`
+	}{m, string(m.Body)})
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequest("POST", h.url, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req.WithContext(c))
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("filter %s status code: %d", h.url, resp.StatusCode)
+	}
+	var reply struct {
+		Action string `json:"action"`
+		Body   string `json:"body"`
+		Reason string `json:"reason"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return
+	}
+	res = &Result{Body: m.Body, Reason: reply.Reason}
+	if reply.Action == "" {
+		return
+	}
+	if res.Action, err = ParseAction(reply.Action); err != nil {
+		return nil, err
+	}
+	if res.Action == Mask {
+		res.Body = []byte(reply.Body)
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ningchengzeng/goim/internal/logic/conf"
+	"github.com/ningchengzeng/goim/internal/logic/filter"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterMessage(t *testing.T) {
+	var (
+		c     = context.Background()
+		store = newTestMessageStore()
+	)
+	oldConf, oldStore := lg.c.Filter, lg.messages
+	lg.messages = store
+	defer func() {
+		lg.c.Filter, lg.messages = oldConf, oldStore
+		lg.ReloadFilter()
+	}()
+	lg.c.Filter = &conf.Filter{Enable: true, Rules: []*conf.FilterRule{
+		{Name: "block", Action: "block", Words: []string{"forbidden"}},
+		{Name: "flag", Action: "flag", Words: []string{"spam"}},
+	}}
+	lg.ReloadFilter()
+	_, err := lg.filterMessage(c, model.HistoryRoom, &filter.Message{Room: "test://test_room", Body: []byte("forbidden")})
+	assert.Equal(t, ErrMessageBlocked, err)
+	body, err := lg.filterMessage(c, model.HistoryUser, &filter.Message{Mid: 1, Body: []byte("spam")})
+	assert.Nil(t, err)
+	assert.Equal(t, "spam", string(body))
+	assert.Equal(t, 1, len(store.flags))
+	assert.Equal(t, "1", store.flags[0].From)
+	audits, _ := store.Audits(c, store.flags[0].ID)
+	assert.Equal(t, 1, len(audits))
+	// reloaded rules, the invalid rule is skipped
+	lg.c.Filter = &conf.Filter{Enable: true, Rules: []*conf.FilterRule{
+		{Name: "invalid", Action: "block", Patterns: []string{"("}},
+		{Name: "mask", Action: "mask", Words: []string{"forbidden"}},
+	}}
+	lg.ReloadFilter()
+	body, err = lg.filterMessage(c, model.HistoryRoom, &filter.Message{Body: []byte("forbidden")})
+	assert.Nil(t, err)
+	assert.Equal(t, "*********", string(body))
+}
@@ -0,0 +1,159 @@
+package grpc
+
+import (
+	"context"
+
+	pb "github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/internal/logic"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type pushServer struct {
+	srv *logic.Logic
+}
+
+var _ pb.LogicPushServer = &pushServer{}
+
+// errCodes are the grpc codes of the logic errors, the others are internal.
+var errCodes = map[error]codes.Code{
+	logic.ErrPriorityArg:     codes.InvalidArgument,
+	logic.ErrPushBatchArg:    codes.InvalidArgument,
+	logic.ErrScheduleArg:     codes.InvalidArgument,
+	logic.ErrDeviceArg:       codes.InvalidArgument,
+	logic.ErrHistoryArg:      codes.InvalidArgument,
+	logic.ErrReadArg:         codes.InvalidArgument,
+	logic.ErrBanArg:          codes.InvalidArgument,
+	logic.ErrMessageNotFound: codes.NotFound,
+	logic.ErrMessageBlocked:  codes.PermissionDenied,
+	logic.ErrRecallDenied:    codes.PermissionDenied,
+	logic.ErrHistoryDenied:   codes.PermissionDenied,
+	logic.ErrReadDenied:      codes.PermissionDenied,
+}
+
+// pushErr wraps a logic error as a grpc status.
+func pushErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if code, ok := errCodes[err]; ok {
+		return status.Error(code, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// PushKeys push a message by keys.
+func (s *pushServer) PushKeys(ctx context.Context, req *pb.PushKeysReq) (*pb.PushKeysReply, error) {
+	if len(req.Keys) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "keys is empty")
+	}
+	report, err := s.srv.PushKeys(ctx, req.Operation, req.Priority, req.MsgID, req.Keys, req.Msg)
+	if err != nil {
+		return nil, pushErr(err)
+	}
+	return &pb.PushKeysReply{Report: report}, nil
+}
+
+// PushMids push a message by mids.
+func (s *pushServer) PushMids(ctx context.Context, req *pb.PushMidsReq) (*pb.PushMidsReply, error) {
+	if len(req.Mids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "mids is empty")
+	}
+	var filter *model.SessionFilter
+	if len(req.Platforms) > 0 || req.Latest {
+		filter = &model.SessionFilter{Platforms: req.Platforms, Latest: req.Latest}
+	}
+	report, err := s.srv.PushMids(ctx, req.Operation, req.Priority, req.MsgID, req.Mids, filter, req.Msg)
+	if err != nil {
+		return nil, pushErr(err)
+	}
+	return &pb.PushMidsReply{Report: report}, nil
+}
+
+// PushRoom push a message to a room.
+func (s *pushServer) PushRoom(ctx context.Context, req *pb.PushRoomReq) (*pb.PushRoomReply, error) {
+	if req.Operation == 0 || req.Type == "" || req.Room == "" {
+		return nil, status.Error(codes.InvalidArgument, "operation, type and room are required")
+	}
+	if err := s.srv.PushRoom(ctx, req.Operation, req.Priority, req.MsgID, req.Type, req.Room, req.Msg); err != nil {
+		return nil, pushErr(err)
+	}
+	return &pb.PushRoomReply{}, nil
+}
+
+// PushAll push a message to all.
+func (s *pushServer) PushAll(ctx context.Context, req *pb.PushAllReq) (*pb.PushAllReply, error) {
+	if req.Operation == 0 {
+		return nil, status.Error(codes.InvalidArgument, "operation is required")
+	}
+	if err := s.srv.PushAll(ctx, req.Operation, req.Speed, req.Priority, req.MsgID, req.Msg); err != nil {
+		return nil, pushErr(err)
+	}
+	return &pb.PushAllReply{}, nil
+}
+
+// PushBatch push many messages in one request.
+func (s *pushServer) PushBatch(ctx context.Context, req *pb.PushBatchReq) (*pb.PushBatchReply, error) {
+	if len(req.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "items is empty")
+	}
+	if err := s.srv.PushBatch(ctx, req.Priority, req.Items); err != nil {
+		return nil, pushErr(err)
+	}
+	return &pb.PushBatchReply{}, nil
+}
+
+// OnlineTop get the top online rooms.
+func (s *pushServer) OnlineTop(ctx context.Context, req *pb.OnlineTopReq) (*pb.OnlineTopReply, error) {
+	if req.Type == "" || req.Limit <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "type and limit are required")
+	}
+	tops, err := s.srv.OnlineTop(ctx, req.Type, int(req.Limit))
+	if err != nil {
+		return nil, pushErr(err)
+	}
+	reply := &pb.OnlineTopReply{Tops: make([]*pb.OnlineTopReply_Top, 0, len(tops))}
+	for _, top := range tops {
+		reply.Tops = append(reply.Tops, &pb.OnlineTopReply_Top{RoomID: top.RoomID, Count: top.Count})
+	}
+	return reply, nil
+}
+
+// OnlineRoom get the online of rooms.
+func (s *pushServer) OnlineRoom(ctx context.Context, req *pb.OnlineRoomReq) (*pb.OnlineRoomReply, error) {
+	if req.Type == "" || len(req.Rooms) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "type and rooms are required")
+	}
+	rooms, err := s.srv.OnlineRoom(ctx, req.Type, req.Rooms)
+	if err != nil {
+		return nil, pushErr(err)
+	}
+	return &pb.OnlineRoomReply{Rooms: rooms}, nil
+}
+
+// OnlineTotal get the total online.
+func (s *pushServer) OnlineTotal(ctx context.Context, req *pb.OnlineTotalReq) (*pb.OnlineTotalReply, error) {
+	ipCount, connCount := s.srv.OnlineTotal(ctx)
+	return &pb.OnlineTotalReply{IpCount: ipCount, ConnCount: connCount}, nil
+}
+
+// Presence get the online state of mids.
+func (s *pushServer) Presence(ctx context.Context, req *pb.PresenceReq) (*pb.PresenceReply, error) {
+	if len(req.Mids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "mids is empty")
+	}
+	presences, err := s.srv.Presences(ctx, req.Mids)
+	if err != nil {
+		return nil, pushErr(err)
+	}
+	reply := &pb.PresenceReply{Online: make(map[int64]bool, len(presences))}
+	for _, p := range presences {
+		reply.Online[p.Mid] = p.Online
+	}
+	return reply, nil
+}
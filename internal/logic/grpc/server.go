@@ -8,6 +8,7 @@ import (
 	pb "github.com/ningchengzeng/goim/api/logic"
 	"github.com/ningchengzeng/goim/internal/logic"
 	"github.com/ningchengzeng/goim/internal/logic/conf"
+	"github.com/ningchengzeng/goim/internal/logic/model"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
@@ -27,6 +28,7 @@ func New(c *conf.RPCServer, l *logic.Logic) *grpc.Server {
 	})
 	srv := grpc.NewServer(keepParams)
 	pb.RegisterLogicServer(srv, &server{l})
+	pb.RegisterLogicPushServer(srv, &pushServer{l})
 	lis, err := net.Listen(c.Network, c.Addr)
 	if err != nil {
 		panic(err)
@@ -71,9 +73,25 @@ func (s *server) Heartbeat(ctx context.Context, req *pb.HeartbeatReq) (*pb.Heart
 	return &pb.HeartbeatReply{}, nil
 }
 
+// Heartbeats renew the heartbeats of many connections.
+func (s *server) Heartbeats(ctx context.Context, req *pb.HeartbeatsReq) (*pb.HeartbeatReply, error) {
+	if err := s.srv.Heartbeats(ctx, req.Server, toHeartbeats(req.Heartbeats)); err != nil {
+		return &pb.HeartbeatReply{}, err
+	}
+	return &pb.HeartbeatReply{}, nil
+}
+
+func toHeartbeats(reqs []*pb.HeartbeatReq) []*model.Heartbeat {
+	hbs := make([]*model.Heartbeat, 0, len(reqs))
+	for _, req := range reqs {
+		hbs = append(hbs, &model.Heartbeat{Mid: req.Mid, Key: req.Key})
+	}
+	return hbs
+}
+
 // RenewOnline renew server online.
 func (s *server) RenewOnline(ctx context.Context, req *pb.OnlineReq) (*pb.OnlineReply, error) {
-	allRoomCount, err := s.srv.RenewOnline(ctx, req.Server, req.RoomCount)
+	allRoomCount, err := s.srv.RenewOnline(ctx, req.Server, req.RoomCount, !req.Delta)
 	if err != nil {
 		return &pb.OnlineReply{}, err
 	}
@@ -82,12 +100,29 @@ func (s *server) RenewOnline(ctx context.Context, req *pb.OnlineReq) (*pb.Online
 
 // Receive receive a message.
 func (s *server) Receive(ctx context.Context, req *pb.ReceiveReq) (*pb.ReceiveReply, error) {
-	if err := s.srv.Receive(ctx, req.Mid, req.Proto); err != nil {
-		return &pb.ReceiveReply{}, err
+	if err := s.srv.Receive(ctx, req.Mid, req.RoomID, req.Proto); err != nil {
+		return &pb.ReceiveReply{}, pushErr(err)
 	}
 	return &pb.ReceiveReply{}, nil
 }
 
+// Signal route an ephemeral signal.
+func (s *server) Signal(ctx context.Context, req *pb.SignalReq) (*pb.SignalReply, error) {
+	if err := s.srv.Signal(ctx, req.Mid, req.Key, req.RoomID, req.Proto); err != nil {
+		return &pb.SignalReply{}, err
+	}
+	return &pb.SignalReply{}, nil
+}
+
+// History query the history messages.
+func (s *server) History(ctx context.Context, req *pb.HistoryReq) (*pb.HistoryReply, error) {
+	body, err := s.srv.ReceiveHistory(ctx, req.Mid, req.RoomID, req.Proto)
+	if err != nil {
+		return &pb.HistoryReply{}, pushErr(err)
+	}
+	return &pb.HistoryReply{Body: body}, nil
+}
+
 // nodes return nodes.
 func (s *server) Nodes(ctx context.Context, req *pb.NodesReq) (*pb.NodesReply, error) {
 	return s.srv.NodesWeighted(ctx, req.Platform, req.ClientIP), nil
@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	pb "github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/pkg/room"
+)
+
+// _eventWorkers is the workers handling the events of a stream.
+const _eventWorkers = 32
+
+// eventStream is the state of a comet event stream.
+type eventStream struct {
+	srv    *server
+	stream pb.Logic_EventsServer
+	mutex  sync.Mutex // grpc stream send is not safe for concurrent use
+	// sent is the room counts of the last online reply acked by the comet,
+	// pending is the room counts of the last reply of seq pendingSeq.
+	sent       map[string]int32
+	pending    map[string]int32
+	pendingSeq int64
+}
+
+// Events serve the connection events of a comet, the online events are handled in order,
+// the others concurrently by the workers, as a comet only sends the next event of a connection after replied.
+func (s *server) Events(stream pb.Logic_EventsServer) error {
+	es := &eventStream{srv: s, stream: stream}
+	events := make(chan *pb.ConnEvent, _eventWorkers)
+	defer close(events)
+	for i := 0; i < _eventWorkers; i++ {
+		go func() {
+			for ev := range events {
+				es.handle(stream.Context(), ev)
+			}
+		}()
+	}
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if ev.Type == pb.ConnEvent_ONLINE {
+			es.online(stream.Context(), ev)
+			continue
+		}
+		events <- ev
+	}
+}
+
+func (es *eventStream) send(reply *pb.ConnEventReply) {
+	es.mutex.Lock()
+	err := es.stream.Send(reply)
+	es.mutex.Unlock()
+	if err != nil {
+		log.Error("stream.Send(%v,%d) error(%v)", reply.Type, reply.Seq, err)
+	}
+}
+
+func (es *eventStream) handle(c context.Context, ev *pb.ConnEvent) {
+	var (
+		err   error
+		reply = &pb.ConnEventReply{Type: ev.Type, Seq: ev.Seq}
+		l     = es.srv.srv
+	)
+	switch ev.Type {
+	case pb.ConnEvent_CONNECT:
+		req := ev.Connect
+		if req == nil {
+			return
+		}
+		r := new(pb.ConnectReply)
+		r.Mid, r.Key, r.RoomID, r.Accepts, r.Heartbeat, err = l.Connect(c, req.Server, req.Cookie, req.Token)
+		reply.Connect = r
+	case pb.ConnEvent_DISCONNECT:
+		req := ev.Disconnect
+		if req == nil {
+			return
+		}
+		r := new(pb.DisconnectReply)
+		r.Has, err = l.Disconnect(c, req.Mid, req.Key, req.Server)
+		reply.Disconnect = r
+	case pb.ConnEvent_HEARTBEAT:
+		if len(ev.Heartbeats) == 0 {
+			return
+		}
+		err = l.Heartbeats(c, ev.Heartbeats[0].Server, toHeartbeats(ev.Heartbeats))
+	case pb.ConnEvent_RECEIVE:
+		req := ev.Receive
+		if req == nil {
+			return
+		}
+		err = l.Receive(c, req.Mid, req.RoomID, req.Proto)
+	}
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	if ev.Seq != 0 {
+		es.send(reply)
+	}
+}
+
+// online apply the room count delta of the comet, then reply the changes of the room counts
+// since the last reply acked by the comet, a reply the comet didn't apply is sent again.
+func (es *eventStream) online(c context.Context, ev *pb.ConnEvent) {
+	reply := &pb.ConnEventReply{Type: ev.Type, Seq: ev.Seq}
+	if delta := ev.Online; delta != nil {
+		if delta.Ack != 0 && delta.Ack == es.pendingSeq {
+			es.sent = es.pending
+		}
+		all, err := es.srv.srv.RenewOnline(c, delta.Server, delta.RoomCount, delta.Full)
+		if err != nil {
+			reply.Error = err.Error()
+		} else {
+			reply.Online = &pb.OnlineDelta{Full: es.sent == nil}
+			if es.sent == nil {
+				reply.Online.RoomCount = all
+			} else {
+				reply.Online.RoomCount = room.CountDelta(es.sent, all)
+			}
+			es.pending, es.pendingSeq = all, ev.Seq
+		}
+	}
+	if ev.Seq != 0 {
+		es.send(reply)
+	}
+}
@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	_historyLimit    = 20
+	_historyMaxLimit = 100
+)
+
+var (
+	// ErrHistoryArg history arg error.
+	ErrHistoryArg = errors.New("history arg error")
+	// ErrHistoryDenied history of a room not joined or of an anonymous user.
+	ErrHistoryDenied = errors.New("history denied")
+)
+
+// MessageStore is the storage of the history messages.
+type MessageStore interface {
+	// History get the messages of the query ordered by id desc.
+	History(c context.Context, q *model.HistoryQuery) ([]*model.HistoryMessage, error)
+	// Message get a message by id, nil if not found.
+	Message(c context.Context, id string) (*model.HistoryMessage, error)
+	// DelMessage mark a message deleted, it's hidden from the history.
+	DelMessage(c context.Context, id string) error
+	// SetMessageViolation mark a message as a violation, it's hidden from the history.
+	SetMessageViolation(c context.Context, id string) error
+	// AddViolation store a message flagged by the content filter as a violation, return the message id.
+	AddViolation(c context.Context, m *model.HistoryMessage) (string, error)
+	// AddAudit add an audit record of a moderated message.
+	AddAudit(c context.Context, a *model.Audit) error
+	// Audits get the audit records of a message.
+	Audits(c context.Context, msgID string) ([]*model.Audit, error)
+}
+
+// History get a page of the history messages.
+func (l *Logic) History(c context.Context, q *model.HistoryQuery) (res *model.History, err error) {
+	switch q.Type {
+	case model.HistoryUser:
+		if q.Mid == 0 || q.Peer == 0 {
+			return nil, ErrHistoryArg
+		}
+	case model.HistoryRoom:
+		if q.Room == "" {
+			return nil, ErrHistoryArg
+		}
+	case model.HistoryBroadcast:
+	default:
+		return nil, ErrHistoryArg
+	}
+	if q.Limit <= 0 {
+		q.Limit = _historyLimit
+	} else if q.Limit > _historyMaxLimit {
+		q.Limit = _historyMaxLimit
+	}
+	msgs, err := l.messages.History(c, q)
+	if err != nil {
+		return
+	}
+	res = &model.History{Messages: msgs}
+	if len(msgs) == q.Limit {
+		res.Cursor = msgs[len(msgs)-1].ID
+	}
+	return
+}
+
+// ReceiveHistory handle the history op from the client, the mid of a user history is always the client itself,
+// and the client could only query the room it joined.
+func (l *Logic) ReceiveHistory(c context.Context, mid int64, roomID string, p *protocol.Proto) (body []byte, err error) {
+	var q model.HistoryQuery
+	if err = json.Unmarshal(p.Body, &q); err != nil {
+		return nil, ErrHistoryArg
+	}
+	q.Mid = mid
+	switch q.Type {
+	case model.HistoryUser:
+		if mid <= 0 {
+			return nil, ErrHistoryDenied
+		}
+	case model.HistoryRoom:
+		if q.Room == "" || q.Room != roomID {
+			return nil, ErrHistoryDenied
+		}
+	}
+	res, err := l.History(c, &q)
+	if err != nil {
+		return
+	}
+	return json.Marshal(res)
+}
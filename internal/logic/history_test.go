@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMessageStore is a message store in memory, the messages are ordered by id desc.
+type testMessageStore struct {
+	MessageStore
+	msgs   []*model.HistoryMessage
+	audits []*model.Audit
+	dels   map[string]string
+	flags  []*model.HistoryMessage
+	// failID is the message id fails to mark
+	failID string
+}
+
+func (s *testMessageStore) History(c context.Context, q *model.HistoryQuery) ([]*model.HistoryMessage, error) {
+	var res []*model.HistoryMessage
+	for _, m := range s.msgs {
+		if q.Cursor != "" && m.ID >= q.Cursor {
+			continue
+		}
+		if len(res) == q.Limit {
+			break
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+func (s *testMessageStore) Message(c context.Context, id string) (*model.HistoryMessage, error) {
+	for _, m := range s.msgs {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *testMessageStore) DelMessage(c context.Context, id string) error {
+	if id == s.failID {
+		return errors.New("mark failed")
+	}
+	s.dels[id] = model.ActionDelete
+	return nil
+}
+
+func (s *testMessageStore) SetMessageViolation(c context.Context, id string) error {
+	s.dels[id] = model.ActionViolation
+	return nil
+}
+
+func (s *testMessageStore) AddViolation(c context.Context, m *model.HistoryMessage) (string, error) {
+	m.ID = "flag_" + strconv.Itoa(len(s.flags))
+	s.flags = append(s.flags, m)
+	return m.ID, nil
+}
+
+func (s *testMessageStore) AddAudit(c context.Context, a *model.Audit) error {
+	s.audits = append(s.audits, a)
+	return nil
+}
+
+func (s *testMessageStore) Audits(c context.Context, msgID string) (res []*model.Audit, err error) {
+	for _, a := range s.audits {
+		if a.MsgID == msgID {
+			res = append(res, a)
+		}
+	}
+	return
+}
+
+func newTestMessageStore() *testMessageStore {
+	s := &testMessageStore{dels: make(map[string]string)}
+	for i := 9; i >= 0; i-- {
+		s.msgs = append(s.msgs, &model.HistoryMessage{ID: strconv.Itoa(i), Type: model.HistoryRoom, From: "1", Room: "test://test_room"})
+	}
+	return s
+}
+
+func TestHistory(t *testing.T) {
+	var (
+		c = context.Background()
+	)
+	old := lg.messages
+	lg.messages = newTestMessageStore()
+	defer func() { lg.messages = old }()
+	res, err := lg.History(c, &model.HistoryQuery{Type: model.HistoryRoom, Room: "test://test_room", Limit: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(res.Messages))
+	assert.Equal(t, "6", res.Cursor)
+	res, err = lg.History(c, &model.HistoryQuery{Type: model.HistoryRoom, Room: "test://test_room", Limit: 4, Cursor: "2"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res.Messages))
+	assert.Empty(t, res.Cursor)
+	_, err = lg.History(c, &model.HistoryQuery{Type: model.HistoryUser, Mid: 1})
+	assert.Equal(t, ErrHistoryArg, err)
+}
+
+func TestReceiveHistory(t *testing.T) {
+	var (
+		c = context.Background()
+	)
+	old := lg.messages
+	lg.messages = newTestMessageStore()
+	defer func() { lg.messages = old }()
+	body, err := lg.ReceiveHistory(c, 1, "test://test_room", &protocol.Proto{Body: []byte(`{"type":"room","room":"test://test_room","limit":4}`)})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, body)
+	_, err = lg.ReceiveHistory(c, 1, "test://other_room", &protocol.Proto{Body: []byte(`{"type":"room","room":"test://test_room"}`)})
+	assert.Equal(t, ErrHistoryDenied, err)
+	_, err = lg.ReceiveHistory(c, 0, "", &protocol.Proto{Body: []byte(`{"type":"user","peer":2}`)})
+	assert.Equal(t, ErrHistoryDenied, err)
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) ban(c *gin.Context) {
+	var arg struct {
+		IPs  []string `form:"ips"`
+		Mids []int64  `form:"mids"`
+		// TTL is the ban seconds, zero is forever
+		TTL int64 `form:"ttl"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if err := s.logic.Ban(context.TODO(), arg.IPs, arg.Mids, time.Duration(arg.TTL)*time.Second); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, nil, OK)
+}
+
+func (s *Server) unban(c *gin.Context) {
+	var arg struct {
+		IPs  []string `form:"ips"`
+		Mids []int64  `form:"mids"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if err := s.logic.Unban(context.TODO(), arg.IPs, arg.Mids); err != nil {
+		errors(c, ServerErr, err.Error())
+		return
+	}
+	result(c, nil, OK)
+}
+
+func (s *Server) bans(c *gin.Context) {
+	res, err := s.logic.Bans(context.TODO())
+	if err != nil {
+		errors(c, ServerErr, err.Error())
+		return
+	}
+	result(c, res, OK)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) deviceRegister(c *gin.Context) {
+	var arg struct {
+		Mid      int64  `form:"mid" binding:"required"`
+		Platform string `form:"platform" binding:"required"`
+		Token    string `form:"token" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if err := s.logic.AddDevice(context.TODO(), arg.Mid, arg.Platform, arg.Token); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, nil, OK)
+}
+
+func (s *Server) deviceUnregister(c *gin.Context) {
+	var arg struct {
+		Mid   int64  `form:"mid" binding:"required"`
+		Token string `form:"token" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if _, err := s.logic.DelDevice(context.TODO(), arg.Mid, arg.Token); err != nil {
+		errors(c, ServerErr, err.Error())
+		return
+	}
+	result(c, nil, OK)
+}
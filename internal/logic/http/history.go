@@ -0,0 +1,22 @@
+package http
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+func (s *Server) history(c *gin.Context) {
+	var arg model.HistoryQuery
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	res, err := s.logic.History(context.TODO(), &arg)
+	if err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, res, OK)
+}
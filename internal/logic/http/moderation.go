@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) messageRecall(c *gin.Context) {
+	var arg struct {
+		Mid int64  `form:"mid" binding:"required"`
+		ID  string `form:"id" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if err := s.logic.RecallMessage(context.TODO(), arg.Mid, arg.ID); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, nil, OK)
+}
+
+func (s *Server) messageDelete(c *gin.Context) {
+	var arg struct {
+		Operator string `form:"operator" binding:"required"`
+		ID       string `form:"id" binding:"required"`
+		Reason   string `form:"reason"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if err := s.logic.DeleteMessage(context.TODO(), arg.Operator, arg.ID, arg.Reason); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, nil, OK)
+}
+
+func (s *Server) messageViolation(c *gin.Context) {
+	var arg struct {
+		Operator string `form:"operator" binding:"required"`
+		ID       string `form:"id" binding:"required"`
+		Reason   string `form:"reason" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if err := s.logic.FlagViolation(context.TODO(), arg.Operator, arg.ID, arg.Reason); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, nil, OK)
+}
+
+func (s *Server) messageAudits(c *gin.Context) {
+	var arg struct {
+		ID string `form:"id" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	res, err := s.logic.Audits(context.TODO(), arg.ID)
+	if err != nil {
+		errors(c, ServerErr, err.Error())
+		return
+	}
+	result(c, res, OK)
+}
@@ -15,6 +15,10 @@ func (s *Server) onlineTop(c *gin.Context) {
 		errors(c, RequestErr, err.Error())
 		return
 	}
+	if arg.Limit <= 0 {
+		errors(c, RequestErr, "limit must be positive")
+		return
+	}
 	res, err := s.logic.OnlineTop(c, arg.Type, arg.Limit)
 	if err != nil {
 		result(c, nil, RequestErr)
@@ -40,6 +44,29 @@ func (s *Server) onlineRoom(c *gin.Context) {
 	result(c, res, OK)
 }
 
+func (s *Server) onlineMembers(c *gin.Context) {
+	var arg struct {
+		Type   string `form:"type" binding:"required"`
+		Room   string `form:"room" binding:"required"`
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	members, next, failed, err := s.logic.RoomMembers(c, arg.Type, arg.Room, arg.Cursor, arg.Limit)
+	if err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, map[string]interface{}{
+		"members": members,
+		"next":    next,
+		"failed":  failed,
+	}, OK)
+}
+
 func (s *Server) onlineTotal(c *gin.Context) {
 	ipCount, connCount := s.logic.OnlineTotal(context.TODO())
 	res := map[string]interface{}{
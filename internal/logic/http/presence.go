@@ -0,0 +1,23 @@
+package http
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) presence(c *gin.Context) {
+	var arg struct {
+		Mids []int64 `form:"mids" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	res, err := s.logic.Presences(context.TODO(), arg.Mids)
+	if err != nil {
+		errors(c, ServerErr, err.Error())
+		return
+	}
+	result(c, res, OK)
+}
@@ -5,12 +5,34 @@ import (
 	"io/ioutil"
 
 	"github.com/gin-gonic/gin"
+	pb "github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/internal/logic"
+	"github.com/ningchengzeng/goim/internal/logic/model"
 )
 
+// pushErr reply a blocked message as a request error, others as server errors.
+func pushErr(c *gin.Context, err error) {
+	if err == logic.ErrMessageBlocked || err == logic.ErrPriorityArg || err == logic.ErrPushBatchArg {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	errors(c, ServerErr, err.Error())
+}
+
+// msgID get the message id by the msg_id arg or the Idempotency-Key header.
+func msgID(c *gin.Context, id string) string {
+	if id != "" {
+		return id
+	}
+	return c.GetHeader("Idempotency-Key")
+}
+
 func (s *Server) pushKeys(c *gin.Context) {
 	var arg struct {
-		Op   int32    `form:"operation"`
-		Keys []string `form:"keys"`
+		Op       int32    `form:"operation"`
+		Priority int32    `form:"priority"`
+		MsgID    string   `form:"msg_id"`
+		Keys     []string `form:"keys"`
 	}
 	if err := c.BindQuery(&arg); err != nil {
 		errors(c, RequestErr, err.Error())
@@ -22,17 +44,22 @@ func (s *Server) pushKeys(c *gin.Context) {
 		errors(c, RequestErr, err.Error())
 		return
 	}
-	if err = s.logic.PushKeys(context.TODO(), arg.Op, arg.Keys, msg); err != nil {
-		result(c, nil, RequestErr)
+	report, err := s.logic.PushKeys(context.TODO(), arg.Op, pb.PushMsg_Priority(arg.Priority), msgID(c, arg.MsgID), arg.Keys, msg)
+	if err != nil {
+		pushErr(c, err)
 		return
 	}
-	result(c, nil, OK)
+	result(c, report, OK)
 }
 
 func (s *Server) pushMids(c *gin.Context) {
 	var arg struct {
-		Op   int32   `form:"operation"`
-		Mids []int64 `form:"mids"`
+		Op        int32    `form:"operation"`
+		Priority  int32    `form:"priority"`
+		MsgID     string   `form:"msg_id"`
+		Mids      []int64  `form:"mids"`
+		Platforms []string `form:"platforms"`
+		Latest    bool     `form:"latest"`
 	}
 	if err := c.BindQuery(&arg); err != nil {
 		errors(c, RequestErr, err.Error())
@@ -44,18 +71,25 @@ func (s *Server) pushMids(c *gin.Context) {
 		errors(c, RequestErr, err.Error())
 		return
 	}
-	if err = s.logic.PushMids(context.TODO(), arg.Op, arg.Mids, msg); err != nil {
-		errors(c, ServerErr, err.Error())
+	var filter *model.SessionFilter
+	if len(arg.Platforms) > 0 || arg.Latest {
+		filter = &model.SessionFilter{Platforms: arg.Platforms, Latest: arg.Latest}
+	}
+	report, err := s.logic.PushMids(context.TODO(), arg.Op, pb.PushMsg_Priority(arg.Priority), msgID(c, arg.MsgID), arg.Mids, filter, msg)
+	if err != nil {
+		pushErr(c, err)
 		return
 	}
-	result(c, nil, OK)
+	result(c, report, OK)
 }
 
 func (s *Server) pushRoom(c *gin.Context) {
 	var arg struct {
-		Op   int32  `form:"operation" binding:"required"`
-		Type string `form:"type" binding:"required"`
-		Room string `form:"room" binding:"required"`
+		Op       int32  `form:"operation" binding:"required"`
+		Priority int32  `form:"priority"`
+		MsgID    string `form:"msg_id"`
+		Type     string `form:"type" binding:"required"`
+		Room     string `form:"room" binding:"required"`
 	}
 	if err := c.BindQuery(&arg); err != nil {
 		errors(c, RequestErr, err.Error())
@@ -67,8 +101,8 @@ func (s *Server) pushRoom(c *gin.Context) {
 		errors(c, RequestErr, err.Error())
 		return
 	}
-	if err = s.logic.PushRoom(c, arg.Op, arg.Type, arg.Room, msg); err != nil {
-		errors(c, ServerErr, err.Error())
+	if err = s.logic.PushRoom(c, arg.Op, pb.PushMsg_Priority(arg.Priority), msgID(c, arg.MsgID), arg.Type, arg.Room, msg); err != nil {
+		pushErr(c, err)
 		return
 	}
 	result(c, nil, OK)
@@ -76,8 +110,10 @@ func (s *Server) pushRoom(c *gin.Context) {
 
 func (s *Server) pushAll(c *gin.Context) {
 	var arg struct {
-		Op    int32 `form:"operation" binding:"required"`
-		Speed int32 `form:"speed"`
+		Op       int32  `form:"operation" binding:"required"`
+		Speed    int32  `form:"speed"`
+		Priority int32  `form:"priority"`
+		MsgID    string `form:"msg_id"`
 	}
 	if err := c.BindQuery(&arg); err != nil {
 		errors(c, RequestErr, err.Error())
@@ -88,8 +124,40 @@ func (s *Server) pushAll(c *gin.Context) {
 		errors(c, RequestErr, err.Error())
 		return
 	}
-	if err = s.logic.PushAll(c, arg.Op, arg.Speed, msg); err != nil {
-		errors(c, ServerErr, err.Error())
+	if err = s.logic.PushAll(c, arg.Op, arg.Speed, pb.PushMsg_Priority(arg.Priority), msgID(c, arg.MsgID), msg); err != nil {
+		pushErr(c, err)
+		return
+	}
+	result(c, nil, OK)
+}
+
+func (s *Server) pushBatch(c *gin.Context) {
+	var arg struct {
+		Priority int32 `json:"priority"`
+		Items    []struct {
+			Op    int32    `json:"operation" binding:"required"`
+			Keys  []string `json:"keys"`
+			Mids  []int64  `json:"mids"`
+			Msg   string   `json:"msg"`
+			MsgID string   `json:"msg_id"`
+		} `json:"items" binding:"required"`
+	}
+	if err := c.BindJSON(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	items := make([]*pb.PushItem, 0, len(arg.Items))
+	for _, item := range arg.Items {
+		items = append(items, &pb.PushItem{
+			Operation: item.Op,
+			Keys:      item.Keys,
+			Mids:      item.Mids,
+			Msg:       []byte(item.Msg),
+			MsgID:     item.MsgID,
+		})
+	}
+	if err := s.logic.PushBatch(c, pb.PushMsg_Priority(arg.Priority), items); err != nil {
+		pushErr(c, err)
 		return
 	}
 	result(c, nil, OK)
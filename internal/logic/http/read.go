@@ -0,0 +1,43 @@
+package http
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+func (s *Server) read(c *gin.Context) {
+	var arg struct {
+		Mid   int64  `form:"mid" binding:"required"`
+		Peer  int64  `form:"peer"`
+		Room  string `form:"room"`
+		MsgID string `form:"msg_id" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if err := s.logic.MarkRead(context.TODO(), arg.Mid, &model.Read{Peer: arg.Peer, Room: arg.Room, MsgID: arg.MsgID}); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, nil, OK)
+}
+
+func (s *Server) unread(c *gin.Context) {
+	var arg struct {
+		Mid           int64    `form:"mid" binding:"required"`
+		Conversations []string `form:"conversations" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	res, err := s.logic.Unread(context.TODO(), arg.Mid, arg.Conversations)
+	if err != nil {
+		errors(c, ServerErr, err.Error())
+		return
+	}
+	result(c, res, OK)
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+func (s *Server) scheduleAdd(c *gin.Context) {
+	var arg struct {
+		Type     string   `form:"type" binding:"required"`
+		Op       int32    `form:"operation" binding:"required"`
+		Priority int32    `form:"priority"`
+		Keys     []string `form:"keys"`
+		Mids     []int64  `form:"mids"`
+		RoomType string   `form:"room_type"`
+		Room     string   `form:"room"`
+		Speed    int32    `form:"speed"`
+		FireAt   int64    `form:"fire_at"`
+		Delay    int64    `form:"delay"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	if arg.FireAt == 0 {
+		// delay in seconds
+		arg.FireAt = time.Now().Unix() + arg.Delay
+	}
+	// read message
+	msg, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	sc := &model.Schedule{
+		Type:     arg.Type,
+		Op:       arg.Op,
+		Priority: arg.Priority,
+		Keys:     arg.Keys,
+		Mids:     arg.Mids,
+		RoomType: arg.RoomType,
+		Room:     arg.Room,
+		Speed:    arg.Speed,
+		Msg:      msg,
+		FireAt:   arg.FireAt,
+	}
+	if err = s.logic.AddSchedule(context.TODO(), sc); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	result(c, sc, OK)
+}
+
+func (s *Server) scheduleList(c *gin.Context) {
+	res, err := s.logic.Schedules(context.TODO())
+	if err != nil {
+		errors(c, ServerErr, err.Error())
+		return
+	}
+	result(c, res, OK)
+}
+
+func (s *Server) scheduleCancel(c *gin.Context) {
+	var arg struct {
+		ID string `form:"id" binding:"required"`
+	}
+	if err := c.BindQuery(&arg); err != nil {
+		errors(c, RequestErr, err.Error())
+		return
+	}
+	has, err := s.logic.CancelSchedule(context.TODO(), arg.ID)
+	if err != nil {
+		errors(c, ServerErr, err.Error())
+		return
+	}
+	if !has {
+		errors(c, RequestErr, "schedule not found")
+		return
+	}
+	result(c, nil, OK)
+}
@@ -36,9 +36,28 @@ func (s *Server) initRouter() {
 	group.POST("/push/mids", s.pushMids)
 	group.POST("/push/room", s.pushRoom)
 	group.POST("/push/all", s.pushAll)
+	group.POST("/push/batch", s.pushBatch)
+	group.POST("/schedule/add", s.scheduleAdd)
+	group.GET("/schedule/list", s.scheduleList)
+	group.POST("/schedule/cancel", s.scheduleCancel)
+	group.POST("/device/register", s.deviceRegister)
+	group.POST("/device/unregister", s.deviceUnregister)
 	group.GET("/online/top", s.onlineTop)
 	group.GET("/online/room", s.onlineRoom)
+	group.GET("/online/members", s.onlineMembers)
 	group.GET("/online/total", s.onlineTotal)
+	group.GET("/sessions", s.sessions)
+	group.GET("/presence", s.presence)
+	group.POST("/read", s.read)
+	group.GET("/unread", s.unread)
+	group.GET("/history", s.history)
+	group.POST("/message/recall", s.messageRecall)
+	group.POST("/message/delete", s.messageDelete)
+	group.POST("/message/violation", s.messageViolation)
+	group.GET("/message/audits", s.messageAudits)
+	group.POST("/ban", s.ban)
+	group.POST("/unban", s.unban)
+	group.GET("/bans", s.bans)
 	group.GET("/nodes/weighted", s.nodesWeighted)
 	group.GET("/nodes/instances", s.nodesInstances)
 }
@@ -3,33 +3,68 @@ package logic
 import (
 	"context"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/bilibili/discovery/naming"
 	log "github.com/go-kratos/kratos/pkg/log"
 	"github.com/ningchengzeng/goim/internal/logic/conf"
 	"github.com/ningchengzeng/goim/internal/logic/dao"
+	"github.com/ningchengzeng/goim/internal/logic/filter"
 	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/ningchengzeng/goim/internal/logic/notify"
 )
 
 const (
 	_onlineTick     = time.Second * 10
 	_onlineDeadline = time.Minute * 5
+	// _onlineLoad is how often the changed room counts are loaded
+	_onlineLoad = time.Second
+	// _onlineSkew is the clock skew of the room changes loaded again
+	_onlineSkew = time.Second * 2
+	// _onlineChanged is how long the room changes are kept, a logic loads
+	// all the room counts if it's behind
+	_onlineChanged = time.Minute
 )
 
 // Logic struct
 type Logic struct {
-	c   *conf.Config
-	dis *naming.Discovery
-	dao *dao.Dao
+	c      *conf.Config
+	dis    *naming.Discovery
+	dao    *dao.Dao
+	ctx    context.Context
+	cancel context.CancelFunc
 	// online
 	totalIPs   int64
 	totalConns int64
-	roomCount  map[string]int32
+	// the room counts of all the servers, replaced by every load
+	roomCount      map[string]int32
+	roomChanged    int64 // the milliseconds of the last room change loaded
+	roomCountMutex sync.RWMutex
 	// load balancer
 	nodes        []*naming.Instance
 	loadBalancer *LoadBalancer
 	regions      map[string]string // province -> region
+	// comets for the paths bypass the job
+	comets      map[string]*cometClient
+	cometsMutex sync.RWMutex
+	// the servers in discovery, the gone ones are stale until their sessions cleaned
+	servers     map[string]struct{}
+	stales      map[string]int64
+	stalesMutex sync.RWMutex
+	// schedule
+	schedules ScheduleStore
+	// history
+	messages MessageStore
+	// content filter
+	filters      filter.Chain
+	filtersMutex sync.RWMutex
+	// offline notify
+	notifier      notify.Notifier
+	notifierMutex sync.RWMutex
+	notifyChan    chan *notifyArg
+	// presence
+	presenceChan chan *model.PresenceEvent
 }
 
 // New init
@@ -40,11 +75,27 @@ func New(c *conf.Config) (l *Logic) {
 		dis:          naming.New(c.DiscoveryConfig()),
 		loadBalancer: NewLoadBalancer(),
 		regions:      make(map[string]string),
+		stales:       make(map[string]int64),
+		roomCount:    make(map[string]int32),
+		presenceChan: make(chan *model.PresenceEvent, _presenceChanSize),
 	}
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	if c.Schedule.Store == "memory" {
+		l.schedules = NewMemoryScheduleStore()
+	} else {
+		l.schedules = l.dao
+	}
+	l.messages = l.dao
+	l.ReloadFilter()
+	l.initNotifier()
 	l.initRegions()
 	l.initNodes()
 	_ = l.loadOnline()
 	go l.onlineproc()
+	go l.scheduleproc()
+	go l.notifyproc()
+	go l.presenceproc()
+	go l.expireproc()
 	return l
 }
 
@@ -55,6 +106,7 @@ func (l *Logic) Ping(c context.Context) (err error) {
 
 // Close close resources.
 func (l *Logic) Close() {
+	l.cancel()
 	l.dao.Close()
 }
 
@@ -95,9 +147,11 @@ func (l *Logic) newNodes(res naming.Resolver) {
 			totalConns int64
 			totalIPs   int64
 			allIns     []*naming.Instance
+			servers    = make(map[string]struct{})
 		)
 		for _, zins := range zoneIns.Instances {
 			for _, ins := range zins {
+				servers[ins.Hostname] = struct{}{}
 				if ins.Metadata == nil {
 					log.Error("node instance metadata is empty(%+v)", ins)
 					continue
@@ -126,36 +180,106 @@ func (l *Logic) newNodes(res naming.Resolver) {
 		l.totalIPs = totalIPs
 		l.nodes = allIns
 		l.loadBalancer.Update(allIns)
+		l.newComets(allIns)
+		l.watchServers(servers)
 	}
 }
 
+// onlineproc load the changed room counts, and one logic del the room counts
+// of the servers not renewed since the deadline.
 func (l *Logic) onlineproc() {
+	load := time.NewTicker(_onlineLoad)
+	expire := time.NewTicker(_onlineTick)
+	defer load.Stop()
+	defer expire.Stop()
 	for {
-		time.Sleep(_onlineTick)
-		if err := l.loadOnline(); err != nil {
-			log.Error("onlineproc error(%v)", err)
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-load.C:
+			if err := l.loadOnline(); err != nil {
+				log.Error("loadOnline() error(%v)", err)
+			}
+		case <-expire.C:
+			l.expireOnline()
 		}
 	}
 }
 
+// loadOnline load the room counts changed since the last load, or all the
+// room counts if the changes since then are not kept.
 func (l *Logic) loadOnline() (err error) {
 	var (
-		roomCount = make(map[string]int32)
+		c       = context.Background()
+		now     = time.Now().UnixNano() / int64(time.Millisecond)
+		skew    = int64(_onlineSkew / time.Millisecond)
+		changed map[string]int32
+		last    int64
 	)
-	for _, server := range l.nodes {
-		var online *model.Online
-		online, err = l.dao.ServerOnline(context.Background(), server.Hostname)
-		if err != nil {
+	l.roomCountMutex.RLock()
+	since := l.roomChanged
+	l.roomCountMutex.RUnlock()
+	if now-since > int64(_onlineChanged/time.Millisecond)-skew {
+		if changed, err = l.dao.AllRoomsOnline(c); err != nil {
 			return
 		}
-		if time.Since(time.Unix(online.Updated, 0)) > _onlineDeadline {
-			_ = l.dao.DelServerOnline(context.Background(), server.Hostname)
-			continue
+		l.roomCountMutex.Lock()
+		l.roomCount = changed
+		l.roomChanged = now - skew
+		l.roomCountMutex.Unlock()
+		return
+	}
+	// the changes within the skew are loaded again, the counts loaded are the latest
+	if changed, last, err = l.dao.ChangedRoomsOnline(c, since-skew); err != nil {
+		return
+	}
+	if last < now-skew {
+		last = now - skew
+	}
+	l.roomCountMutex.Lock()
+	defer l.roomCountMutex.Unlock()
+	if len(changed) > 0 {
+		roomCount := make(map[string]int32, len(l.roomCount)+len(changed))
+		for room, count := range l.roomCount {
+			roomCount[room] = count
 		}
-		for roomID, count := range online.RoomCount {
-			roomCount[roomID] += count
+		for room, count := range changed {
+			if count > 0 {
+				roomCount[room] = count
+			} else {
+				delete(roomCount, room)
+			}
 		}
+		l.roomCount = roomCount
+	}
+	if last > l.roomChanged {
+		l.roomChanged = last
 	}
-	l.roomCount = roomCount
+	return
+}
+
+// expireOnline del the room counts of the servers not renewed since the
+// deadline and the old room changes, only by the logic locked it.
+func (l *Logic) expireOnline() {
+	c := context.Background()
+	if ok, err := l.dao.LockOnlineExpire(c, int32(_onlineTick/time.Second)); err != nil || !ok {
+		return
+	}
+	servers, err := l.dao.ExpiredServersOnline(c, time.Now().Add(-_onlineDeadline).Unix())
+	if err != nil {
+		return
+	}
+	for _, server := range servers {
+		log.Warn("server:%s online is expired, delete it", server)
+		_ = l.dao.DelServerOnline(c, server)
+	}
+	_ = l.dao.DelRoomsChanged(c, time.Now().Add(-_onlineChanged).UnixNano()/int64(time.Millisecond))
+}
+
+// onlineRooms get the room counts of all the servers last loaded.
+func (l *Logic) onlineRooms() (roomCount map[string]int32) {
+	l.roomCountMutex.RLock()
+	roomCount = l.roomCount
+	l.roomCountMutex.RUnlock()
 	return
 }
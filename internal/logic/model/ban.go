@@ -0,0 +1,9 @@
+package model
+
+// Ban a banned ip or mid, their connections are refused by the comets.
+type Ban struct {
+	IP  string `json:"ip,omitempty"`
+	Mid int64  `json:"mid,omitempty"`
+	// Expire is the unix seconds when the ban ends, 0 is forever.
+	Expire int64 `json:"expire"`
+}
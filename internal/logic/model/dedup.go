@@ -0,0 +1,16 @@
+package model
+
+// Dedup targets of the pushes not routed by server.
+const (
+	TargetAll    = "all"
+	TargetNotify = "notify" // the offline notify of the mids
+	TargetPush   = "push"   // the whole push to the keys or the mids
+)
+
+// MsgTarget is a message pushed to a target by an operation, the unit of the
+// message id deduplication. The target is a server, a room key, TargetAll, TargetNotify or TargetPush.
+type MsgTarget struct {
+	Op     int32
+	Target string
+	MsgID  string
+}
@@ -0,0 +1,16 @@
+package model
+
+const (
+	// PlatformIOS ios device, notified by apns.
+	PlatformIOS = "ios"
+	// PlatformAndroid android device, notified by fcm.
+	PlatformAndroid = "android"
+)
+
+// Device a mobile device token registered for the offline notification.
+type Device struct {
+	Mid      int64  `json:"mid"`
+	Platform string `json:"platform"`
+	Token    string `json:"token"`
+	Updated  int64  `json:"updated"`
+}
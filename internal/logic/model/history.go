@@ -0,0 +1,45 @@
+package model
+
+const (
+	// HistoryUser the 1:1 messages of mid and peer.
+	HistoryUser = "user"
+	// HistoryRoom the messages of a room.
+	HistoryRoom = "room"
+	// HistoryBroadcast the broadcast messages.
+	HistoryBroadcast = "broadcast"
+	// HistoryKeys the messages of client keys.
+	HistoryKeys = "keys"
+)
+
+// HistoryQuery query a page of the history messages, the newest first.
+type HistoryQuery struct {
+	Type string `json:"type" form:"type"`
+	Mid  int64  `json:"mid" form:"mid"`
+	Peer int64  `json:"peer,omitempty" form:"peer"`
+	Room string `json:"room,omitempty" form:"room"`
+	// Cursor is the last message id of the previous page, empty is the first page.
+	Cursor string `json:"cursor,omitempty" form:"cursor"`
+	// Start and End limit the send time in unix seconds, zero is unlimited.
+	Start int64 `json:"start,omitempty" form:"start"`
+	End   int64 `json:"end,omitempty" form:"end"`
+	Limit int   `json:"limit,omitempty" form:"limit"`
+}
+
+// HistoryMessage a history message.
+type HistoryMessage struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	From     string   `json:"from,omitempty"`
+	To       string   `json:"to,omitempty"`
+	Room     string   `json:"room,omitempty"`
+	Keys     []string `json:"keys,omitempty"`
+	Body     string   `json:"body"`
+	SendTime int64    `json:"send_time"`
+}
+
+// History a page of the history messages.
+type History struct {
+	Messages []*HistoryMessage `json:"messages"`
+	// Cursor is the cursor of the next page, empty if no more.
+	Cursor string `json:"cursor,omitempty"`
+}
@@ -0,0 +1,25 @@
+package model
+
+const (
+	// ActionRecall the sender recalled the message.
+	ActionRecall = "recall"
+	// ActionDelete an operator deleted the message.
+	ActionDelete = "delete"
+	// ActionViolation a moderator flagged the message as a violation.
+	ActionViolation = "violation"
+)
+
+// Audit who did what to a message.
+type Audit struct {
+	Operator string `json:"operator"`
+	Action   string `json:"action"`
+	MsgID    string `json:"msg_id"`
+	Reason   string `json:"reason,omitempty"`
+	Created  int64  `json:"created"`
+}
+
+// MessageControl is pushed to the clients to remove a message.
+type MessageControl struct {
+	MsgID  string `json:"msg_id"`
+	Action string `json:"action"`
+}
@@ -0,0 +1,26 @@
+package model
+
+const (
+	// PresenceConnect online by a new connection.
+	PresenceConnect = "connect"
+	// PresenceDisconnect offline by the last connection closed.
+	PresenceDisconnect = "disconnect"
+	// PresenceExpire offline by the heartbeat expired.
+	PresenceExpire = "expire"
+)
+
+// Presence the online state of mid.
+type Presence struct {
+	Mid    int64 `json:"mid"`
+	Online bool  `json:"online"`
+}
+
+// PresenceEvent a presence change of mid.
+type PresenceEvent struct {
+	Mid    int64  `json:"mid"`
+	Online bool   `json:"online"`
+	Reason string `json:"reason"`
+	Key    string `json:"key,omitempty"`
+	Server string `json:"server,omitempty"`
+	Time   int64  `json:"time"`
+}
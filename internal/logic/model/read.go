@@ -0,0 +1,57 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	_convUser = "user:"
+	_convRoom = "room:"
+)
+
+// Read marks the messages of a conversation read up to MsgID,
+// the conversation is a 1:1 with Peer or a Room.
+type Read struct {
+	Peer  int64  `json:"peer,omitempty"`
+	Room  string `json:"room,omitempty"`
+	MsgID string `json:"msg_id"`
+}
+
+// Conversation get the conversation key of the read.
+func (r *Read) Conversation() string {
+	if r.Room != "" {
+		return RoomConversation(r.Room)
+	}
+	return UserConversation(r.Peer)
+}
+
+// ReadReceipt is pushed to the peer when mid read the messages.
+type ReadReceipt struct {
+	Mid   int64  `json:"mid"`
+	MsgID string `json:"msg_id"`
+}
+
+// UserConversation get the 1:1 conversation key with peer.
+func UserConversation(peer int64) string {
+	return _convUser + strconv.FormatInt(peer, 10)
+}
+
+// RoomConversation get the room conversation key.
+func RoomConversation(room string) string {
+	return _convRoom + room
+}
+
+// DecodeConversation decode a conversation key to the peer or room.
+func DecodeConversation(conv string) (peer int64, room string, err error) {
+	switch {
+	case strings.HasPrefix(conv, _convUser):
+		peer, err = strconv.ParseInt(conv[len(_convUser):], 10, 64)
+	case strings.HasPrefix(conv, _convRoom):
+		room = conv[len(_convRoom):]
+	default:
+		err = fmt.Errorf("invalid conversation: %s", conv)
+	}
+	return
+}
@@ -1,20 +1,22 @@
 package model
 
 import (
-	"fmt"
-	"net/url"
+	"github.com/ningchengzeng/goim/pkg/room"
 )
 
 // EncodeRoomKey encode a room key.
-func EncodeRoomKey(typ string, room string) string {
-	return fmt.Sprintf("%s://%s", typ, room)
+func EncodeRoomKey(typ string, id string) string {
+	return room.EncodeKey(typ, id)
 }
 
 // DecodeRoomKey decode room key.
 func DecodeRoomKey(key string) (string, string, error) {
-	u, err := url.Parse(key)
-	if err != nil {
-		return "", "", err
-	}
-	return u.Scheme, u.Host, nil
+	return room.DecodeKey(key)
+}
+
+// RoomMember a connection in a room.
+type RoomMember struct {
+	Mid    int64  `json:"mid"`
+	Key    string `json:"key"`
+	Server string `json:"server"`
 }
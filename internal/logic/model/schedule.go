@@ -0,0 +1,28 @@
+package model
+
+const (
+	// ScheduleKeys schedule push by keys
+	ScheduleKeys = "keys"
+	// ScheduleMids schedule push by mids
+	ScheduleMids = "mids"
+	// ScheduleRoom schedule push to a room
+	ScheduleRoom = "room"
+	// ScheduleAll schedule push to all
+	ScheduleAll = "all"
+)
+
+// Schedule a push fired at the given time.
+type Schedule struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Op       int32    `json:"operation"`
+	Priority int32    `json:"priority"`
+	Keys     []string `json:"keys,omitempty"`
+	Mids     []int64  `json:"mids,omitempty"`
+	RoomType string   `json:"room_type,omitempty"`
+	Room     string   `json:"room,omitempty"`
+	Speed    int32    `json:"speed,omitempty"`
+	Msg      []byte   `json:"msg"`
+	FireAt   int64    `json:"fire_at"`
+	Created  int64    `json:"created"`
+}
@@ -0,0 +1,31 @@
+package model
+
+// Session the metadata of a connection of mid.
+type Session struct {
+	Key       string `json:"key"`
+	Server    string `json:"server"`
+	Platform  string `json:"platform"`
+	Device    string `json:"device,omitempty"`
+	Connected int64  `json:"connected"`
+}
+
+// SessionFilter filters the sessions of a push by mids.
+type SessionFilter struct {
+	// Platforms only push to the sessions of these platforms, empty is all.
+	Platforms []string
+	// Latest only push to the most recent connected session of each mid.
+	Latest bool
+}
+
+// Match check the session matches the platforms.
+func (f *SessionFilter) Match(s *Session) bool {
+	if len(f.Platforms) == 0 {
+		return true
+	}
+	for _, p := range f.Platforms {
+		if p == s.Platform {
+			return true
+		}
+	}
+	return false
+}
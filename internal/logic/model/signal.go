@@ -0,0 +1,18 @@
+package model
+
+import "encoding/json"
+
+// Signal an ephemeral signal sent by client, like typing indicators.
+// It goes to the mids if any, or else the room, default the room of the sender.
+type Signal struct {
+	Mids []int64         `json:"mids,omitempty"`
+	Room string          `json:"room,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// SignalFrame the signal delivered to the targets.
+type SignalFrame struct {
+	From int64           `json:"from"`
+	Key  string          `json:"key"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
@@ -0,0 +1,100 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	pb "github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+var (
+	// ErrMessageNotFound message not found.
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrRecallDenied only the sender can recall a message within the recall window.
+	ErrRecallDenied = errors.New("recall denied")
+)
+
+// RecallMessage the sender mid recall a message within the recall window.
+func (l *Logic) RecallMessage(c context.Context, mid int64, id string) (err error) {
+	msg, err := l.messages.Message(c, id)
+	if err != nil {
+		return
+	}
+	if msg == nil {
+		return ErrMessageNotFound
+	}
+	if msg.From != strconv.FormatInt(mid, 10) || time.Since(time.Unix(msg.SendTime, 0)) > time.Duration(l.c.Moderation.RecallWindow) {
+		return ErrRecallDenied
+	}
+	return l.moderate(c, msg, model.ActionRecall, strconv.FormatInt(mid, 10), "")
+}
+
+// DeleteMessage an operator delete a message.
+func (l *Logic) DeleteMessage(c context.Context, operator, id, reason string) (err error) {
+	return l.moderateByID(c, id, model.ActionDelete, operator, reason)
+}
+
+// FlagViolation a moderator flag a message as a violation.
+func (l *Logic) FlagViolation(c context.Context, operator, id, reason string) (err error) {
+	return l.moderateByID(c, id, model.ActionViolation, operator, reason)
+}
+
+// Audits get the audit records of a message.
+func (l *Logic) Audits(c context.Context, id string) ([]*model.Audit, error) {
+	return l.messages.Audits(c, id)
+}
+
+func (l *Logic) moderateByID(c context.Context, id, action, operator, reason string) (err error) {
+	msg, err := l.messages.Message(c, id)
+	if err != nil {
+		return
+	}
+	if msg == nil {
+		return ErrMessageNotFound
+	}
+	return l.moderate(c, msg, action, operator, reason)
+}
+
+// moderate mark the stored message, record the audit, then push the control op
+// to the room, users or keys of the message, so that clients remove it.
+func (l *Logic) moderate(c context.Context, msg *model.HistoryMessage, action, operator, reason string) (err error) {
+	if action == model.ActionViolation {
+		err = l.messages.SetMessageViolation(c, msg.ID)
+	} else {
+		err = l.messages.DelMessage(c, msg.ID)
+	}
+	if err != nil {
+		return
+	}
+	if err = l.messages.AddAudit(c, &model.Audit{Operator: operator, Action: action, MsgID: msg.ID, Reason: reason}); err != nil {
+		return
+	}
+	body, err := json.Marshal(&model.MessageControl{MsgID: msg.ID, Action: action})
+	if err != nil {
+		return
+	}
+	switch msg.Type {
+	case model.HistoryRoom:
+		err = l.dao.BroadcastRoomMsg(c, protocol.OpMessageControl, pb.PushMsg_HIGH, "", msg.Room, body)
+	case model.HistoryUser:
+		var mids []int64
+		for _, id := range []string{msg.From, msg.To} {
+			if mid, e := strconv.ParseInt(id, 10, 64); e == nil {
+				mids = append(mids, mid)
+			}
+		}
+		err = l.pushToMids(c, protocol.OpMessageControl, mids, body)
+	case model.HistoryKeys:
+		_, err = l.PushKeys(c, protocol.OpMessageControl, pb.PushMsg_HIGH, "", msg.Keys, body)
+	default:
+		err = l.dao.BroadcastMsg(c, protocol.OpMessageControl, 0, pb.PushMsg_HIGH, "", body)
+	}
+	log.Info("message %s id:%s operator:%s reason:%s", action, msg.ID, operator, reason)
+	return
+}
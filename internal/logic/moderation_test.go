@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeration(t *testing.T) {
+	var (
+		c     = context.Background()
+		store = newTestMessageStore()
+	)
+	old := lg.messages
+	lg.messages = store
+	defer func() { lg.messages = old }()
+	// sent long ago
+	err := lg.RecallMessage(c, 1, "9")
+	assert.Equal(t, ErrRecallDenied, err)
+	err = lg.RecallMessage(c, 1, "not_found")
+	assert.Equal(t, ErrMessageNotFound, err)
+	err = lg.DeleteMessage(c, "admin", "8", "spam")
+	assert.Nil(t, err)
+	err = lg.FlagViolation(c, "moderator", "7", "abuse")
+	assert.Nil(t, err)
+	assert.Equal(t, model.ActionDelete, store.dels["8"])
+	assert.Equal(t, model.ActionViolation, store.dels["7"])
+	audits, err := lg.Audits(c, "7")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(audits))
+	assert.Equal(t, "moderator", audits[0].Operator)
+	// no audit if the mark failed
+	store.failID = "6"
+	err = lg.DeleteMessage(c, "admin", "6", "spam")
+	assert.NotNil(t, err)
+	audits, err = lg.Audits(c, "6")
+	assert.Nil(t, err)
+	assert.Empty(t, audits)
+}
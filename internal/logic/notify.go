@@ -0,0 +1,111 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/ningchengzeng/goim/internal/logic/notify"
+)
+
+// ErrDeviceArg device arg error.
+var ErrDeviceArg = errors.New("device arg error")
+
+type notifyArg struct {
+	op   int32
+	mids []int64
+	msg  []byte
+}
+
+// notifyData is the data of the payload template.
+type notifyData struct {
+	Mid      int64
+	Op       int32
+	Platform string
+	Msg      string
+}
+
+func (l *Logic) initNotifier() {
+	switch l.c.Notify.Provider {
+	case "http":
+		l.SetNotifier(notify.NewHTTP(l.c.Notify.URL, time.Duration(l.c.Notify.Timeout)))
+	default:
+		l.SetNotifier(notify.NewMock())
+	}
+	l.notifyChan = make(chan *notifyArg, l.c.Notify.Chan)
+}
+
+// SetNotifier set the notifier of the offline mobile devices.
+func (l *Logic) SetNotifier(n notify.Notifier) {
+	l.notifierMutex.Lock()
+	l.notifier = n
+	l.notifierMutex.Unlock()
+}
+
+func (l *Logic) getNotifier() (n notify.Notifier) {
+	l.notifierMutex.RLock()
+	n = l.notifier
+	l.notifierMutex.RUnlock()
+	return
+}
+
+// AddDevice register a device token for the offline notification.
+func (l *Logic) AddDevice(c context.Context, mid int64, platform, token string) (err error) {
+	if platform != model.PlatformIOS && platform != model.PlatformAndroid {
+		return ErrDeviceArg
+	}
+	return l.dao.AddDevice(c, &model.Device{Mid: mid, Platform: platform, Token: token, Updated: time.Now().Unix()})
+}
+
+// DelDevice unregister a device token.
+func (l *Logic) DelDevice(c context.Context, mid int64, token string) (has bool, err error) {
+	return l.dao.DelDevice(c, mid, token)
+}
+
+// notifyOffline hands the message of the offline mids to the notifier.
+func (l *Logic) notifyOffline(op int32, mids []int64, msg []byte) {
+	if !l.c.Notify.Enable || len(mids) == 0 {
+		return
+	}
+	select {
+	case l.notifyChan <- &notifyArg{op: op, mids: mids, msg: msg}:
+	default:
+		log.Warn("notify chan full, drop op:%d mids:%d", op, len(mids))
+	}
+}
+
+func (l *Logic) notifyproc() {
+	for {
+		var arg *notifyArg
+		select {
+		case <-l.ctx.Done():
+			return
+		case arg = <-l.notifyChan:
+		}
+		if err := l.notify(l.ctx, arg); err != nil {
+			log.Error("l.notify(op:%d mids:%v) error(%v)", arg.op, arg.mids, err)
+		}
+	}
+}
+
+func (l *Logic) notify(c context.Context, arg *notifyArg) (err error) {
+	tpl := l.c.Notify.Template(arg.op)
+	devices, err := l.dao.DevicesByMids(c, arg.mids)
+	if err != nil {
+		return
+	}
+	notifier := l.getNotifier()
+	for _, device := range devices {
+		var buf bytes.Buffer
+		if err = tpl.Execute(&buf, &notifyData{Mid: device.Mid, Op: arg.op, Platform: device.Platform, Msg: string(arg.msg)}); err != nil {
+			return
+		}
+		if e := notifier.Notify(c, device, buf.Bytes()); e != nil {
+			log.Error("l.notifier.Notify(%d,%s) error(%v)", device.Mid, device.Platform, e)
+		}
+	}
+	return
+}
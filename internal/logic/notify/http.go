@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+// HTTP posts the notification to a push gateway, which forwards it to apns or fcm.
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP new a http notifier.
+func NewHTTP(url string, timeout time.Duration) *HTTP {
+	return &HTTP{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Notify post the device and payload as json.
+func (h *HTTP) Notify(c context.Context, device *model.Device, payload []byte) (err error) {
+	body, err := json.Marshal(struct {
+		*model.Device
+		Payload string `json:"payload"`
+	}{device, string(payload)})
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequest("POST", h.url, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req.WithContext(c))
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("notify %s status code: %d", h.url, resp.StatusCode)
+	}
+	return
+}
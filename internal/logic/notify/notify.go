@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+// Notifier sends a notification to an offline mobile device, like apns or fcm.
+type Notifier interface {
+	Notify(c context.Context, device *model.Device, payload []byte) error
+}
+
+// Mock is a local notifier which only records the notifications, used by tests.
+type Mock struct {
+	mutex sync.Mutex
+	Sent  []*Notification
+}
+
+// Notification a sent notification.
+type Notification struct {
+	Device  *model.Device
+	Payload []byte
+}
+
+// NewMock new a mock notifier.
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// Notify record the notification.
+func (m *Mock) Notify(c context.Context, device *model.Device, payload []byte) error {
+	m.mutex.Lock()
+	m.Sent = append(m.Sent, &Notification{Device: device, Payload: payload})
+	m.mutex.Unlock()
+	return nil
+}
+
+// Notifications get the recorded notifications.
+func (m *Mock) Notifications() []*Notification {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]*Notification(nil), m.Sent...)
+}
+
+// Platforms dispatches the notification to the notifier of the device platform.
+type Platforms map[string]Notifier
+
+// Notify notify by the device platform.
+func (p Platforms) Notify(c context.Context, device *model.Device, payload []byte) error {
+	n, ok := p[device.Platform]
+	if !ok {
+		return fmt.Errorf("no notifier for platform: %s", device.Platform)
+	}
+	return n.Notify(c, device, payload)
+}
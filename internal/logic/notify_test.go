@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/ningchengzeng/goim/internal/logic/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotify(t *testing.T) {
+	var (
+		c     = context.TODO()
+		mid   = int64(1000)
+		token = "test_token"
+		mock  = notify.NewMock()
+	)
+	lg.SetNotifier(mock)
+	err := lg.AddDevice(c, mid, model.PlatformIOS, token)
+	assert.Nil(t, err)
+	err = lg.AddDevice(c, mid, "unknown", token)
+	assert.Equal(t, ErrDeviceArg, err)
+	err = lg.notify(c, &notifyArg{op: 1000, mids: []int64{mid}, msg: []byte("hello")})
+	assert.Nil(t, err)
+	sent := mock.Notifications()
+	assert.Equal(t, 1, len(sent))
+	assert.Equal(t, token, sent[0].Device.Token)
+	assert.Contains(t, string(sent[0].Payload), "hello")
+	has, err := lg.DelDevice(c, mid, token)
+	assert.Nil(t, err)
+	assert.True(t, has)
+}
@@ -2,36 +2,27 @@ package logic
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/comet"
 	"github.com/ningchengzeng/goim/internal/logic/model"
 )
 
+const (
+	_maxRoomMembers = 1000
+)
+
 var (
 	_emptyTops = make([]*model.Top, 0)
 )
 
 // OnlineTop get the top online.
 func (l *Logic) OnlineTop(c context.Context, typ string, n int) (tops []*model.Top, err error) {
-	for key, cnt := range l.roomCount {
-		if strings.HasPrefix(key, typ) {
-			_, roomID, err := model.DecodeRoomKey(key)
-			if err != nil {
-				continue
-			}
-			top := &model.Top{
-				RoomID: roomID,
-				Count:  cnt,
-			}
-			tops = append(tops, top)
-		}
-	}
-	sort.Slice(tops, func(i, j int) bool {
-		return tops[i].Count > tops[j].Count
-	})
-	if len(tops) > n {
-		tops = tops[:n]
+	if tops, err = l.dao.TopRooms(c, typ, n); err != nil {
+		return
 	}
 	if len(tops) == 0 {
 		tops = _emptyTops
@@ -41,13 +32,76 @@ func (l *Logic) OnlineTop(c context.Context, typ string, n int) (tops []*model.T
 
 // OnlineRoom get rooms online.
 func (l *Logic) OnlineRoom(c context.Context, typ string, rooms []string) (res map[string]int32, err error) {
+	roomCount := l.onlineRooms()
 	res = make(map[string]int32, len(rooms))
 	for _, room := range rooms {
-		res[room] = l.roomCount[model.EncodeRoomKey(typ, room)]
+		res[room] = roomCount[model.EncodeRoomKey(typ, room)]
 	}
 	return
 }
 
+// RoomMembers page the members of a room in all the comets hosting it, cursor is
+// empty for the first page, next is empty if there is no more.
+// The comets failed are skipped and returned in failed, so a page may miss
+// their members.
+func (l *Logic) RoomMembers(c context.Context, typ, room, cursor string, limit int) (members []*model.RoomMember, next string, failed []string, err error) {
+	from, after, err := decodeMembersCursor(cursor)
+	if err != nil {
+		return
+	}
+	if limit <= 0 || limit > _maxRoomMembers {
+		limit = _maxRoomMembers
+	}
+	comets := l.allComets()
+	servers := make([]string, 0, len(comets))
+	for server := range comets {
+		servers = append(servers, server)
+	}
+	roomKey := model.EncodeRoomKey(typ, room)
+	if servers, err = l.dao.RoomServers(c, roomKey, servers); err != nil {
+		return
+	}
+	sort.Strings(servers)
+	members = make([]*model.RoomMember, 0, limit)
+	for _, server := range servers {
+		if server < from {
+			continue
+		}
+		if server != from {
+			after = ""
+		}
+		reply, e := comets[server].RoomMembers(c, &comet.RoomMembersReq{RoomID: roomKey, Cursor: after, Limit: int32(limit - len(members))})
+		if e != nil {
+			log.Error("RoomMembers(%s) server:%s error(%v)", roomKey, server, e)
+			failed = append(failed, server)
+			continue
+		}
+		for _, m := range reply.Members {
+			members = append(members, &model.RoomMember{Mid: m.Mid, Key: m.Key, Server: server})
+		}
+		if reply.Next != "" {
+			next = encodeMembersCursor(server, reply.Next)
+			return
+		}
+	}
+	return
+}
+
+func encodeMembersCursor(server, key string) string {
+	return server + ":" + key
+}
+
+func decodeMembersCursor(cursor string) (server, key string, err error) {
+	if cursor == "" {
+		return
+	}
+	i := strings.IndexByte(cursor, ':')
+	if i <= 0 || i == len(cursor)-1 {
+		return "", "", fmt.Errorf("invalid cursor:%s", cursor)
+	}
+	return cursor[:i], cursor[i+1:], nil
+}
+
 // OnlineTotal get all online.
 func (l *Logic) OnlineTotal(c context.Context) (int64, int64) {
 	return l.totalIPs, l.totalConns
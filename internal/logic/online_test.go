@@ -11,7 +11,6 @@ func TestOnline(t *testing.T) {
 	var (
 		c     = context.TODO()
 		typ   = "test"
-		n     = 2
 		rooms = []string{"room_01", "room_02", "room_03"}
 	)
 	lg.totalIPs = 100
@@ -21,9 +20,6 @@ func TestOnline(t *testing.T) {
 		"test://room_02": 200,
 		"test://room_03": 300,
 	}
-	tops, err := lg.OnlineTop(c, typ, n)
-	assert.Nil(t, err)
-	assert.Equal(t, len(tops), 2)
 	onlines, err := lg.OnlineRoom(c, typ, rooms)
 	assert.Nil(t, err)
 	assert.Equal(t, onlines["room_01"], int32(100))
@@ -33,3 +29,16 @@ func TestOnline(t *testing.T) {
 	assert.Equal(t, ips, int64(100))
 	assert.Equal(t, conns, int64(200))
 }
+
+func TestMembersCursor(t *testing.T) {
+	server, key, err := decodeMembersCursor("")
+	assert.Nil(t, err)
+	assert.Equal(t, "", server)
+	assert.Equal(t, "", key)
+	server, key, err = decodeMembersCursor(encodeMembersCursor("comet-01", "a:b"))
+	assert.Nil(t, err)
+	assert.Equal(t, "comet-01", server)
+	assert.Equal(t, "a:b", key)
+	_, _, err = decodeMembersCursor("invalid")
+	assert.NotNil(t, err)
+}
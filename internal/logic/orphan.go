@@ -0,0 +1,135 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+)
+
+const (
+	// _orphanLock is how long a logic holds the cleanup of a dead server.
+	_orphanLock = time.Minute
+)
+
+// watchServers mark the servers gone from discovery stale, the pushes skip
+// them, then their sessions are cleaned after the delay if they are still gone.
+func (l *Logic) watchServers(servers map[string]struct{}) {
+	l.stalesMutex.Lock()
+	defer l.stalesMutex.Unlock()
+	for server := range servers {
+		delete(l.stales, server)
+	}
+	if l.c.Orphan.Enable {
+		for server := range l.servers {
+			if _, ok := servers[server]; ok {
+				continue
+			}
+			if _, ok := l.stales[server]; ok {
+				continue
+			}
+			log.Warn("comet server:%s is gone, mark it stale", server)
+			since := time.Now().Unix()
+			l.stales[server] = since
+			server := server
+			time.AfterFunc(time.Duration(l.c.Orphan.Delay), func() { l.cleanServer(server, since) })
+		}
+	}
+	l.servers = servers
+}
+
+// isStale check the server is gone from discovery.
+func (l *Logic) isStale(server string) (ok bool) {
+	_, ok = l.staleSince(server)
+	return
+}
+
+// staleSince get the unix time the server was marked stale.
+func (l *Logic) staleSince(server string) (since int64, ok bool) {
+	l.stalesMutex.RLock()
+	since, ok = l.stales[server]
+	l.stalesMutex.RUnlock()
+	return
+}
+
+// unstale unmark the server if it's still stale since.
+func (l *Logic) unstale(server string, since int64) {
+	l.stalesMutex.Lock()
+	if cur, ok := l.stales[server]; ok && cur == since {
+		delete(l.stales, server)
+	}
+	l.stalesMutex.Unlock()
+}
+
+// liveKeysByMids get the key servers of mids skipping the stale servers,
+// olMids are the mids have a connection on a live server.
+func (l *Logic) liveKeysByMids(c context.Context, mids []int64) (keyServers map[string]string, olMids []int64, err error) {
+	midKeyServers, err := l.dao.KeyServersByMids(c, mids)
+	if err != nil {
+		return
+	}
+	keyServers = make(map[string]string)
+	for _, mid := range mids {
+		online := false
+		for key, server := range midKeyServers[mid] {
+			if l.isStale(server) {
+				continue
+			}
+			keyServers[key] = server
+			online = true
+		}
+		if online {
+			olMids = append(olMids, mid)
+		}
+	}
+	return
+}
+
+// cleanServer delete the sessions left by a dead server since it was marked
+// stale, only one logic cleans a server, the others keep it stale until the
+// cleanup is done. The connections heartbeated after since are kept, and the
+// cleanup stops if the server comes back.
+func (l *Logic) cleanServer(server string, since int64) {
+	if cur, ok := l.staleSince(server); !ok || cur != since {
+		return
+	}
+	c := context.Background()
+	ok, err := l.dao.LockDeadServer(c, server, int32(_orphanLock/time.Second))
+	if err != nil || !ok {
+		time.AfterFunc(_orphanLock, func() { l.unstale(server, since) })
+		return
+	}
+	var (
+		cursor int64
+		total  int
+		done   bool
+	)
+	for {
+		if cur, ok := l.staleSince(server); !ok || cur != since {
+			log.Warn("comet server:%s is back, stop the cleanup", server)
+			break
+		}
+		next, mids, keys, err := l.dao.ServerKeys(c, server, cursor, l.c.Orphan.Batch)
+		if err != nil {
+			break
+		}
+		offMids, err := l.dao.DelServerMappings(c, server, since, mids, keys)
+		if err != nil {
+			break
+		}
+		for _, mid := range offMids {
+			l.presenceOffline(c, mid, "", server)
+		}
+		total += len(keys)
+		if cursor = next; cursor == 0 {
+			done = true
+			break
+		}
+	}
+	if done {
+		_ = l.dao.DelServerKeys(c, server, since)
+		_ = l.dao.DelServerOnline(c, server)
+	}
+	l.unstale(server, since)
+	log.Info("comet server:%s is dead, clean %d sessions", server, total)
+}
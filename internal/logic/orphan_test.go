@@ -0,0 +1,24 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ningchengzeng/goim/internal/logic/conf"
+	xtime "github.com/ningchengzeng/goim/pkg/time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchServers(t *testing.T) {
+	l := &Logic{
+		c:      &conf.Config{Orphan: &conf.Orphan{Enable: true, Delay: xtime.Duration(time.Hour)}},
+		stales: make(map[string]int64),
+	}
+	l.watchServers(map[string]struct{}{"comet_01": {}, "comet_02": {}})
+	assert.False(t, l.isStale("comet_02"))
+	l.watchServers(map[string]struct{}{"comet_01": {}})
+	assert.False(t, l.isStale("comet_01"))
+	assert.True(t, l.isStale("comet_02"))
+	l.watchServers(map[string]struct{}{"comet_01": {}, "comet_02": {}})
+	assert.False(t, l.isStale("comet_02"))
+}
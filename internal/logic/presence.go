@@ -0,0 +1,151 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+const (
+	_presenceChanSize = 1024
+)
+
+// Presences get the online state of mids.
+func (l *Logic) Presences(c context.Context, mids []int64) (res []*model.Presence, err error) {
+	_, olMids, err := l.dao.KeysByMids(c, mids)
+	if err != nil {
+		return
+	}
+	online := make(map[int64]struct{}, len(olMids))
+	for _, mid := range olMids {
+		online[mid] = struct{}{}
+	}
+	for _, mid := range mids {
+		_, ok := online[mid]
+		res = append(res, &model.Presence{Mid: mid, Online: ok})
+	}
+	return
+}
+
+// isOnline check the mid has any connection.
+func (l *Logic) isOnline(c context.Context, mid int64) bool {
+	_, olMids, err := l.dao.KeysByMids(c, []int64{mid})
+	return err == nil && len(olMids) > 0
+}
+
+// presenceDeadline get the deadline of mid to heartbeat, the same as the mapping expire.
+func (l *Logic) presenceDeadline() int64 {
+	return time.Now().Add(time.Duration(l.c.Redis.Expire)).Unix()
+}
+
+// presenceOnline refresh the heartbeat deadline of mid, emits an online event if it was offline.
+func (l *Logic) presenceOnline(c context.Context, mid int64, key, server string, wasOnline bool) {
+	if !l.c.Presence.Enable || mid <= 0 {
+		return
+	}
+	if err := l.dao.TouchPresence(c, mid, l.presenceDeadline()); err != nil {
+		return
+	}
+	if !wasOnline {
+		l.emitPresence(&model.PresenceEvent{Mid: mid, Online: true, Reason: model.PresenceConnect, Key: key, Server: server})
+	}
+}
+
+// presenceOffline emits an offline event if mid has no connection left.
+func (l *Logic) presenceOffline(c context.Context, mid int64, key, server string) {
+	if !l.c.Presence.Enable || mid <= 0 || l.isOnline(c, mid) {
+		return
+	}
+	if err := l.dao.DelPresence(c, mid); err != nil {
+		return
+	}
+	l.emitPresence(&model.PresenceEvent{Mid: mid, Online: false, Reason: model.PresenceDisconnect, Key: key, Server: server})
+}
+
+func (l *Logic) emitPresence(ev *model.PresenceEvent) {
+	ev.Time = time.Now().Unix()
+	select {
+	case l.presenceChan <- ev:
+	default:
+		log.Warn("presence chan full, drop event mid:%d online:%t", ev.Mid, ev.Online)
+	}
+}
+
+// presenceproc publish the presence events in order until the logic closed.
+func (l *Logic) presenceproc() {
+	for {
+		var ev *model.PresenceEvent
+		select {
+		case <-l.ctx.Done():
+			return
+		case ev = <-l.presenceChan:
+		}
+		c := l.ctx
+		if l.c.Presence.Topic != "" {
+			_ = l.dao.PublishPresence(c, ev)
+		}
+		if l.c.Presence.Friends {
+			if err := l.pushFriends(c, ev); err != nil {
+				log.Error("l.pushFriends(%+v) error(%v)", ev, err)
+			}
+		}
+		log.Info("presence mid:%d online:%t reason:%s", ev.Mid, ev.Online, ev.Reason)
+	}
+}
+
+// pushFriends push the presence change to the online friends of mid.
+func (l *Logic) pushFriends(c context.Context, ev *model.PresenceEvent) (err error) {
+	friends, err := l.dao.Friends(c, ev.Mid)
+	if err != nil || len(friends) == 0 {
+		return
+	}
+	body, err := json.Marshal(&model.Presence{Mid: ev.Mid, Online: ev.Online})
+	if err != nil {
+		return
+	}
+	return l.pushToMids(c, protocol.OpPresence, friends, body)
+}
+
+// expireproc emits the offline events of the mids whose heartbeat expired,
+// the connections of them are gone without a disconnect, like a comet crashed.
+func (l *Logic) expireproc() {
+	ticker := time.NewTicker(time.Duration(l.c.Presence.Tick))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !l.c.Presence.Enable {
+			continue
+		}
+		l.expirePresences(l.ctx)
+	}
+}
+
+// expirePresences claim the expired mids by batch until less than a batch is left.
+func (l *Logic) expirePresences(c context.Context) {
+	batch := l.c.Presence.Batch
+	for {
+		mids, err := l.dao.ClaimPresences(c, time.Now().Unix(), batch)
+		if err != nil {
+			return
+		}
+		for _, mid := range mids {
+			if l.isOnline(c, mid) {
+				// the mapping lives a little longer than the deadline, check it next tick.
+				_ = l.dao.TouchPresence(c, mid, time.Now().Add(time.Duration(l.c.Presence.Tick)).Unix())
+				continue
+			}
+			l.emitPresence(&model.PresenceEvent{Mid: mid, Online: false, Reason: model.PresenceExpire})
+		}
+		if len(mids) < batch {
+			return
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresences(t *testing.T) {
+	var (
+		c      = context.Background()
+		server = "test_server"
+		token  = []byte(`{"mid":2000, "key":"test_presence_key", "room_id":"test://test_room", "platform":"web"}`)
+	)
+	mid, key, _, _, _, err := lg.Connect(c, server, "", token)
+	assert.Nil(t, err)
+	res, err := lg.Presences(c, []int64{mid, 2001})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res))
+	assert.True(t, res[0].Online)
+	assert.False(t, res[1].Online)
+	_, err = lg.Disconnect(c, mid, key, server)
+	assert.Nil(t, err)
+	res, err = lg.Presences(c, []int64{mid})
+	assert.Nil(t, err)
+	assert.False(t, res[0].Online)
+}
@@ -2,14 +2,113 @@ package logic
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/google/uuid"
+	pb "github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/internal/logic/filter"
 	"github.com/ningchengzeng/goim/internal/logic/model"
 
 	log "github.com/go-kratos/kratos/pkg/log"
 )
 
-// PushKeys push a message by keys.
-func (l *Logic) PushKeys(c context.Context, op int32, keys []string, msg []byte) (err error) {
+var (
+	// ErrPriorityArg priority arg error.
+	ErrPriorityArg = errors.New("priority arg error")
+	// ErrPushBatchArg push batch arg error, like the items of the same message id and operation.
+	ErrPushBatchArg = errors.New("push batch arg error")
+)
+
+// dedup mark the message targets pushed, fresh are the ones not pushed within
+// the dedup window, a target without message id is always fresh.
+func (l *Logic) dedup(c context.Context, ts []*model.MsgTarget) (fresh []*model.MsgTarget, err error) {
+	var marks []*model.MsgTarget
+	for _, t := range ts {
+		if t.MsgID == "" {
+			fresh = append(fresh, t)
+		} else {
+			marks = append(marks, t)
+		}
+	}
+	oks, err := l.dao.AddMsgIDs(c, marks, int32(time.Duration(l.c.Dedup.Window)/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range marks {
+		if oks[i] {
+			fresh = append(fresh, t)
+		} else {
+			log.Info("push op:%d target:%s msgID:%s is duplicated, drop it", t.Op, t.Target, t.MsgID)
+		}
+	}
+	return
+}
+
+// undedup unmark the message targets failed to push, so that a retry is
+// delivered to them only.
+func (l *Logic) undedup(c context.Context, ts []*model.MsgTarget) {
+	var marks []*model.MsgTarget
+	for _, t := range ts {
+		if t.MsgID != "" {
+			marks = append(marks, t)
+		}
+	}
+	if err := l.dao.DelMsgIDs(c, marks); err != nil {
+		log.Error("l.dao.DelMsgIDs(%d) error(%v)", len(marks), err)
+	}
+}
+
+// dedupOne dedup a message pushed to a single target, dup is true if it was
+// already pushed within the dedup window.
+func (l *Logic) dedupOne(c context.Context, op int32, target, msgID string) (dup bool, err error) {
+	fresh, err := l.dedup(c, []*model.MsgTarget{{Op: op, Target: target, MsgID: msgID}})
+	return len(fresh) == 0, err
+}
+
+// pushServers push a message to the keys of every server, dup is true if the
+// push was done within the dedup window, whatever the servers the keys are on
+// now. A failed push is unmarked with the servers failed, so that a retry is
+// not delivered twice to the servers succeeded.
+func (l *Logic) pushServers(c context.Context, op int32, priority pb.PushMsg_Priority, msgID string, serverKeys map[string][]string, msg []byte, report *pb.PushReport) (err error) {
+	push := &model.MsgTarget{Op: op, Target: model.TargetPush, MsgID: msgID}
+	if report.Duplicated, err = l.dedupOne(c, op, push.Target, msgID); err != nil || report.Duplicated {
+		return
+	}
+	ts := make([]*model.MsgTarget, 0, len(serverKeys))
+	for server := range serverKeys {
+		ts = append(ts, &model.MsgTarget{Op: op, Target: server, MsgID: msgID})
+	}
+	fresh, err := l.dedup(c, ts)
+	if err != nil {
+		l.undedup(c, []*model.MsgTarget{push})
+		return
+	}
+	var failed []*model.MsgTarget
+	for _, t := range fresh {
+		keys := serverKeys[t.Target]
+		if e := l.dao.PushMsg(c, op, priority, report.MsgID, t.Target, keys, msg); e != nil {
+			err = e
+			failed = append(failed, t)
+			continue
+		}
+		report.Servers[t.Target] = int32(len(keys))
+	}
+	if len(failed) > 0 {
+		l.undedup(c, append(failed, push))
+	}
+	return
+}
+
+// PushKeys push a message by keys, report tells which keys are offline and the servers routed.
+func (l *Logic) PushKeys(c context.Context, op int32, priority pb.PushMsg_Priority, msgID string, keys []string, msg []byte) (report *pb.PushReport, err error) {
+	if !priority.Valid() {
+		return nil, ErrPriorityArg
+	}
+	report = &pb.PushReport{MsgID: msgID, Servers: make(map[string]int32)}
+	if report.MsgID == "" {
+		report.MsgID = uuid.New().String()
+	}
 	servers, err := l.dao.ServersByKeys(c, keys)
 	if err != nil {
 		return
@@ -17,46 +116,265 @@ func (l *Logic) PushKeys(c context.Context, op int32, keys []string, msg []byte)
 	pushKeys := make(map[string][]string)
 	for i, key := range keys {
 		server := servers[i]
-		if server != "" && key != "" {
+		if server != "" && key != "" && !l.isStale(server) {
 			pushKeys[server] = append(pushKeys[server], key)
+		} else if key != "" {
+			report.OfflineKeys = append(report.OfflineKeys, key)
 		}
 	}
-	for server := range pushKeys {
-		if err = l.dao.PushMsg(c, op, server, pushKeys[server], msg); err != nil {
-			return
-		}
-	}
+	err = l.pushServers(c, op, priority, msgID, pushKeys, msg, report)
 	return
 }
 
-// PushMids push a message by mid.
-func (l *Logic) PushMids(c context.Context, op int32, mids []int64, msg []byte) (err error) {
-	keyServers, _, err := l.dao.KeysByMids(c, mids)
+// PushMids push a message by mid, report tells which mids are online and the servers routed.
+// A non nil filter limits the sessions of the mids pushed to.
+func (l *Logic) PushMids(c context.Context, op int32, priority pb.PushMsg_Priority, msgID string, mids []int64, filter *model.SessionFilter, msg []byte) (report *pb.PushReport, err error) {
+	if !priority.Valid() {
+		return nil, ErrPriorityArg
+	}
+	report = &pb.PushReport{MsgID: msgID, Servers: make(map[string]int32)}
+	if report.MsgID == "" {
+		report.MsgID = uuid.New().String()
+	}
+	keyServers, olMids, err := l.liveKeysByMids(c, mids)
 	if err != nil {
 		return
 	}
+	report.OnlineMids = olMids
+	report.OfflineMids = offlineMids(mids, olMids)
+	if filter != nil {
+		if keyServers, err = l.filterKeys(c, olMids, keyServers, filter); err != nil {
+			return
+		}
+	}
 	keys := make(map[string][]string)
 	for key, server := range keyServers {
 		if key == "" || server == "" {
 			log.Warn("push key:%s server:%s is empty", key, server)
 			continue
 		}
+		if l.isStale(server) {
+			continue
+		}
 		keys[server] = append(keys[server], key)
 	}
-	for server, keys := range keys {
-		if err = l.dao.PushMsg(c, op, server, keys, msg); err != nil {
+	if err = l.pushServers(c, op, priority, msgID, keys, msg, report); err != nil {
+		return
+	}
+	// notify the offline mids after the pushes published, and once for a message id
+	if len(report.OfflineMids) > 0 && l.c.Notify.Enable {
+		var dup bool
+		if dup, err = l.dedupOne(c, op, model.TargetNotify, msgID); err != nil || dup {
 			return
 		}
+		l.notifyOffline(op, report.OfflineMids, msg)
+	}
+	return
+}
+
+// offlineMids get the mids not in olMids.
+func offlineMids(mids, olMids []int64) (res []int64) {
+	online := make(map[int64]struct{}, len(olMids))
+	for _, mid := range olMids {
+		online[mid] = struct{}{}
+	}
+	for _, mid := range mids {
+		if _, ok := online[mid]; !ok {
+			res = append(res, mid)
+		}
 	}
 	return
 }
 
 // PushRoom push a message by room.
-func (l *Logic) PushRoom(c context.Context, op int32, typ, room string, msg []byte) (err error) {
-	return l.dao.BroadcastRoomMsg(c, op, model.EncodeRoomKey(typ, room), msg)
+func (l *Logic) PushRoom(c context.Context, op int32, priority pb.PushMsg_Priority, msgID, typ, room string, msg []byte) (err error) {
+	if !priority.Valid() {
+		return ErrPriorityArg
+	}
+	roomKey := model.EncodeRoomKey(typ, room)
+	dup, err := l.dedupOne(c, op, roomKey, msgID)
+	if err != nil || dup {
+		return
+	}
+	defer func() {
+		if err != nil {
+			l.undedup(c, []*model.MsgTarget{{Op: op, Target: roomKey, MsgID: msgID}})
+		}
+	}()
+	if msg, err = l.filterMessage(c, model.HistoryRoom, &filter.Message{Room: roomKey, Op: op, Body: msg}); err != nil {
+		return
+	}
+	return l.dao.BroadcastRoomMsg(c, op, priority, msgID, roomKey, msg)
 }
 
 // PushAll push a message to all.
-func (l *Logic) PushAll(c context.Context, op, speed int32, msg []byte) (err error) {
-	return l.dao.BroadcastMsg(c, op, speed, msg)
+func (l *Logic) PushAll(c context.Context, op, speed int32, priority pb.PushMsg_Priority, msgID string, msg []byte) (err error) {
+	if !priority.Valid() {
+		return ErrPriorityArg
+	}
+	dup, err := l.dedupOne(c, op, model.TargetAll, msgID)
+	if err != nil || dup {
+		return
+	}
+	defer func() {
+		if err != nil {
+			l.undedup(c, []*model.MsgTarget{{Op: op, Target: model.TargetAll, MsgID: msgID}})
+		}
+	}()
+	if msg, err = l.filterMessage(c, model.HistoryBroadcast, &filter.Message{Op: op, Body: msg}); err != nil {
+		return
+	}
+	return l.dao.BroadcastMsg(c, op, speed, priority, msgID, msg)
+}
+
+// PushBatch push many messages by keys or mids, the targets are resolved and
+// deduplicated at once, every server gets the batch in as few messages as the
+// nsq message size allows. The offline mids are notified after the pushes.
+// The items of the same message id and operation are rejected, as they dedup each other.
+func (l *Logic) PushBatch(c context.Context, priority pb.PushMsg_Priority, items []*pb.PushItem) (err error) {
+	if !priority.Valid() {
+		return ErrPriorityArg
+	}
+	itemsSeen := make(map[model.MsgTarget]struct{}, len(items))
+	for _, item := range items {
+		if item.MsgID == "" {
+			continue
+		}
+		t := model.MsgTarget{Op: item.Operation, MsgID: item.MsgID}
+		if _, ok := itemsSeen[t]; ok {
+			return ErrPushBatchArg
+		}
+		itemsSeen[t] = struct{}{}
+	}
+	var (
+		keys     []string
+		mids     []int64
+		midsSeen = make(map[int64]struct{})
+	)
+	for _, item := range items {
+		keys = append(keys, item.Keys...)
+		for _, mid := range item.Mids {
+			if _, ok := midsSeen[mid]; !ok {
+				midsSeen[mid] = struct{}{}
+				mids = append(mids, mid)
+			}
+		}
+	}
+	keyServers := make(map[string]string, len(keys))
+	if len(keys) > 0 {
+		var servers []string
+		if servers, err = l.dao.ServersByKeys(c, keys); err != nil {
+			return
+		}
+		for i, key := range keys {
+			keyServers[key] = servers[i]
+		}
+	}
+	var midKeyServers map[int64]map[string]string
+	if len(mids) > 0 {
+		if midKeyServers, err = l.dao.KeyServersByMids(c, mids); err != nil {
+			return
+		}
+	}
+	var (
+		ts       []*model.MsgTarget
+		pushes   = make(map[*model.MsgTarget]*pb.PushItem)
+		notifies = make(map[*model.MsgTarget]*notifyArg)
+	)
+	for _, item := range items {
+		var offMids []int64
+		serverKeys := make(map[string][]string)
+		for _, key := range item.Keys {
+			if server := keyServers[key]; server != "" && key != "" && !l.isStale(server) {
+				serverKeys[server] = append(serverKeys[server], key)
+			}
+		}
+		for _, mid := range item.Mids {
+			if len(midKeyServers[mid]) == 0 {
+				offMids = append(offMids, mid)
+				continue
+			}
+			for key, server := range midKeyServers[mid] {
+				if key == "" || server == "" {
+					log.Warn("push key:%s server:%s is empty", key, server)
+					continue
+				}
+				if l.isStale(server) {
+					continue
+				}
+				serverKeys[server] = append(serverKeys[server], key)
+			}
+		}
+		for server, keys := range serverKeys {
+			t := &model.MsgTarget{Op: item.Operation, Target: server, MsgID: item.MsgID}
+			ts = append(ts, t)
+			pushes[t] = &pb.PushItem{
+				Operation: item.Operation,
+				Keys:      keys,
+				Msg:       item.Msg,
+				MsgID:     item.MsgID,
+			}
+		}
+		if len(offMids) > 0 {
+			t := &model.MsgTarget{Op: item.Operation, Target: model.TargetNotify, MsgID: item.MsgID}
+			ts = append(ts, t)
+			notifies[t] = &notifyArg{op: item.Operation, mids: offMids, msg: item.Msg}
+		}
+	}
+	fresh, err := l.dedup(c, ts)
+	if err != nil {
+		return
+	}
+	var (
+		batches = make(map[string][]*pb.PushItem)
+		targets = make(map[*pb.PushItem]*model.MsgTarget)
+		failed  []*model.MsgTarget
+	)
+	for _, t := range fresh {
+		if item, ok := pushes[t]; ok {
+			batches[t.Target] = append(batches[t.Target], item)
+			targets[item] = t
+		}
+	}
+	for server, items := range batches {
+		fails, e := l.dao.PushBatchMsg(c, priority, server, items)
+		if e != nil {
+			err = e
+		}
+		for _, item := range fails {
+			failed = append(failed, targets[item])
+		}
+	}
+	for _, t := range fresh {
+		if arg, ok := notifies[t]; ok {
+			if err != nil {
+				failed = append(failed, t)
+				continue
+			}
+			l.notifyOffline(arg.op, arg.mids, arg.msg)
+		}
+	}
+	l.undedup(c, failed)
+	return
+}
+
+// pushToMids push a server event to the online sessions of mids,
+// without the dedup, report and offline notify of PushMids.
+func (l *Logic) pushToMids(c context.Context, op int32, mids []int64, msg []byte) (err error) {
+	keyServers, _, err := l.dao.KeysByMids(c, mids)
+	if err != nil {
+		return
+	}
+	keys := make(map[string][]string)
+	for key, server := range keyServers {
+		if key != "" && server != "" && !l.isStale(server) {
+			keys[server] = append(keys[server], key)
+		}
+	}
+	for server, keys := range keys {
+		if err = l.dao.PushMsg(c, op, pb.PushMsg_NORMAL, "", server, keys, msg); err != nil {
+			return
+		}
+	}
+	return
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/google/uuid"
+	pb "github.com/ningchengzeng/goim/api/logic"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,8 +16,26 @@ func TestPushKeys(t *testing.T) {
 		keys = []string{"test_key"}
 		msg  = []byte("hello")
 	)
-	err := lg.PushKeys(c, op, keys, msg)
+	report, err := lg.PushKeys(c, op, pb.PushMsg_NORMAL, "", keys, msg)
 	assert.Nil(t, err)
+	assert.NotEmpty(t, report.MsgID)
+}
+
+func TestPushKeysDedup(t *testing.T) {
+	var (
+		c     = context.TODO()
+		op    = int32(100)
+		keys  = []string{"test_key"}
+		msg   = []byte("hello")
+		msgID = uuid.New().String()
+	)
+	report, err := lg.PushKeys(c, op, pb.PushMsg_NORMAL, msgID, keys, msg)
+	assert.Nil(t, err)
+	assert.False(t, report.Duplicated)
+	// a retry is dropped, even if the keys moved to other servers
+	report, err = lg.PushKeys(c, op, pb.PushMsg_NORMAL, msgID, keys, msg)
+	assert.Nil(t, err)
+	assert.True(t, report.Duplicated)
 }
 
 func TestPushMids(t *testing.T) {
@@ -25,8 +45,9 @@ func TestPushMids(t *testing.T) {
 		mids = []int64{1, 2, 3}
 		msg  = []byte("hello")
 	)
-	err := lg.PushMids(c, op, mids, msg)
+	report, err := lg.PushMids(c, op, pb.PushMsg_HIGH, "", mids, nil, msg)
 	assert.Nil(t, err)
+	assert.Equal(t, len(mids), len(report.OnlineMids)+len(report.OfflineMids))
 }
 
 func TestPushRoom(t *testing.T) {
@@ -37,7 +58,7 @@ func TestPushRoom(t *testing.T) {
 		room = "test_room"
 		msg  = []byte("hello")
 	)
-	err := lg.PushRoom(c, op, typ, room, msg)
+	err := lg.PushRoom(c, op, pb.PushMsg_NORMAL, "", typ, room, msg)
 	assert.Nil(t, err)
 }
 
@@ -48,6 +69,29 @@ func TestPushAll(t *testing.T) {
 		speed = int32(100)
 		msg   = []byte("hello")
 	)
-	err := lg.PushAll(c, op, speed, msg)
+	err := lg.PushAll(c, op, speed, pb.PushMsg_NORMAL, "", msg)
 	assert.Nil(t, err)
 }
+
+func TestPushBatch(t *testing.T) {
+	var (
+		c     = context.TODO()
+		items = []*pb.PushItem{
+			{Operation: 100, Keys: []string{"test_key"}, Msg: []byte("hello")},
+			{Operation: 100, Mids: []int64{1, 2, 3}, Msg: []byte("hello")},
+		}
+	)
+	err := lg.PushBatch(c, pb.PushMsg_NORMAL, items)
+	assert.Nil(t, err)
+	// the items of the same message id and operation
+	err = lg.PushBatch(c, pb.PushMsg_NORMAL, []*pb.PushItem{
+		{Operation: 100, MsgID: "test_msg", Keys: []string{"test_key"}, Msg: []byte("hello")},
+		{Operation: 100, MsgID: "test_msg", Keys: []string{"test_key"}, Msg: []byte("world")},
+	})
+	assert.Equal(t, ErrPushBatchArg, err)
+}
+
+func TestOfflineMids(t *testing.T) {
+	res := offlineMids([]int64{1, 2, 3}, []int64{2})
+	assert.Equal(t, []int64{1, 3}, res)
+}
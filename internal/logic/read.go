@@ -0,0 +1,86 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrReadArg read arg error.
+	ErrReadArg = errors.New("read arg error")
+	// ErrReadDenied read of a room not joined or by an anonymous user.
+	ErrReadDenied = errors.New("read denied")
+)
+
+// MarkRead mark the messages of a conversation read up to the message id,
+// the peer of a 1:1 conversation receives a read receipt, a room only keeps
+// the cursor for the unread count.
+func (l *Logic) MarkRead(c context.Context, mid int64, r *model.Read) (err error) {
+	if mid <= 0 {
+		return ErrReadDenied
+	}
+	if (r.Peer == 0) == (r.Room == "") || r.Peer < 0 || r.Peer == mid {
+		return ErrReadArg
+	}
+	oid, err := primitive.ObjectIDFromHex(r.MsgID)
+	if err != nil {
+		return ErrReadArg
+	}
+	// the cursors are compared as the fixed width lower hex
+	r.MsgID = oid.Hex()
+	ok, err := l.dao.SetReadCursor(c, mid, r.Conversation(), r.MsgID)
+	if err != nil || !ok {
+		return
+	}
+	if r.Room != "" {
+		return
+	}
+	if err = l.dao.SetMessagesRead(c, mid, r.Peer, r.MsgID); err != nil {
+		log.Error("l.dao.SetMessagesRead(%d,%d,%s) error(%v)", mid, r.Peer, r.MsgID, err)
+		return
+	}
+	body, err := json.Marshal(&model.ReadReceipt{Mid: mid, MsgID: r.MsgID})
+	if err != nil {
+		return
+	}
+	return l.pushToMids(c, protocol.OpReadReceipt, []int64{r.Peer}, body)
+}
+
+// Unread get the unread count of the conversations of mid.
+func (l *Logic) Unread(c context.Context, mid int64, convs []string) (res map[string]int64, err error) {
+	cursors, err := l.dao.ReadCursors(c, mid, convs)
+	if err != nil {
+		return
+	}
+	res = make(map[string]int64, len(convs))
+	for _, conv := range convs {
+		peer, room, e := model.DecodeConversation(conv)
+		if e != nil {
+			return nil, ErrReadArg
+		}
+		if res[conv], err = l.dao.UnreadCount(c, mid, peer, room, cursors[conv]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// receiveRead handle the read op from the client, the client could only mark
+// the room it joined.
+func (l *Logic) receiveRead(c context.Context, mid int64, roomID string, p *protocol.Proto) (err error) {
+	var r model.Read
+	if err = json.Unmarshal(p.Body, &r); err != nil {
+		log.Error("json.Unmarshal(%s) error(%v)", p.Body, err)
+		return ErrReadArg
+	}
+	if r.Room != "" && r.Room != roomID {
+		return ErrReadDenied
+	}
+	return l.MarkRead(c, mid, &r)
+}
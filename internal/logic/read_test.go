@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMarkRead(t *testing.T) {
+	var (
+		c     = context.Background()
+		mid   = int64(3000)
+		peer  = int64(3001)
+		msgID = primitive.NewObjectID().Hex()
+	)
+	err := lg.MarkRead(c, mid, &model.Read{Peer: peer, MsgID: msgID})
+	assert.Nil(t, err)
+	err = lg.MarkRead(c, mid, &model.Read{Peer: peer, MsgID: "bad"})
+	assert.Equal(t, ErrReadArg, err)
+	err = lg.MarkRead(c, mid, &model.Read{MsgID: msgID})
+	assert.Equal(t, ErrReadArg, err)
+	err = lg.MarkRead(c, mid, &model.Read{Peer: mid, MsgID: msgID})
+	assert.Equal(t, ErrReadArg, err)
+	err = lg.MarkRead(c, 0, &model.Read{Peer: peer, MsgID: msgID})
+	assert.Equal(t, ErrReadDenied, err)
+	err = lg.MarkRead(c, mid, &model.Read{Room: "test://test_room", MsgID: msgID})
+	assert.Nil(t, err)
+	// the client could only mark the room it joined
+	body := []byte(`{"room":"test://test_room","msg_id":"` + msgID + `"}`)
+	err = lg.Receive(c, mid, "test://test_room", &protocol.Proto{Op: protocol.OpRead, Body: body})
+	assert.Nil(t, err)
+	err = lg.Receive(c, mid, "test://other_room", &protocol.Proto{Op: protocol.OpRead, Body: body})
+	assert.Equal(t, ErrReadDenied, err)
+	res, err := lg.Unread(c, mid, []string{model.UserConversation(peer), model.RoomConversation("test://test_room")})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res))
+	_, err = lg.Unread(c, mid, []string{"bad"})
+	assert.Equal(t, ErrReadArg, err)
+}
@@ -0,0 +1,197 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/google/uuid"
+	pb "github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+// ErrScheduleArg schedule arg error.
+var ErrScheduleArg = errors.New("schedule arg error")
+
+// ScheduleStore stores the pending schedules durably.
+type ScheduleStore interface {
+	AddSchedule(c context.Context, s *model.Schedule) error
+	Schedules(c context.Context) ([]*model.Schedule, error)
+	CancelSchedule(c context.Context, id string) (bool, error)
+	// ClaimSchedules leases the schedules due before now until now+lease,
+	// a schedule must be claimed only once across all the logic instances
+	// within the lease, it's claimed again after the lease unless it's done.
+	ClaimSchedules(c context.Context, now, lease int64, limit int) ([]*model.Schedule, error)
+	// DoneSchedule deletes a schedule after it's fired.
+	DoneSchedule(c context.Context, id string) error
+}
+
+// MemoryScheduleStore is a schedule store in memory, only for single instance.
+type MemoryScheduleStore struct {
+	mutex     sync.Mutex
+	schedules map[string]*model.Schedule
+	leases    map[string]int64 // id -> lease deadline
+}
+
+// NewMemoryScheduleStore new a memory schedule store.
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{
+		schedules: make(map[string]*model.Schedule),
+		leases:    make(map[string]int64),
+	}
+}
+
+// AddSchedule add a schedule.
+func (m *MemoryScheduleStore) AddSchedule(c context.Context, s *model.Schedule) error {
+	m.mutex.Lock()
+	m.schedules[s.ID] = s
+	m.mutex.Unlock()
+	return nil
+}
+
+// Schedules get all the pending schedules.
+func (m *MemoryScheduleStore) Schedules(c context.Context) (res []*model.Schedule, err error) {
+	m.mutex.Lock()
+	for _, s := range m.schedules {
+		res = append(res, s)
+	}
+	m.mutex.Unlock()
+	return
+}
+
+// CancelSchedule delete a pending schedule.
+func (m *MemoryScheduleStore) CancelSchedule(c context.Context, id string) (has bool, err error) {
+	m.mutex.Lock()
+	if _, has = m.schedules[id]; has {
+		delete(m.schedules, id)
+		delete(m.leases, id)
+	}
+	m.mutex.Unlock()
+	return
+}
+
+// ClaimSchedules claim the schedules due before now by fire time until now+lease.
+func (m *MemoryScheduleStore) ClaimSchedules(c context.Context, now, lease int64, limit int) (res []*model.Schedule, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for id, s := range m.schedules {
+		if s.FireAt <= now && m.leases[id] <= now {
+			res = append(res, s)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].FireAt < res[j].FireAt
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	for _, s := range res {
+		m.leases[s.ID] = now + lease
+	}
+	return
+}
+
+// DoneSchedule delete a fired schedule.
+func (m *MemoryScheduleStore) DoneSchedule(c context.Context, id string) (err error) {
+	_, err = m.CancelSchedule(c, id)
+	return
+}
+
+// AddSchedule add a push fired at s.FireAt.
+func (l *Logic) AddSchedule(c context.Context, s *model.Schedule) (err error) {
+	switch s.Type {
+	case model.ScheduleKeys:
+		if len(s.Keys) == 0 {
+			return ErrScheduleArg
+		}
+	case model.ScheduleMids:
+		if len(s.Mids) == 0 {
+			return ErrScheduleArg
+		}
+	case model.ScheduleRoom:
+		if s.RoomType == "" || s.Room == "" {
+			return ErrScheduleArg
+		}
+	case model.ScheduleAll:
+	default:
+		return ErrScheduleArg
+	}
+	if !pb.PushMsg_Priority(s.Priority).Valid() {
+		return ErrPriorityArg
+	}
+	s.ID = uuid.New().String()
+	s.Created = time.Now().Unix()
+	if err = l.schedules.AddSchedule(c, s); err != nil {
+		log.Error("l.schedules.AddSchedule(%+v) error(%v)", s, err)
+	}
+	return
+}
+
+// Schedules get the pending schedules ordered by fire time.
+func (l *Logic) Schedules(c context.Context) (res []*model.Schedule, err error) {
+	if res, err = l.schedules.Schedules(c); err != nil {
+		return
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].FireAt < res[j].FireAt
+	})
+	return
+}
+
+// CancelSchedule cancel a pending schedule.
+func (l *Logic) CancelSchedule(c context.Context, id string) (has bool, err error) {
+	return l.schedules.CancelSchedule(c, id)
+}
+
+// scheduleproc fire the due schedules, a schedule failed to fire is left to
+// be claimed again after the lease.
+func (l *Logic) scheduleproc() {
+	ticker := time.NewTicker(time.Duration(l.c.Schedule.Tick))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		lease := int64(time.Duration(l.c.Schedule.Lease) / time.Second)
+		schedules, err := l.schedules.ClaimSchedules(l.ctx, time.Now().Unix(), lease, l.c.Schedule.Batch)
+		if err != nil {
+			log.Error("scheduleproc error(%v)", err)
+			continue
+		}
+		for _, s := range schedules {
+			if err = l.fireSchedule(l.ctx, s); err != nil && err != ErrScheduleArg && err != ErrPriorityArg {
+				log.Error("fireSchedule(%+v) error(%v)", s, err)
+				continue
+			}
+			if err = l.schedules.DoneSchedule(l.ctx, s.ID); err != nil {
+				log.Error("l.schedules.DoneSchedule(%s) error(%v)", s.ID, err)
+				continue
+			}
+			log.Info("schedule fired id:%s type:%s fire_at:%d", s.ID, s.Type, s.FireAt)
+		}
+	}
+}
+
+// fireSchedule push the schedule through the normal push paths.
+func (l *Logic) fireSchedule(c context.Context, s *model.Schedule) (err error) {
+	// the schedule id is the message id, a schedule is never pushed twice.
+	priority := pb.PushMsg_Priority(s.Priority)
+	switch s.Type {
+	case model.ScheduleKeys:
+		_, err = l.PushKeys(c, s.Op, priority, s.ID, s.Keys, s.Msg)
+	case model.ScheduleMids:
+		_, err = l.PushMids(c, s.Op, priority, s.ID, s.Mids, nil, s.Msg)
+	case model.ScheduleRoom:
+		err = l.PushRoom(c, s.Op, priority, s.ID, s.RoomType, s.Room, s.Msg)
+	case model.ScheduleAll:
+		err = l.PushAll(c, s.Op, s.Speed, priority, s.ID, s.Msg)
+	default:
+		err = ErrScheduleArg
+	}
+	return
+}
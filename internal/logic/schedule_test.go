@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryScheduleStore(t *testing.T) {
+	var (
+		c   = context.TODO()
+		now = time.Now().Unix()
+		st  = NewMemoryScheduleStore()
+	)
+	assert.Nil(t, st.AddSchedule(c, &model.Schedule{ID: "1", Type: model.ScheduleAll, FireAt: now - 1}))
+	assert.Nil(t, st.AddSchedule(c, &model.Schedule{ID: "2", Type: model.ScheduleAll, FireAt: now + 60}))
+	assert.Nil(t, st.AddSchedule(c, &model.Schedule{ID: "3", Type: model.ScheduleAll, FireAt: now - 2}))
+	res, err := st.ClaimSchedules(c, now, 60, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, "3", res[0].ID)
+	res, err = st.ClaimSchedules(c, now, 60, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, "1", res[0].ID)
+	res, err = st.ClaimSchedules(c, now, 60, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(res))
+	assert.Nil(t, st.DoneSchedule(c, "1"))
+	res, err = st.ClaimSchedules(c, now+61, 60, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, "3", res[0].ID)
+	has, err := st.CancelSchedule(c, "2")
+	assert.Nil(t, err)
+	assert.True(t, has)
+	has, err = st.CancelSchedule(c, "2")
+	assert.Nil(t, err)
+	assert.False(t, has)
+}
+
+func TestAddSchedule(t *testing.T) {
+	var (
+		c = context.TODO()
+		s = &model.Schedule{
+			Type:   model.ScheduleKeys,
+			Op:     100,
+			Keys:   []string{"test_key"},
+			Msg:    []byte("hello"),
+			FireAt: time.Now().Unix() + 3600,
+		}
+	)
+	err := lg.AddSchedule(c, s)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, s.ID)
+	res, err := lg.Schedules(c)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, res)
+	has, err := lg.CancelSchedule(c, s.ID)
+	assert.Nil(t, err)
+	assert.True(t, has)
+	err = lg.AddSchedule(c, &model.Schedule{Type: model.ScheduleRoom})
+	assert.Equal(t, ErrScheduleArg, err)
+}
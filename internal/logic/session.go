@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+// Sessions get the online sessions of mid, the most recent connected first.
+func (l *Logic) Sessions(c context.Context, mid int64) (res []*model.Session, err error) {
+	keyServers, _, err := l.dao.KeysByMids(c, []int64{mid})
+	if err != nil {
+		return
+	}
+	sessions, err := l.liveSessions(c, []int64{mid}, keyServers)
+	if err != nil {
+		return
+	}
+	return sessions[mid], nil
+}
+
+// liveSessions get the sessions of mids which still have a key server,
+// the expired sessions left by the timeout connections are skipped.
+func (l *Logic) liveSessions(c context.Context, mids []int64, keyServers map[string]string) (res map[int64][]*model.Session, err error) {
+	if res, err = l.dao.SessionsByMids(c, mids); err != nil {
+		return
+	}
+	for mid, sessions := range res {
+		lives := sessions[:0]
+		for _, s := range sessions {
+			if server, ok := keyServers[s.Key]; ok {
+				s.Server = server
+				lives = append(lives, s)
+			}
+		}
+		sort.Slice(lives, func(i, j int) bool {
+			return lives[i].Connected > lives[j].Connected
+		})
+		res[mid] = lives
+	}
+	return
+}
+
+// filterKeys get the key servers of mids matched the session filter.
+func (l *Logic) filterKeys(c context.Context, mids []int64, keyServers map[string]string, filter *model.SessionFilter) (res map[string]string, err error) {
+	sessions, err := l.liveSessions(c, mids, keyServers)
+	if err != nil {
+		return
+	}
+	res = make(map[string]string)
+	for _, ss := range sessions {
+		for _, s := range ss {
+			if !filter.Match(s) {
+				continue
+			}
+			res[s.Key] = s.Server
+			if filter.Latest {
+				break
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"github.com/ningchengzeng/goim/api/comet"
+	pb "github.com/ningchengzeng/goim/api/logic"
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/ningchengzeng/goim/internal/logic/model"
+)
+
+// ErrSignalDenied signal to a room not joined or to the mids not friends.
+var ErrSignalDenied = errors.New("signal denied")
+
+// Signal route an ephemeral signal straight to the target comets,
+// it bypasses the nsq and job, and is never persisted or retried.
+// The sender could only signal its own room or its friends.
+func (l *Logic) Signal(c context.Context, mid int64, key, roomID string, p *protocol.Proto) (err error) {
+	var sig model.Signal
+	if err = json.Unmarshal(p.Body, &sig); err != nil {
+		log.Error("json.Unmarshal(%s) error(%v)", p.Body, err)
+		return
+	}
+	if sig.Room != "" && sig.Room != roomID {
+		log.Warn("signal mid:%d key:%s to room:%s not joined", mid, key, sig.Room)
+		return ErrSignalDenied
+	}
+	body, err := json.Marshal(&model.SignalFrame{From: mid, Key: key, Data: sig.Data})
+	if err != nil {
+		return
+	}
+	frame := &protocol.Proto{Ver: p.Ver, Op: p.Op, Body: body}
+	if len(sig.Mids) > 0 {
+		return l.signalMids(c, mid, sig.Mids, frame)
+	}
+	if roomID == "" {
+		return
+	}
+	// only the comets hosting the room
+	comets := l.allComets()
+	servers := make([]string, 0, len(comets))
+	for server := range comets {
+		servers = append(servers, server)
+	}
+	if servers, err = l.dao.RoomServers(c, roomID, servers); err != nil || len(servers) == 0 {
+		return
+	}
+	req := &comet.BroadcastRoomReq{RoomID: roomID, Proto: frame}
+	l.eachComet(c, servers, func(ctx context.Context, server string, cc *cometClient) (err error) {
+		if _, err = cc.client.BroadcastRoom(ctx, req); err != nil {
+			log.Error("signal BroadcastRoom(%s) server:%s error(%v)", roomID, server, err)
+		}
+		return
+	})
+	return
+}
+
+// signalMids signal the friends of mid in mids.
+func (l *Logic) signalMids(c context.Context, mid int64, mids []int64, frame *protocol.Proto) (err error) {
+	if mid <= 0 {
+		return ErrSignalDenied
+	}
+	friends, err := l.dao.Friends(c, mid)
+	if err != nil {
+		return
+	}
+	isFriend := make(map[int64]struct{}, len(friends))
+	for _, friend := range friends {
+		isFriend[friend] = struct{}{}
+	}
+	var targets []int64
+	for _, target := range mids {
+		if _, ok := isFriend[target]; ok {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		log.Warn("signal mid:%d to mids:%v not friends", mid, mids)
+		return ErrSignalDenied
+	}
+	keyServers, _, err := l.dao.KeysByMids(c, targets)
+	if err != nil {
+		return
+	}
+	serverKeys := make(map[string][]string)
+	for key, server := range keyServers {
+		if key != "" && server != "" {
+			serverKeys[server] = append(serverKeys[server], key)
+		}
+	}
+	servers := make([]string, 0, len(serverKeys))
+	for server := range serverKeys {
+		servers = append(servers, server)
+	}
+	l.eachComet(c, servers, func(ctx context.Context, server string, cc *cometClient) (err error) {
+		keys := serverKeys[server]
+		if _, err = cc.client.PushMsg(ctx, &comet.PushMsgReq{Keys: keys, ProtoOp: frame.Op, Proto: frame, Priority: int32(pb.PushMsg_HIGH)}); err != nil {
+			log.Error("signal PushMsg(%v) server:%s error(%v)", keys, server, err)
+		}
+		return
+	})
+	return
+}
@@ -0,0 +1,24 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ningchengzeng/goim/api/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignal(t *testing.T) {
+	var (
+		c  = context.Background()
+		op = int32(1100)
+	)
+	err := lg.Signal(c, 1, "test_key", "test://test_room", &protocol.Proto{Op: op, Body: []byte(`{"data":{"typing":true}}`)})
+	assert.Nil(t, err)
+	err = lg.Signal(c, 1, "test_key", "test://test_room", &protocol.Proto{Op: op, Body: []byte(`{"room":"test://other_room","data":{"typing":true}}`)})
+	assert.Equal(t, ErrSignalDenied, err)
+	err = lg.Signal(c, 1, "test_key", "", &protocol.Proto{Op: op, Body: []byte(`{"mids":[2],"data":{"typing":true}}`)})
+	assert.Equal(t, ErrSignalDenied, err)
+	err = lg.Signal(c, 1, "test_key", "", &protocol.Proto{Op: op, Body: []byte(`typing`)})
+	assert.NotNil(t, err)
+}
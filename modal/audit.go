@@ -0,0 +1,52 @@
+package modal
+
+import (
+	"context"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Audit 消息操作审计记录
+type Audit struct {
+	Id         string    `json:"id" bson:"_id,omitempty"` // 记录ID
+	Operator   string    //操作者
+	Action     string    //操作: recall, delete, violation
+	MessageId  string    //消息ID
+	Reason     string    //操作原因
+	CreateTime time.Time //操作时间
+}
+
+// AuditCollectionName 审计表定义
+const AuditCollectionName = "audit"
+
+// Insert 插入审计记录
+func (audit *Audit) Insert(database *mongo.Database) (err error) {
+	collection := database.Collection(AuditCollectionName)
+	audit.CreateTime = time.Now()
+	if _, err = collection.InsertOne(context.TODO(), audit); err != nil {
+		log.Error("Audit.Insert(%s,%s) Error(%v)", audit.Action, audit.MessageId, err)
+	}
+	return
+}
+
+// FindByMessageId 根据消息获取审计记录
+func (audit *Audit) FindByMessageId(database *mongo.Database) (results []Audit) {
+	collection := database.Collection(AuditCollectionName)
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"createtime": 1})
+
+	cursor, err := collection.Find(context.TODO(), bson.M{"messageid": audit.MessageId}, opts)
+	if err != nil {
+		log.Error("Audit.FindByMessageId(%s) Error(%v)", audit.MessageId, err)
+		return
+	}
+	if err = cursor.All(context.TODO(), &results); err != nil {
+		log.Error("Audit.FindByMessageId(%s) Error(%v)", audit.MessageId, err)
+	}
+	return
+}
@@ -24,7 +24,7 @@ const (
 
 // Message 消息
 type Message struct {
-	Id            string      `json:"id" bson:"_id"` // 消息ID
+	Id            string      `json:"id" bson:"_id,omitempty"` // 消息ID
 	UserId        string      //消息发送者
 	ObjectUserId  string      //消息发送对象
 	RoomId        string      //消息发送房间
@@ -51,6 +51,7 @@ func (msg *Message) Insert(database *mongo.Database) primitive.ObjectID {
 
 	if err != nil {
 		log.Error("Message.Insert(%s) Error(%v)", msg, err)
+		return primitive.NilObjectID
 	}
 	doc := result.InsertedID.(primitive.ObjectID)
 	return doc
@@ -61,14 +62,14 @@ func (msg *Message) FindByBroadcast(database *mongo.Database) (results []Message
 	collection := database.Collection(MessageCollectionName)
 
 	opts := options.Find()
-	opts.SetSort(bson.M{"SendTime": -1})
+	opts.SetSort(bson.M{"sendtime": -1})
 
 	dd, _ := time.ParseDuration("24h")
 	cursor, err := collection.Find(context.TODO(), bson.M{
-		"Type": BroadcastMessage,
-		"Del":  false,
-		"Send": true,
-		"SendTime": bson.M{
+		"type": BroadcastMessage,
+		"del":  false,
+		"send": true,
+		"sendtime": bson.M{
 			"$gt": time.Now().Add(dd * -90),
 		},
 	}, opts)
@@ -87,18 +88,18 @@ func (msg *Message) FindByUserId(database *mongo.Database) (results []Message) {
 	collection := database.Collection(MessageCollectionName)
 
 	opts := options.Find()
-	opts.SetSort(bson.M{"SendTime": -1})
+	opts.SetSort(bson.M{"sendtime": -1})
 
 	dd, _ := time.ParseDuration("24h")
 	cursor, err := collection.Find(context.TODO(), bson.M{
-		"$or": bson.M{
-			"UserId":       msg.UserId,
-			"ObjectUserId": msg.ObjectUserId,
+		"$or": bson.A{
+			bson.M{"userid": msg.UserId},
+			bson.M{"objectuserid": msg.ObjectUserId},
 		},
-		"Type": UserMessage,
-		"Del":  false,
-		"Send": true,
-		"SendTime": bson.M{
+		"type": UserMessage,
+		"del":  false,
+		"send": true,
+		"sendtime": bson.M{
 			"$gt": time.Now().Add(dd * -90),
 		},
 	}, opts)
@@ -117,15 +118,15 @@ func (msg *Message) FindByKeys(database *mongo.Database) (results []Message) {
 	collection := database.Collection(MessageCollectionName)
 
 	opts := options.Find()
-	opts.SetSort(bson.M{"SendTime": -1})
+	opts.SetSort(bson.M{"sendtime": -1})
 
 	dd, _ := time.ParseDuration("24h")
 	cursor, err := collection.Find(context.TODO(), bson.M{
-		"$in":  msg.Keys,
-		"Type": KeyMessage,
-		"Del":  false,
-		"Send": true,
-		"SendTime": bson.M{
+		"keys": bson.M{"$in": msg.Keys},
+		"type": KeyMessage,
+		"del":  false,
+		"send": true,
+		"sendtime": bson.M{
 			"$gt": time.Now().Add(dd * -90),
 		},
 	}, opts)
@@ -144,15 +145,15 @@ func (msg *Message) FindByRomeId(database *mongo.Database) (results []Message) {
 	collection := database.Collection(MessageCollectionName)
 
 	opts := options.Find()
-	opts.SetSort(bson.M{"SendTime": -1})
+	opts.SetSort(bson.M{"sendtime": -1})
 
 	dd, _ := time.ParseDuration("24h")
 	cursor, err := collection.Find(context.TODO(), bson.M{
-		"RoomId": msg.UserId,
-		"Type":   RoomMessage,
-		"Del":    false,
-		"Send":   true,
-		"SendTime": bson.M{
+		"roomid": msg.RoomId,
+		"type":   RoomMessage,
+		"del":    false,
+		"send":   true,
+		"sendtime": bson.M{
 			"$gt": time.Now().Add(dd * -90),
 		},
 	}, opts)
@@ -166,30 +167,146 @@ func (msg *Message) FindByRomeId(database *mongo.Database) (results []Message) {
 	return
 }
 
+// FindById 根据消息ID获取消息
+func (msg *Message) FindById(database *mongo.Database) (result *Message) {
+	objID, err := primitive.ObjectIDFromHex(msg.Id)
+	if err != nil {
+		return nil
+	}
+	collection := database.Collection(MessageCollectionName)
+	result = new(Message)
+	if err = collection.FindOne(context.TODO(), bson.M{"_id": objID}).Decode(result); err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Error("Message.FindById(%s) Error(%v)", msg.Id, err)
+		}
+		return nil
+	}
+	return
+}
+
 // SetViolation 设置为违规消息
-func (msg *Message) SetViolation(database *mongo.Database) {
+func (msg *Message) SetViolation(database *mongo.Database) (err error) {
 	objID, _ := primitive.ObjectIDFromHex(msg.Id)
 	collection := database.Collection(MessageCollectionName)
-	collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"Violation": true, "ViolationTime": time.Now()})
+	if _, err = collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"$set": bson.M{"violation": true, "violationtime": time.Now()}}); err != nil {
+		log.Error("Message.SetViolation(%s) Error(%v)", msg.Id, err)
+	}
+	return
 }
 
 // SetRead 设置为已读
 func (msg *Message) SetRead(database *mongo.Database) {
 	objID, _ := primitive.ObjectIDFromHex(msg.Id)
 	collection := database.Collection(MessageCollectionName)
-	collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"Read": true, "ReadTime": time.Now()})
+	collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"$set": bson.M{"read": true, "readtime": time.Now()}})
 }
 
 // SetSend 设置为已经发送
 func (msg *Message) SetSend(database *mongo.Database) {
 	objID, _ := primitive.ObjectIDFromHex(msg.Id)
 	collection := database.Collection(MessageCollectionName)
-	collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"Send": true, "SendTime": time.Now()})
+	collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"$set": bson.M{"send": true, "sendtime": time.Now()}})
 }
 
 // SetDel 设置为已删除
-func (msg *Message) SetDel(database *mongo.Database) {
+func (msg *Message) SetDel(database *mongo.Database) (err error) {
 	objID, _ := primitive.ObjectIDFromHex(msg.Id)
 	collection := database.Collection(MessageCollectionName)
-	collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"Del": true, "DelTime": time.Now()})
+	if _, err = collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"$set": bson.M{"del": true, "deltime": time.Now()}}); err != nil {
+		log.Error("Message.SetDel(%s) Error(%v)", msg.Id, err)
+	}
+	return
+}
+
+// SetReadBefore 设置会话中截止到cursor的消息为已读
+func (msg *Message) SetReadBefore(database *mongo.Database, cursor primitive.ObjectID) {
+	collection := database.Collection(MessageCollectionName)
+	_, err := collection.UpdateMany(context.TODO(), bson.M{
+		"userid":       msg.UserId,
+		"objectuserid": msg.ObjectUserId,
+		"type":         UserMessage,
+		"read":         false,
+		"_id":          bson.M{"$lte": cursor},
+	}, bson.M{"$set": bson.M{"read": true, "readtime": time.Now()}})
+	if err != nil {
+		log.Error("Message.SetReadBefore(%s,%s) Error(%v)", msg.UserId, msg.ObjectUserId, err)
+	}
+}
+
+// CountUnread 获取会话中cursor之后的未读消息数, 单聊按UserId和ObjectUserId,
+// 房间按RoomId, 不含读者ObjectUserId自己发送的消息
+func (msg *Message) CountUnread(database *mongo.Database, cursor primitive.ObjectID) (count int64) {
+	collection := database.Collection(MessageCollectionName)
+	filter := bson.M{
+		"type": msg.Type,
+		"del":  false,
+	}
+	if msg.Type == RoomMessage {
+		filter["roomid"] = msg.RoomId
+		if msg.ObjectUserId != "" {
+			filter["userid"] = bson.M{"$ne": msg.ObjectUserId}
+		}
+	} else {
+		filter["userid"] = msg.UserId
+		filter["objectuserid"] = msg.ObjectUserId
+		filter["read"] = false
+	}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$gt": cursor}
+	}
+	count, err := collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		log.Error("Message.CountUnread(%s,%s,%s) Error(%v)", msg.UserId, msg.ObjectUserId, msg.RoomId, err)
+	}
+	return
+}
+
+// FindHistory 分页获取历史消息, 按消息ID倒序, before为上一页最后的消息ID
+// 单聊获取UserId和ObjectUserId双方的消息, 不含已删除和违规消息
+func (msg *Message) FindHistory(database *mongo.Database, before primitive.ObjectID, start, end time.Time, limit int64) (results []Message) {
+	collection := database.Collection(MessageCollectionName)
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"_id": -1})
+	opts.SetLimit(limit)
+
+	filter := bson.M{
+		"type":      msg.Type,
+		"del":       false,
+		"violation": false,
+		"send":      true,
+	}
+	switch msg.Type {
+	case UserMessage:
+		filter["$or"] = bson.A{
+			bson.M{"userid": msg.UserId, "objectuserid": msg.ObjectUserId},
+			bson.M{"userid": msg.ObjectUserId, "objectuserid": msg.UserId},
+		}
+	case RoomMessage:
+		filter["roomid"] = msg.RoomId
+	case KeyMessage:
+		filter["keys"] = bson.M{"$in": msg.Keys}
+	}
+	if !before.IsZero() {
+		filter["_id"] = bson.M{"$lt": before}
+	}
+	// 未到发送时间的定时消息不返回
+	sendTime := bson.M{"$lte": time.Now()}
+	if !start.IsZero() {
+		sendTime["$gte"] = start
+	}
+	if !end.IsZero() {
+		sendTime["$lt"] = end
+	}
+	filter["sendtime"] = sendTime
+	cursor, err := collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		log.Error("Message.FindHistory(%d,%s,%s,%s) Error(%v)", msg.Type, msg.UserId, msg.ObjectUserId, msg.RoomId, err)
+		return
+	}
+	err = cursor.All(context.TODO(), &results)
+	if err != nil {
+		log.Error("Message.FindHistory(%d,%s,%s,%s) Error(%v)", msg.Type, msg.UserId, msg.ObjectUserId, msg.RoomId, err)
+	}
+	return
 }
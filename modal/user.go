@@ -1,6 +1,13 @@
 package modal
 
-import "time"
+import (
+	"context"
+	"time"
+
+	log "github.com/go-kratos/kratos/pkg/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
 
 // UserFriend is 用户好友
 type UserFriend struct {
@@ -17,6 +24,7 @@ type UserFriend struct {
 
 // User is 用户信息
 type User struct {
+	Mid        int64        //用户mid
 	Code       string       //好友编号
 	Tag        []string     //用户标签
 	Name       string       //好友名称
@@ -29,3 +37,58 @@ type User struct {
 	CreateTime time.Time    //创建时间
 	UpdateTime time.Time    //修改时间
 }
+
+// UserCollectionName 用户表定义
+const UserCollectionName = "user"
+
+// FindByCode 根据编号获取用户
+func (user *User) FindByCode(database *mongo.Database) (result *User) {
+	collection := database.Collection(UserCollectionName)
+	result = new(User)
+	err := collection.FindOne(context.TODO(), bson.M{"code": user.Code}).Decode(result)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Error("User.FindByCode(%s) Error(%v)", user.Code, err)
+		}
+		return nil
+	}
+	return
+}
+
+// FindByMid 根据mid获取用户
+func (user *User) FindByMid(database *mongo.Database) (result *User) {
+	collection := database.Collection(UserCollectionName)
+	result = new(User)
+	err := collection.FindOne(context.TODO(), bson.M{"mid": user.Mid}).Decode(result)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Error("User.FindByMid(%d) Error(%v)", user.Mid, err)
+		}
+		return nil
+	}
+	return
+}
+
+// FindUsersByCodes 根据编号批量获取用户
+func FindUsersByCodes(database *mongo.Database, codes []string) (results []*User) {
+	collection := database.Collection(UserCollectionName)
+	cursor, err := collection.Find(context.TODO(), bson.M{"code": bson.M{"$in": codes}})
+	if err != nil {
+		log.Error("FindUsersByCodes(%v) Error(%v)", codes, err)
+		return
+	}
+	if err = cursor.All(context.TODO(), &results); err != nil {
+		log.Error("FindUsersByCodes(%v) Error(%v)", codes, err)
+	}
+	return
+}
+
+// FriendIds 获取未移除的好友编号
+func (user *User) FriendIds() (ids []string) {
+	for _, friend := range user.Friends {
+		if !friend.Remove {
+			ids = append(ids, friend.UserId)
+		}
+	}
+	return
+}
@@ -0,0 +1,178 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket, it refills rate tokens per second up to burst.
+type Bucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// New new a full token bucket.
+func New(rate float64, burst int) *Bucket {
+	return &Bucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow take a token, return false if the bucket is empty.
+func (b *Bucket) Allow() bool {
+	return b.AllowAt(time.Now())
+}
+
+// AllowAt take a token at the time now.
+func (b *Bucket) AllowAt(now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.allow(now)
+}
+
+// AllowRateAt take a token at the time now, the bucket takes the rate and
+// burst if they changed, e.g. after the config reloaded.
+func (b *Bucket) AllowRateAt(rate float64, burst int, now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.rate != rate || b.burst != float64(burst) {
+		b.rate = rate
+		b.burst = float64(burst)
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	return b.allow(now)
+}
+
+func (b *Bucket) allow(now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+const _groupShards = 32
+
+// Key is the key of a bucket in a group, the owner is ID or Name, so that
+// a key is made without an allocation.
+type Key struct {
+	ID   int64
+	Name string
+	Op   int32
+}
+
+func (k Key) shard() uint32 {
+	// fnv-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(k.Name); i++ {
+		h = (h ^ uint32(k.Name[i])) * 16777619
+	}
+	for _, v := range [...]uint64{uint64(k.ID), uint64(uint32(k.Op))} {
+		for i := 0; i < 64; i += 8 {
+			h = (h ^ uint32(byte(v>>uint(i)))) * 16777619
+		}
+	}
+	return h % _groupShards
+}
+
+type groupShard struct {
+	mutex   sync.Mutex
+	buckets map[Key]*Bucket
+}
+
+// Group is a set of token buckets by key, the buckets idle longer than idle
+// are removed by a sweeper until the group closed.
+type Group struct {
+	idle   time.Duration
+	shards [_groupShards]groupShard
+	done   chan struct{}
+}
+
+// NewGroup new a group of token buckets.
+func NewGroup(idle time.Duration) *Group {
+	g := &Group{idle: idle, done: make(chan struct{})}
+	for i := range g.shards {
+		g.shards[i].buckets = make(map[Key]*Bucket)
+	}
+	go g.sweepproc()
+	return g
+}
+
+// Allow take a token from the bucket of the key, the bucket is created with rate and burst if absent.
+func (g *Group) Allow(key Key, rate float64, burst int) bool {
+	return g.AllowAt(key, rate, burst, time.Now())
+}
+
+// AllowAt take a token from the bucket of the key at the time now.
+func (g *Group) AllowAt(key Key, rate float64, burst int, now time.Time) bool {
+	s := &g.shards[key.shard()]
+	s.mutex.Lock()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = New(rate, burst)
+		b.last = now
+		s.buckets[key] = b
+	}
+	s.mutex.Unlock()
+	return b.AllowRateAt(rate, burst, now)
+}
+
+// Sweep remove the buckets idle at the time now.
+func (g *Group) Sweep(now time.Time) {
+	for i := range g.shards {
+		s := &g.shards[i]
+		s.mutex.Lock()
+		for k, b := range s.buckets {
+			b.mutex.Lock()
+			if now.Sub(b.last) > g.idle {
+				delete(s.buckets, k)
+			}
+			b.mutex.Unlock()
+		}
+		s.mutex.Unlock()
+	}
+}
+
+func (g *Group) sweepproc() {
+	ticker := time.NewTicker(g.idle)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			g.Sweep(now)
+		case <-g.done:
+			return
+		}
+	}
+}
+
+// Len get the number of the buckets.
+func (g *Group) Len() (n int) {
+	for i := range g.shards {
+		s := &g.shards[i]
+		s.mutex.Lock()
+		n += len(s.buckets)
+		s.mutex.Unlock()
+	}
+	return
+}
+
+// Close stop the sweeper.
+func (g *Group) Close() {
+	close(g.done)
+}
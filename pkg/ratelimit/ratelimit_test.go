@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucket(t *testing.T) {
+	b := New(10, 2)
+	now := time.Now()
+	if !b.AllowAt(now) || !b.AllowAt(now) {
+		t.FailNow()
+	}
+	if b.AllowAt(now) {
+		t.FailNow()
+	}
+	if !b.AllowAt(now.Add(100 * time.Millisecond)) {
+		t.FailNow()
+	}
+	if b.AllowAt(now.Add(100 * time.Millisecond)) {
+		t.FailNow()
+	}
+}
+
+func TestBucketRate(t *testing.T) {
+	b := New(1, 1)
+	now := time.Now()
+	if !b.AllowAt(now) || b.AllowAt(now) {
+		t.FailNow()
+	}
+	// the reloaded rate is taken
+	if !b.AllowRateAt(10, 1, now.Add(100*time.Millisecond)) {
+		t.FailNow()
+	}
+}
+
+func TestGroup(t *testing.T) {
+	g := NewGroup(time.Minute)
+	defer g.Close()
+	now := time.Now()
+	a := Key{ID: 1, Op: 4}
+	if !g.AllowAt(a, 1, 1, now) || g.AllowAt(a, 1, 1, now) {
+		t.FailNow()
+	}
+	if !g.AllowAt(Key{Name: "127.0.0.1", Op: 4}, 1, 1, now) {
+		t.FailNow()
+	}
+	if g.Len() != 2 {
+		t.FailNow()
+	}
+	// the idle buckets are removed
+	g.Sweep(now.Add(2 * time.Minute))
+	if g.Len() != 0 {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,48 @@
+// Package room is the room key and the room count helpers shared by comet, logic and job.
+package room
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// EncodeKey encode a room key by the type and the room id, eg: "live://1000".
+func EncodeKey(typ string, id string) string {
+	return fmt.Sprintf("%s://%s", typ, id)
+}
+
+// DecodeKey decode a room key into the type and the room id.
+func DecodeKey(key string) (typ string, id string, err error) {
+	u, err := url.Parse(key)
+	if err != nil {
+		return "", "", err
+	}
+	return u.Scheme, u.Host, nil
+}
+
+// CountDelta get the room counts changed from old to cur, a zero count means the room is gone.
+func CountDelta(old, cur map[string]int32) (delta map[string]int32) {
+	delta = make(map[string]int32)
+	for room, count := range cur {
+		if c, ok := old[room]; !ok || c != count {
+			delta[room] = count
+		}
+	}
+	for room := range old {
+		if _, ok := cur[room]; !ok {
+			delta[room] = 0
+		}
+	}
+	return
+}
+
+// ApplyCountDelta apply the changed room counts to m.
+func ApplyCountDelta(m, delta map[string]int32) {
+	for room, count := range delta {
+		if count == 0 {
+			delete(m, room)
+		} else {
+			m[room] = count
+		}
+	}
+}
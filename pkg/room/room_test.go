@@ -0,0 +1,27 @@
+package room
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	key := EncodeKey("live", "1000")
+	assert.Equal(t, "live://1000", key)
+	typ, id, err := DecodeKey(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "live", typ)
+	assert.Equal(t, "1000", id)
+}
+
+func TestCountDelta(t *testing.T) {
+	var (
+		old = map[string]int32{"room_01": 1, "room_02": 2, "room_03": 3}
+		cur = map[string]int32{"room_01": 1, "room_02": 5, "room_04": 4}
+	)
+	delta := CountDelta(old, cur)
+	assert.Equal(t, map[string]int32{"room_02": 5, "room_03": 0, "room_04": 4}, delta)
+	ApplyCountDelta(old, delta)
+	assert.Equal(t, cur, old)
+}